@@ -0,0 +1,112 @@
+package sls
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// buffers larger than maxPooledBufferSize are not pooled, so that a few huge requests
+// do not keep lots of memory in the pool
+const maxPooledBufferSize = 32 << 20
+
+// bytesPool holds the buffers to marshal, compress and decompress logs.
+var bytesPool sync.Pool
+
+// getBytes returns a byte slice of length size, which can be handed back with putBytes.
+func getBytes(size int) []byte {
+	if v := bytesPool.Get(); v != nil {
+		if b := *v.(*[]byte); cap(b) >= size {
+			return b[:size]
+		}
+	}
+	return make([]byte, size)
+}
+
+// putBytes hands back b to the pool, b must not be used after that.
+func putBytes(b []byte) {
+	if cap(b) == 0 || cap(b) > maxPooledBufferSize {
+		return
+	}
+	b = b[:0]
+	bytesPool.Put(&b)
+}
+
+// ReleaseLogsBytes hands back the logs binary data returned by GetLogsBytes, GetLogsBytesV2
+// or GetLogsBytesWithQuery, so that the buffer is reused by later calls.
+// It is optional, and data must not be used after it is released.
+func ReleaseLogsBytes(data []byte) {
+	putBytes(data)
+}
+
+// readBody reads the response body into a pooled buffer, which can be handed back with putBytes.
+func readBody(r *http.Response) ([]byte, error) {
+	if r.ContentLength < 0 || r.ContentLength > maxPooledBufferSize {
+		return ioutil.ReadAll(r.Body)
+	}
+	buf := getBytes(int(r.ContentLength))
+	if _, err := io.ReadFull(r.Body, buf); err != nil {
+		putBytes(buf)
+		return nil, err
+	}
+	return buf, nil
+}
+
+// encodeLogGroup marshals and compresses lg into a pooled buffer,
+// release must be called once the body is not used anymore.
+func encodeLogGroup(lg *LogGroup, compressType int) (body []byte, h map[string]string, release func(), err error) {
+	raw := getBytes(lg.Size())
+	n, err := lg.MarshalTo(raw)
+	if err != nil {
+		putBytes(raw)
+		return nil, nil, nil, err
+	}
+	raw = raw[:n]
+	rawSize := strconv.Itoa(len(raw))
+
+	switch compressType {
+	case Compress_LZ4:
+		out := getBytes(lz4.CompressBlockBound(len(raw)))
+		n, err := lz4.CompressBlock(raw, out, nil)
+		if err != nil {
+			putBytes(raw)
+			putBytes(out)
+			return nil, nil, nil, err
+		}
+		// copy incompressible data as lz4 format
+		if n == 0 {
+			n, _ = copyIncompressible(raw, out)
+		}
+		putBytes(raw)
+		h = map[string]string{
+			"x-log-compresstype": "lz4",
+			"x-log-bodyrawsize":  rawSize,
+			"Content-Type":       "application/x-protobuf",
+		}
+		return out[:n], h, func() { putBytes(out) }, nil
+	case Compress_ZSTD:
+		out, err := slsZstdCompressor.Compress(raw, getBytes(len(raw)))
+		putBytes(raw)
+		if err != nil {
+			putBytes(out)
+			return nil, nil, nil, err
+		}
+		h = map[string]string{
+			"x-log-compresstype": "zstd",
+			"x-log-bodyrawsize":  rawSize,
+			"Content-Type":       "application/x-protobuf",
+		}
+		return out, h, func() { putBytes(out) }, nil
+	default:
+		// no compress
+		h = map[string]string{
+			"x-log-bodyrawsize": rawSize,
+			"Content-Type":      "application/x-protobuf",
+		}
+		return raw, h, func() { putBytes(raw) }, nil
+	}
+}
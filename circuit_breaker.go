@@ -0,0 +1,234 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a circuit of CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets all requests through, it is the initial state.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen fails all requests fast without sending them.
+	CircuitOpen
+	// CircuitHalfOpen lets a few probe requests through to check if the endpoint recovers.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return fmt.Sprintf("CircuitState(%d)", int(s))
+}
+
+// ErrCircuitOpen matches the errors returned when a request is rejected by an open circuit,
+// use errors.Is(err, sls.ErrCircuitOpen) to check it.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitOpenError is returned without sending the request when the circuit
+// of the endpoint and project is open.
+type CircuitOpenError struct {
+	Endpoint string
+	Project  string
+	State    CircuitState // CircuitOpen, or CircuitHalfOpen if there are too many probe requests
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("circuit breaker of endpoint %s project %s is %s", e.Endpoint, e.Project, e.State)
+}
+
+// Is reports whether target is ErrCircuitOpen.
+func (e *CircuitOpenError) Is(target error) bool {
+	return target == ErrCircuitOpen
+}
+
+// CircuitBreakerConfig configures a CircuitBreaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens a closed circuit, defaults to 5.
+	FailureThreshold int
+	// OpenTimeout is how long a circuit stays open before it becomes half-open, defaults to 30s.
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests is the number of concurrent probe requests allowed by a half-open circuit,
+	// the circuit is closed once all of them succeed and opened again once any of them fails.
+	// Defaults to 1.
+	HalfOpenMaxRequests int
+	// IsFailure reports whether a request failed because of the endpoint, eg. network errors
+	// and 5xx responses, which is the default.
+	IsFailure func(err error) bool
+	// OnStateChange is called when the state of the circuit of endpoint and project changes.
+	// It is called synchronously in the request path, so it should return quickly.
+	OnStateChange func(endpoint, project string, from, to CircuitState)
+}
+
+type circuitKey struct {
+	endpoint string
+	project  string
+}
+
+// CircuitBreaker stops sending requests to an endpoint and project for a while once
+// they fail consecutively, so that callers fail fast instead of retrying against
+// a degraded endpoint until the retry timeout.
+//
+// A CircuitBreaker is safe for concurrent use and can be shared by clients.
+type CircuitBreaker struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[circuitKey]*circuit
+}
+
+// NewCircuitBreaker creates a CircuitBreaker, zero fields of config are set to the defaults.
+func NewCircuitBreaker(config CircuitBreakerConfig) *CircuitBreaker {
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = 5
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = 30 * time.Second
+	}
+	if config.HalfOpenMaxRequests <= 0 {
+		config.HalfOpenMaxRequests = 1
+	}
+	if config.IsFailure == nil {
+		config.IsFailure = isEndpointFailure
+	}
+	return &CircuitBreaker{
+		config:   config,
+		circuits: map[circuitKey]*circuit{},
+	}
+}
+
+// State returns the state of the circuit of endpoint and project.
+func (b *CircuitBreaker) State(endpoint, project string) CircuitState {
+	c := b.circuit(endpoint, project)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.config.OpenTimeout {
+		return CircuitHalfOpen
+	}
+	return c.state
+}
+
+func (b *CircuitBreaker) circuit(endpoint, project string) *circuit {
+	key := circuitKey{endpoint, project}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c, ok := b.circuits[key]
+	if !ok {
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	return c
+}
+
+// interceptor returns an Interceptor that rejects requests to endpoint if the circuit is open,
+// and records the result of requests sent.
+func (b *CircuitBreaker) interceptor(endpoint string) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			c := b.circuit(endpoint, req.Project)
+			probe, state, ok := c.allow(b, endpoint, req.Project)
+			if !ok {
+				return nil, &CircuitOpenError{Endpoint: endpoint, Project: req.Project, State: state}
+			}
+			resp, err := next(ctx, req)
+			c.done(b, endpoint, req.Project, probe, err)
+			return resp, err
+		}
+	}
+}
+
+func isEndpointFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	if httpCode := httpCodeOf(err); httpCode != 0 {
+		return httpCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) && !errors.Is(err, context.Canceled)
+}
+
+type circuit struct {
+	mu        sync.Mutex
+	state     CircuitState
+	failures  int // consecutive failures in closed state
+	openedAt  time.Time
+	probes    int // probe requests in flight in half-open state
+	successes int // successful probe requests in half-open state
+}
+
+// allow reports whether a request can be sent, and whether it is a probe request.
+func (c *circuit) allow(b *CircuitBreaker, endpoint, project string) (probe bool, state CircuitState, ok bool) {
+	c.mu.Lock()
+	from := c.state
+	if c.state == CircuitOpen && time.Since(c.openedAt) >= b.config.OpenTimeout {
+		c.state, c.probes, c.successes = CircuitHalfOpen, 0, 0
+	}
+	switch c.state {
+	case CircuitOpen:
+		ok = false
+	case CircuitHalfOpen:
+		if c.probes < b.config.HalfOpenMaxRequests {
+			c.probes++
+			probe, ok = true, true
+		}
+	default:
+		ok = true
+	}
+	state = c.state
+	c.mu.Unlock()
+	b.notify(endpoint, project, from, state)
+	return
+}
+
+// done records the result of a request allowed by allow.
+func (c *circuit) done(b *CircuitBreaker, endpoint, project string, probe bool, err error) {
+	failed := b.config.IsFailure(err)
+	// the caller gave up, it says nothing about the endpoint
+	ignored := !failed && err != nil && errors.Is(err, context.Canceled)
+
+	c.mu.Lock()
+	from := c.state
+	switch {
+	case probe && c.state == CircuitHalfOpen:
+		c.probes--
+		if failed {
+			c.state, c.openedAt = CircuitOpen, time.Now()
+		} else if !ignored {
+			c.successes++
+			if c.successes >= b.config.HalfOpenMaxRequests {
+				c.state, c.failures = CircuitClosed, 0
+			}
+		}
+	case c.state == CircuitClosed:
+		if failed {
+			c.failures++
+			if c.failures >= b.config.FailureThreshold {
+				c.state, c.openedAt = CircuitOpen, time.Now()
+			}
+		} else if !ignored {
+			c.failures = 0
+		}
+	}
+	to := c.state
+	c.mu.Unlock()
+	b.notify(endpoint, project, from, to)
+}
+
+func (b *CircuitBreaker) notify(endpoint, project string, from, to CircuitState) {
+	if from != to && b.config.OnStateChange != nil {
+		b.config.OnStateChange(endpoint, project, from, to)
+	}
+}
@@ -0,0 +1,97 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	shardsURL := fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore)
+	checkpointURL := fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore)
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("GET", shardsURL,
+		httpmock.NewStringResponder(503, `{"errorCode":"ServerBusy","errorMessage":"server busy"}`))
+	transport.RegisterResponder("GET", checkpointURL, httpmock.NewStringResponder(200, `[]`))
+
+	var mu sync.Mutex
+	var changes []string
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{
+		FailureThreshold: 2,
+		OpenTimeout:      100 * time.Millisecond,
+		OnStateChange: func(endpoint, project string, from, to CircuitState) {
+			mu.Lock()
+			defer mu.Unlock()
+			changes = append(changes, fmt.Sprintf("%s/%s: %s->%s", endpoint, project, from, to))
+		},
+	})
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	client.SetCircuitBreaker(breaker)
+	client.SetRetryPolicy(&RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, RetryableHTTPCodes: []int{503}})
+
+	// the retries stop once the circuit is opened
+	_, err := client.ListShards(project, logstore)
+	require.True(t, errors.Is(err, ErrCircuitOpen), err)
+	var openErr *CircuitOpenError
+	require.True(t, errors.As(err, &openErr))
+	require.Equal(t, project, openErr.Project)
+	require.Equal(t, 2, transport.GetCallCountInfo()["GET "+shardsURL])
+	require.Equal(t, CircuitOpen, breaker.State(endpoint, project))
+
+	// fail fast without sending requests
+	_, err = client.GetCheckpoint(project, logstore, "cg")
+	require.True(t, errors.Is(err, ErrCircuitOpen), err)
+	require.Equal(t, 0, transport.GetCallCountInfo()["GET "+checkpointURL])
+	// other projects are not affected
+	require.Equal(t, CircuitClosed, breaker.State(endpoint, "otherProject"))
+
+	// a successful probe closes the circuit
+	time.Sleep(100 * time.Millisecond)
+	require.Equal(t, CircuitHalfOpen, breaker.State(endpoint, project))
+	_, err = client.GetCheckpoint(project, logstore, "cg")
+	require.NoError(t, err)
+	require.Equal(t, CircuitClosed, breaker.State(endpoint, project))
+
+	mu.Lock()
+	defer mu.Unlock()
+	prefix := endpoint + "/" + project + ": "
+	require.Equal(t, []string{prefix + "closed->open", prefix + "open->half-open", prefix + "half-open->closed"}, changes)
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(CircuitBreakerConfig{FailureThreshold: 1, OpenTimeout: time.Millisecond, HalfOpenMaxRequests: 2})
+	c := breaker.circuit("endpoint", "project")
+	serverErr := &Error{HTTPCode: 500, Code: INTERNAL_SERVER_ERROR}
+
+	// client errors do not count as failures
+	_, _, ok := c.allow(breaker, "endpoint", "project")
+	require.True(t, ok)
+	c.done(breaker, "endpoint", "project", false, &Error{HTTPCode: 404, Code: LOGSTORE_NOT_EXIST})
+	require.Equal(t, CircuitClosed, breaker.State("endpoint", "project"))
+
+	c.done(breaker, "endpoint", "project", false, serverErr)
+	require.Equal(t, CircuitOpen, c.state)
+	time.Sleep(time.Millisecond)
+
+	// at most HalfOpenMaxRequests probes are in flight
+	probe1, _, ok1 := c.allow(breaker, "endpoint", "project")
+	probe2, _, ok2 := c.allow(breaker, "endpoint", "project")
+	_, state, ok3 := c.allow(breaker, "endpoint", "project")
+	require.True(t, probe1 && ok1 && probe2 && ok2)
+	require.False(t, ok3)
+	require.Equal(t, CircuitHalfOpen, state)
+
+	// a failed probe opens the circuit again
+	c.done(breaker, "endpoint", "project", true, nil)
+	require.Equal(t, CircuitHalfOpen, c.state)
+	c.done(breaker, "endpoint", "project", true, serverErr)
+	require.Equal(t, CircuitOpen, c.state)
+}
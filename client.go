@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/aliyun/aliyun-log-go-sdk/util"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // GlobalForceUsingHTTP if GlobalForceUsingHTTP is true, then all request will use HTTP(ignore LogProject's UsingHTTP flag)
@@ -56,13 +58,17 @@ type Error struct {
 	Code      string `json:"errorCode"`
 	Message   string `json:"errorMessage"`
 	RequestID string `json:"requestID"`
+
+	cause error // the error wrapped by NewClientError
 }
 
 func IsDebugLevelMatched(level int) bool {
 	return level <= GlobalDebugLevel
 }
 
-// NewClientError new client error
+// NewClientError new client error, its code is always ClientError and its http code -1.
+// The request id is kept if err wraps an sls error or a BadResponseError, which can still be
+// matched by errors.Is with the sentinel errors.
 func NewClientError(err error) *Error {
 	if err == nil {
 		return nil
@@ -74,9 +80,22 @@ func NewClientError(err error) *Error {
 	clientError.HTTPCode = -1
 	clientError.Code = "ClientError"
 	clientError.Message = err.Error()
+	clientError.cause = err
+	var slsErr *Error
+	var badRespErr *BadResponseError
+	if slsErr = serverErrorOf(err); slsErr != nil {
+		clientError.RequestID = slsErr.RequestID
+	} else if errors.As(err, &badRespErr) {
+		clientError.RequestID = badRespErr.RequestID
+	}
 	return clientError
 }
 
+// Unwrap returns the error wrapped by NewClientError, if any.
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
 func (e Error) String() string {
 	b, err := json.MarshalIndent(e, "", "    ")
 	if err != nil {
@@ -90,7 +109,8 @@ func (e Error) Error() string {
 }
 
 func IsTokenError(err error) bool {
-	if clientErr, ok := err.(*Error); ok {
+	var clientErr *Error
+	if errors.As(err, &clientErr) {
 		if clientErr.HTTPCode == 401 {
 			return true
 		}
@@ -107,40 +127,62 @@ type Client struct {
 	UserAgent       string // default defaultLogUserAgent
 	RequestTimeOut  time.Duration
 	RetryTimeOut    time.Duration
+	retryPolicy     *RetryPolicy
 	HTTPClient      *http.Client
 	Region          string
 	AuthVersion     AuthVersionType //  v1 or v4 signature,default is v1
 
 	accessKeyLock       sync.RWMutex
+	initOnce            sync.Once
 	credentialsProvider CredentialsProvider
 	// User defined common headers.
 	// When conflict with sdk pre-defined headers, the value will
 	// be ignored
 	CommonHeaders map[string]string
 	InnerHeaders  map[string]string
+
+	interceptors []Interceptor
+	tracer       trace.Tracer
+	rateLimiter  *RateLimiter
+	breaker      *CircuitBreaker
+	endpoints    *endpointGroup
+	metrics      *MetricsCollector
+	newSigner    func(creds Credentials) Signer
 }
 
-// repeated calls only create one http client
+// repeated calls only create one http client, it must not be called with accessKeyLock held
 func (c *Client) initHttpClient() {
-	if c.RequestTimeOut == 0 {
-		c.RequestTimeOut = defaultRequestTimeout
-	}
-	if c.RetryTimeOut == 0 {
-		c.RetryTimeOut = defaultRetryTimeout
-	}
-	if c.HTTPClient == nil {
-		c.HTTPClient = newDefaultHTTPClient(c.RequestTimeOut)
-	}
+	c.initOnce.Do(func() {
+		c.accessKeyLock.Lock()
+		defer c.accessKeyLock.Unlock()
+		if c.RequestTimeOut == 0 {
+			c.RequestTimeOut = defaultRequestTimeout
+		}
+		if c.RetryTimeOut == 0 {
+			c.RetryTimeOut = defaultRetryTimeout
+		}
+		if c.HTTPClient == nil {
+			c.HTTPClient = newDefaultHTTPClient(c.RequestTimeOut)
+		}
+	})
 }
 
 func convert(c *Client, projName string) *LogProject {
+	c.initHttpClient()
 	c.accessKeyLock.RLock()
 	defer c.accessKeyLock.RUnlock()
 	return convertLocked(c, projName)
 }
 
+// convertWithContext is like convert, but all requests sent by the returned
+// project are bound to ctx.
+func convertWithContext(ctx context.Context, c *Client, projName string) *LogProject {
+	p := convert(c, projName)
+	p.ctx = ctx
+	return p
+}
+
 func convertLocked(c *Client, projName string) *LogProject {
-	c.initHttpClient()
 	var p *LogProject
 	if c.credentialsProvider != nil {
 		p, _ = NewLogProjectV2(projName, c.Endpoint, c.credentialsProvider)
@@ -154,8 +196,16 @@ func convertLocked(c *Client, projName string) *LogProject {
 	p.Region = c.Region
 	p.commonHeaders = c.CommonHeaders
 	p.innerHeaders = c.InnerHeaders
+	p.interceptors = c.interceptors
+	p.tracer = c.tracer
+	p.rateLimiter = c.rateLimiter
+	p.breaker = c.breaker
+	p.endpoints = c.endpoints
+	p.metrics = c.metrics
+	p.newSigner = c.newSigner
 	p.httpClient = c.HTTPClient
 	p.retryTimeout = c.RetryTimeOut
+	p.retryPolicy = c.retryPolicy
 	return p
 }
 
@@ -165,6 +215,26 @@ func (c *Client) WithCredentialsProvider(provider CredentialsProvider) *Client {
 	return c
 }
 
+// SetEndpoints set an ordered list of endpoints of the client, Endpoint is set to the first one.
+// Requests are sent to the first healthy endpoint, and are resent to the next one on connection
+// errors, eg. the endpoint is unreachable. An endpoint failing to connect is not preferred
+// for 30 seconds, then the client tries it again, so it falls back to the preferred endpoint
+// once it recovers. See EndpointResolver to build the endpoints of a region.
+func (c *Client) SetEndpoints(endpoints ...string) {
+	c.accessKeyLock.Lock()
+	defer c.accessKeyLock.Unlock()
+	if len(endpoints) == 0 {
+		c.endpoints = nil
+		return
+	}
+	c.Endpoint = endpoints[0]
+	if len(endpoints) == 1 {
+		c.endpoints = nil
+		return
+	}
+	c.endpoints = newEndpointGroup(append([]string(nil), endpoints...), defaultEndpointCooldown)
+}
+
 // SetUserAgent set a custom userAgent
 func (c *Client) SetUserAgent(userAgent string) {
 	c.UserAgent = userAgent
@@ -180,6 +250,46 @@ func (c *Client) SetRetryTimeout(timeout time.Duration) {
 	c.RetryTimeOut = timeout
 }
 
+// SetRetryPolicy set the retry policy of all requests sent by the client.
+// If policy is nil, which is the default, only the requests of some apis are retried,
+// controlled by package level variables, eg. RetryOnServerErrorEnabled.
+func (c *Client) SetRetryPolicy(policy *RetryPolicy) {
+	c.accessKeyLock.Lock()
+	c.retryPolicy = policy
+	c.accessKeyLock.Unlock()
+}
+
+// SetRateLimiter set the rate limiter of all requests sent by the client, nil means no limit.
+func (c *Client) SetRateLimiter(limiter *RateLimiter) {
+	c.accessKeyLock.Lock()
+	c.rateLimiter = limiter
+	c.accessKeyLock.Unlock()
+}
+
+// SetCircuitBreaker set the circuit breaker of all requests sent by the client, nil means no circuit breaker.
+func (c *Client) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.accessKeyLock.Lock()
+	c.breaker = breaker
+	c.accessKeyLock.Unlock()
+}
+
+// SetMetricsCollector set the collector of metrics of all requests sent by the client, nil means no metrics.
+func (c *Client) SetMetricsCollector(collector *MetricsCollector) {
+	c.accessKeyLock.Lock()
+	c.metrics = collector
+	c.accessKeyLock.Unlock()
+}
+
+// SetSigner set the function creating the signer of each request from the credentials got from
+// the credentials provider, eg. to sign with a key managed by a KMS, or to sign custom headers.
+// The Date header, or the x-log-date header for AuthV4, is set before signing as the AuthVersion requires.
+// nil means SignerV1 or SignerV4 chosen by the AuthVersion.
+func (c *Client) SetSigner(newSigner func(creds Credentials) Signer) {
+	c.accessKeyLock.Lock()
+	c.newSigner = newSigner
+	c.accessKeyLock.Unlock()
+}
+
 // SetAuthVersion set signature version that the client used
 func (c *Client) SetAuthVersion(version AuthVersionType) {
 	c.accessKeyLock.Lock()
@@ -380,8 +490,8 @@ func (c *Client) CheckProjectExist(name string) (bool, error) {
 	proj := convert(c, name)
 	resp, err := request(proj, "GET", uri, h, nil)
 	if err != nil {
-		if _, ok := err.(*Error); ok {
-			slsErr := err.(*Error)
+		var slsErr *Error
+		if errors.As(err, &slsErr) {
 			if slsErr.Code == "ProjectNotExist" {
 				return false, nil
 			}
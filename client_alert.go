@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -261,6 +262,11 @@ type AlertConfiguration struct {
 }
 
 func (c *Client) CreateSavedSearch(project string, savedSearch *SavedSearch) error {
+	return c.CreateSavedSearchCtx(context.Background(), project, savedSearch)
+}
+
+// CreateSavedSearchCtx is like CreateSavedSearch, but the request is bound to ctx.
+func (c *Client) CreateSavedSearchCtx(ctx context.Context, project string, savedSearch *SavedSearch) error {
 	body, err := json.Marshal(savedSearch)
 	if err != nil {
 		return NewClientError(err)
@@ -272,7 +278,7 @@ func (c *Client) CreateSavedSearch(project string, savedSearch *SavedSearch) err
 	}
 
 	uri := "/savedsearches"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -281,6 +287,11 @@ func (c *Client) CreateSavedSearch(project string, savedSearch *SavedSearch) err
 }
 
 func (c *Client) UpdateSavedSearch(project string, savedSearch *SavedSearch) error {
+	return c.UpdateSavedSearchCtx(context.Background(), project, savedSearch)
+}
+
+// UpdateSavedSearchCtx is like UpdateSavedSearch, but the request is bound to ctx.
+func (c *Client) UpdateSavedSearchCtx(ctx context.Context, project string, savedSearch *SavedSearch) error {
 	body, err := json.Marshal(savedSearch)
 	if err != nil {
 		return NewClientError(err)
@@ -292,7 +303,7 @@ func (c *Client) UpdateSavedSearch(project string, savedSearch *SavedSearch) err
 	}
 
 	uri := "/savedsearches/" + savedSearch.SavedSearchName
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -301,13 +312,18 @@ func (c *Client) UpdateSavedSearch(project string, savedSearch *SavedSearch) err
 }
 
 func (c *Client) DeleteSavedSearch(project string, savedSearchName string) error {
+	return c.DeleteSavedSearchCtx(context.Background(), project, savedSearchName)
+}
+
+// DeleteSavedSearchCtx is like DeleteSavedSearch, but the request is bound to ctx.
+func (c *Client) DeleteSavedSearchCtx(ctx context.Context, project string, savedSearchName string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := "/savedsearches/" + savedSearchName
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -316,13 +332,18 @@ func (c *Client) DeleteSavedSearch(project string, savedSearchName string) error
 }
 
 func (c *Client) GetSavedSearch(project string, savedSearchName string) (*SavedSearch, error) {
+	return c.GetSavedSearchCtx(context.Background(), project, savedSearchName)
+}
+
+// GetSavedSearchCtx is like GetSavedSearch, but the request is bound to ctx.
+func (c *Client) GetSavedSearchCtx(ctx context.Context, project string, savedSearchName string) (*SavedSearch, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := "/savedsearches/" + savedSearchName
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -339,6 +360,11 @@ func (c *Client) GetSavedSearch(project string, savedSearchName string) (*SavedS
 }
 
 func (c *Client) ListSavedSearch(project string, savedSearchName string, offset, size int) (savedSearches []string, total int, count int, err error) {
+	return c.ListSavedSearchCtx(context.Background(), project, savedSearchName, offset, size)
+}
+
+// ListSavedSearchCtx is like ListSavedSearch, but the request is bound to ctx.
+func (c *Client) ListSavedSearchCtx(ctx context.Context, project string, savedSearchName string, offset, size int) (savedSearches []string, total int, count int, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -348,7 +374,7 @@ func (c *Client) ListSavedSearch(project string, savedSearchName string, offset,
 	}
 
 	uri := "/savedsearches"
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -372,6 +398,11 @@ func (c *Client) ListSavedSearch(project string, savedSearchName string, offset,
 }
 
 func (c *Client) ListSavedSearchV2(project string, savedSearchName string, offset, size int) (savedSearches []string, savedsearchItems []ResponseSavedSearchItem, total int, count int, err error) {
+	return c.ListSavedSearchV2Ctx(context.Background(), project, savedSearchName, offset, size)
+}
+
+// ListSavedSearchV2Ctx is like ListSavedSearchV2, but the request is bound to ctx.
+func (c *Client) ListSavedSearchV2Ctx(ctx context.Context, project string, savedSearchName string, offset, size int) (savedSearches []string, savedsearchItems []ResponseSavedSearchItem, total int, count int, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -381,7 +412,7 @@ func (c *Client) ListSavedSearchV2(project string, savedSearchName string, offse
 	}
 
 	uri := "/savedsearches"
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, nil, 0, 0, err
 	}
@@ -406,6 +437,11 @@ func (c *Client) ListSavedSearchV2(project string, savedSearchName string, offse
 }
 
 func (c *Client) CreateAlert(project string, alert *Alert) error {
+	return c.CreateAlertCtx(context.Background(), project, alert)
+}
+
+// CreateAlertCtx is like CreateAlert, but the request is bound to ctx.
+func (c *Client) CreateAlertCtx(ctx context.Context, project string, alert *Alert) error {
 	body, err := json.Marshal(alert)
 	if err != nil {
 		return NewClientError(err)
@@ -416,7 +452,7 @@ func (c *Client) CreateAlert(project string, alert *Alert) error {
 	}
 
 	uri := "/jobs"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -425,6 +461,11 @@ func (c *Client) CreateAlert(project string, alert *Alert) error {
 }
 
 func (c *Client) CreateAlertString(project string, alert string) error {
+	return c.CreateAlertStringCtx(context.Background(), project, alert)
+}
+
+// CreateAlertStringCtx is like CreateAlertString, but the request is bound to ctx.
+func (c *Client) CreateAlertStringCtx(ctx context.Context, project string, alert string) error {
 	body := []byte(alert)
 	h := map[string]string{
 		"x-log-bodyrawsize": fmt.Sprintf("%v", len(body)),
@@ -432,7 +473,7 @@ func (c *Client) CreateAlertString(project string, alert string) error {
 	}
 
 	uri := "/jobs"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -441,6 +482,11 @@ func (c *Client) CreateAlertString(project string, alert string) error {
 }
 
 func (c *Client) UpdateAlert(project string, alert *Alert) error {
+	return c.UpdateAlertCtx(context.Background(), project, alert)
+}
+
+// UpdateAlertCtx is like UpdateAlert, but the request is bound to ctx.
+func (c *Client) UpdateAlertCtx(ctx context.Context, project string, alert *Alert) error {
 	body, err := json.Marshal(alert)
 	if err != nil {
 		return NewClientError(err)
@@ -452,7 +498,7 @@ func (c *Client) UpdateAlert(project string, alert *Alert) error {
 	}
 
 	uri := "/jobs/" + alert.Name
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -461,6 +507,11 @@ func (c *Client) UpdateAlert(project string, alert *Alert) error {
 }
 
 func (c *Client) UpdateAlertString(project string, alertName, alert string) error {
+	return c.UpdateAlertStringCtx(context.Background(), project, alertName, alert)
+}
+
+// UpdateAlertStringCtx is like UpdateAlertString, but the request is bound to ctx.
+func (c *Client) UpdateAlertStringCtx(ctx context.Context, project string, alertName, alert string) error {
 	body := []byte(alert)
 
 	h := map[string]string{
@@ -469,7 +520,7 @@ func (c *Client) UpdateAlertString(project string, alertName, alert string) erro
 	}
 
 	uri := "/jobs/" + alertName
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -478,13 +529,18 @@ func (c *Client) UpdateAlertString(project string, alertName, alert string) erro
 }
 
 func (c *Client) DeleteAlert(project string, alertName string) error {
+	return c.DeleteAlertCtx(context.Background(), project, alertName)
+}
+
+// DeleteAlertCtx is like DeleteAlert, but the request is bound to ctx.
+func (c *Client) DeleteAlertCtx(ctx context.Context, project string, alertName string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := "/jobs/" + alertName
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -493,12 +549,17 @@ func (c *Client) DeleteAlert(project string, alertName string) error {
 }
 
 func (c *Client) DisableAlert(project string, alertName string) error {
+	return c.DisableAlertCtx(context.Background(), project, alertName)
+}
+
+// DisableAlertCtx is like DisableAlert, but the request is bound to ctx.
+func (c *Client) DisableAlertCtx(ctx context.Context, project string, alertName string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := fmt.Sprintf("/jobs/%s?action=disable", alertName)
-	r, err := c.request(project, "PUT", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -507,12 +568,17 @@ func (c *Client) DisableAlert(project string, alertName string) error {
 }
 
 func (c *Client) EnableAlert(project string, alertName string) error {
+	return c.EnableAlertCtx(context.Background(), project, alertName)
+}
+
+// EnableAlertCtx is like EnableAlert, but the request is bound to ctx.
+func (c *Client) EnableAlertCtx(ctx context.Context, project string, alertName string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := fmt.Sprintf("/jobs/%s?action=enable", alertName)
-	r, err := c.request(project, "PUT", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -521,12 +587,17 @@ func (c *Client) EnableAlert(project string, alertName string) error {
 }
 
 func (c *Client) GetAlert(project string, alertName string) (*Alert, error) {
+	return c.GetAlertCtx(context.Background(), project, alertName)
+}
+
+// GetAlertCtx is like GetAlert, but the request is bound to ctx.
+func (c *Client) GetAlertCtx(ctx context.Context, project string, alertName string) (*Alert, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + alertName
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -543,12 +614,17 @@ func (c *Client) GetAlert(project string, alertName string) (*Alert, error) {
 }
 
 func (c *Client) GetAlertString(project string, alertName string) (string, error) {
+	return c.GetAlertStringCtx(context.Background(), project, alertName)
+}
+
+// GetAlertStringCtx is like GetAlertString, but the request is bound to ctx.
+func (c *Client) GetAlertStringCtx(ctx context.Context, project string, alertName string) (string, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + alertName
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return "", err
 	}
@@ -561,6 +637,11 @@ func (c *Client) GetAlertString(project string, alertName string) (string, error
 }
 
 func (c *Client) ListAlert(project, alertName, dashboard string, offset, size int) (alerts []*Alert, total int, count int, err error) {
+	return c.ListAlertCtx(context.Background(), project, alertName, dashboard, offset, size)
+}
+
+// ListAlertCtx is like ListAlert, but the request is bound to ctx.
+func (c *Client) ListAlertCtx(ctx context.Context, project, alertName, dashboard string, offset, size int) (alerts []*Alert, total int, count int, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -574,7 +655,7 @@ func (c *Client) ListAlert(project, alertName, dashboard string, offset, size in
 		v.Add("resourceProvider", dashboard)
 	}
 	uri := "/jobs?" + v.Encode()
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
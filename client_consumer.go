@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
@@ -33,6 +34,11 @@ type ConsumerGroupCheckPoint struct {
 
 // CreateConsumerGroup ...
 func (c *Client) CreateConsumerGroup(project, logstore string, cg ConsumerGroup) (err error) {
+	return c.CreateConsumerGroupCtx(context.Background(), project, logstore, cg)
+}
+
+// CreateConsumerGroupCtx is like CreateConsumerGroup, but the request is bound to ctx.
+func (c *Client) CreateConsumerGroupCtx(ctx context.Context, project, logstore string, cg ConsumerGroup) (err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -43,7 +49,7 @@ func (c *Client) CreateConsumerGroup(project, logstore string, cg ConsumerGroup)
 		return err
 	}
 	uri := fmt.Sprintf("/logstores/%v/consumergroups", logstore)
-	_, err = c.request(project, "POST", uri, h, body)
+	_, err = c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -52,6 +58,11 @@ func (c *Client) CreateConsumerGroup(project, logstore string, cg ConsumerGroup)
 
 // UpdateConsumerGroup ...
 func (c *Client) UpdateConsumerGroup(project, logstore string, cg ConsumerGroup) (err error) {
+	return c.UpdateConsumerGroupCtx(context.Background(), project, logstore, cg)
+}
+
+// UpdateConsumerGroupCtx is like UpdateConsumerGroup, but the request is bound to ctx.
+func (c *Client) UpdateConsumerGroupCtx(ctx context.Context, project, logstore string, cg ConsumerGroup) (err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -67,7 +78,7 @@ func (c *Client) UpdateConsumerGroup(project, logstore string, cg ConsumerGroup)
 		return err
 	}
 	uri := fmt.Sprintf("/logstores/%v/consumergroups/%v", logstore, cg.ConsumerGroupName)
-	_, err = c.request(project, "PUT", uri, h, body)
+	_, err = c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -76,12 +87,17 @@ func (c *Client) UpdateConsumerGroup(project, logstore string, cg ConsumerGroup)
 
 // DeleteConsumerGroup ...
 func (c *Client) DeleteConsumerGroup(project, logstore string, cgName string) (err error) {
+	return c.DeleteConsumerGroupCtx(context.Background(), project, logstore, cgName)
+}
+
+// DeleteConsumerGroupCtx is like DeleteConsumerGroup, but the request is bound to ctx.
+func (c *Client) DeleteConsumerGroupCtx(ctx context.Context, project, logstore string, cgName string) (err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
 
 	uri := fmt.Sprintf("/logstores/%v/consumergroups/%v", logstore, cgName)
-	_, err = c.request(project, "DELETE", uri, h, nil)
+	_, err = c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -90,12 +106,17 @@ func (c *Client) DeleteConsumerGroup(project, logstore string, cgName string) (e
 
 // ListConsumerGroup ...
 func (c *Client) ListConsumerGroup(project, logstore string) (cgList []*ConsumerGroup, err error) {
+	return c.ListConsumerGroupCtx(context.Background(), project, logstore)
+}
+
+// ListConsumerGroupCtx is like ListConsumerGroup, but the request is bound to ctx.
+func (c *Client) ListConsumerGroupCtx(ctx context.Context, project, logstore string) (cgList []*ConsumerGroup, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
 
 	uri := fmt.Sprintf("/logstores/%v/consumergroups", logstore)
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, NewClientError(err)
 	}
@@ -140,6 +161,11 @@ func (c *Client) ListConsumerGroup(project, logstore string) (cgList []*Consumer
 
 // HeartBeat ...
 func (c *Client) HeartBeat(project, logstore string, cgName, consumer string, heartBeatShardIDs []int) (shardIDs []int, err error) {
+	return c.HeartBeatCtx(context.Background(), project, logstore, cgName, consumer, heartBeatShardIDs)
+}
+
+// HeartBeatCtx is like HeartBeat, but the request is bound to ctx.
+func (c *Client) HeartBeatCtx(ctx context.Context, project, logstore string, cgName, consumer string, heartBeatShardIDs []int) (shardIDs []int, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -156,7 +182,7 @@ func (c *Client) HeartBeat(project, logstore string, cgName, consumer string, he
 	urlVal.Add("consumer", consumer)
 	uri := fmt.Sprintf("/logstores/%v/consumergroups/%v?%v", logstore, cgName, urlVal.Encode())
 
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return nil, NewClientError(err)
 	}
@@ -176,6 +202,11 @@ func (c *Client) HeartBeat(project, logstore string, cgName, consumer string, he
 
 // UpdateCheckpoint ...
 func (c *Client) UpdateCheckpoint(project, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) (err error) {
+	return c.UpdateCheckpointCtx(context.Background(), project, logstore, cgName, consumer, shardID, checkpoint, forceSuccess)
+}
+
+// UpdateCheckpointCtx is like UpdateCheckpoint, but the request is bound to ctx.
+func (c *Client) UpdateCheckpointCtx(ctx context.Context, project, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) (err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -198,7 +229,7 @@ func (c *Client) UpdateCheckpoint(project, logstore string, cgName string, consu
 	}
 	// fmt.Println(urlVal.Encode())
 	uri := fmt.Sprintf("/logstores/%v/consumergroups/%v?%v", logstore, cgName, urlVal.Encode())
-	_, err = c.request(project, "POST", uri, h, body)
+	_, err = c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -207,11 +238,16 @@ func (c *Client) UpdateCheckpoint(project, logstore string, cgName string, consu
 
 // GetCheckpoint ...
 func (c *Client) GetCheckpoint(project, logstore string, cgName string) (checkPointList []*ConsumerGroupCheckPoint, err error) {
+	return c.GetCheckpointCtx(context.Background(), project, logstore, cgName)
+}
+
+// GetCheckpointCtx is like GetCheckpoint, but the request is bound to ctx.
+func (c *Client) GetCheckpointCtx(ctx context.Context, project, logstore string, cgName string) (checkPointList []*ConsumerGroupCheckPoint, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
 	uri := fmt.Sprintf("/logstores/%v/consumergroups/%v", logstore, cgName)
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, NewClientError(err)
 	}
@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -90,6 +91,11 @@ func NewETL(endpoint, accessKeyId, accessKeySecret, logstore, name, project stri
 }
 
 func (c *Client) CreateETL(project string, etljob ETL) error {
+	return c.CreateETLCtx(context.Background(), project, etljob)
+}
+
+// CreateETLCtx is like CreateETL, but the request is bound to ctx.
+func (c *Client) CreateETLCtx(ctx context.Context, project string, etljob ETL) error {
 	body, err := json.Marshal(etljob)
 	if err != nil {
 		return NewClientError(err)
@@ -100,7 +106,7 @@ func (c *Client) CreateETL(project string, etljob ETL) error {
 	}
 	uri := "/jobs"
 
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -109,12 +115,17 @@ func (c *Client) CreateETL(project string, etljob ETL) error {
 }
 
 func (c *Client) GetETL(project string, etlName string) (ETLJob *ETL, err error) {
+	return c.GetETLCtx(context.Background(), project, etlName)
+}
+
+// GetETLCtx is like GetETL, but the request is bound to ctx.
+func (c *Client) GetETLCtx(ctx context.Context, project string, etlName string) (ETLJob *ETL, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + etlName
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -131,6 +142,11 @@ func (c *Client) GetETL(project string, etlName string) (ETLJob *ETL, err error)
 }
 
 func (c *Client) UpdateETL(project string, etljob ETL) error {
+	return c.UpdateETLCtx(context.Background(), project, etljob)
+}
+
+// UpdateETLCtx is like UpdateETL, but the request is bound to ctx.
+func (c *Client) UpdateETLCtx(ctx context.Context, project string, etljob ETL) error {
 	body, err := json.Marshal(etljob)
 	if err != nil {
 		return NewClientError(err)
@@ -140,7 +156,7 @@ func (c *Client) UpdateETL(project string, etljob ETL) error {
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + etljob.Name
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -149,12 +165,17 @@ func (c *Client) UpdateETL(project string, etljob ETL) error {
 }
 
 func (c *Client) DeleteETL(project string, etlName string) error {
+	return c.DeleteETLCtx(context.Background(), project, etlName)
+}
+
+// DeleteETLCtx is like DeleteETL, but the request is bound to ctx.
+func (c *Client) DeleteETLCtx(ctx context.Context, project string, etlName string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + etlName
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -163,13 +184,18 @@ func (c *Client) DeleteETL(project string, etlName string) error {
 }
 
 func (c *Client) ListETL(project string, offset int, size int) (*ListETLResponse, error) {
+	return c.ListETLCtx(context.Background(), project, offset, size)
+}
+
+// ListETLCtx is like ListETL, but the request is bound to ctx.
+func (c *Client) ListETLCtx(ctx context.Context, project string, offset int, size int) (*ListETLResponse, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := fmt.Sprintf("/jobs?offset=%d&size=%d&jobType=ETL", offset, size)
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -187,13 +213,18 @@ func (c *Client) ListETL(project string, offset int, size int) (*ListETLResponse
 }
 
 func (c *Client) StartETL(project, name string) error {
+	return c.StartETLCtx(context.Background(), project, name)
+}
+
+// StartETLCtx is like StartETL, but the request is bound to ctx.
+func (c *Client) StartETLCtx(ctx context.Context, project, name string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := fmt.Sprintf("/jobs/%s?action=START", name)
-	r, err := c.request(project, "PUT", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -202,13 +233,18 @@ func (c *Client) StartETL(project, name string) error {
 }
 
 func (c *Client) StopETL(project, name string) error {
+	return c.StopETLCtx(context.Background(), project, name)
+}
+
+// StopETLCtx is like StopETL, but the request is bound to ctx.
+func (c *Client) StopETLCtx(ctx context.Context, project, name string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := fmt.Sprintf("/jobs/%s?action=STOP", name)
-	r, err := c.request(project, "PUT", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -217,6 +253,11 @@ func (c *Client) StopETL(project, name string) error {
 }
 
 func (c *Client) RestartETL(project string, etljob ETL) error {
+	return c.RestartETLCtx(context.Background(), project, etljob)
+}
+
+// RestartETLCtx is like RestartETL, but the request is bound to ctx.
+func (c *Client) RestartETLCtx(ctx context.Context, project string, etljob ETL) error {
 	body, err := json.Marshal(etljob)
 	if err != nil {
 		return NewClientError(err)
@@ -226,7 +267,7 @@ func (c *Client) RestartETL(project string, etljob ETL) error {
 		"Content-Type":      "application/json",
 	}
 	uri := fmt.Sprintf("/jobs/%s?action=RESTART", etljob.Name)
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
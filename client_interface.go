@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -472,3 +473,141 @@ type ClientInterface interface {
 	// #################### AlertPub Msg  #####################
 	PublishAlertEvent(project string, alertResult []byte) error
 }
+
+// ClientInterfaceWithContext extends ClientInterface with context-aware methods,
+// the in-flight http request and the retry backoff are aborted once ctx is done.
+//
+// The *Client returned by CreateNormalInterface and CreateNormalInterfaceV2 implements it.
+type ClientInterfaceWithContext interface {
+	ClientInterface
+
+	// #################### Logstore Operations #####################
+	ListLogStoreCtx(ctx context.Context, project string) ([]string, error)
+	ListLogStoreV2Ctx(ctx context.Context, project string, offset, size int, telemetryType string) ([]string, error)
+	GetLogStoreCtx(ctx context.Context, project string, logstore string) (*LogStore, error)
+	CreateLogStoreCtx(ctx context.Context, project string, logstore string, ttl, shardCnt int, autoSplit bool, maxSplitShard int) error
+	CreateLogStoreV2Ctx(ctx context.Context, project string, logstore *LogStore) error
+	DeleteLogStoreCtx(ctx context.Context, project string, logstore string) (err error)
+	UpdateLogStoreCtx(ctx context.Context, project string, logstore string, ttl, shardCnt int) (err error)
+	UpdateLogStoreV2Ctx(ctx context.Context, project string, logstore *LogStore) (err error)
+	CheckLogstoreExistCtx(ctx context.Context, project string, logstore string) (bool, error)
+	GetLogStoreMeteringModeCtx(ctx context.Context, project string, logstore string) (*GetMeteringModeResponse, error)
+	UpdateLogStoreMeteringModeCtx(ctx context.Context, project string, logstore string, meteringMode string) error
+
+	// #################### ETL Operations #####################
+	CreateETLCtx(ctx context.Context, project string, etljob ETL) error
+	UpdateETLCtx(ctx context.Context, project string, etljob ETL) error
+	GetETLCtx(ctx context.Context, project string, etlName string) (ETLJob *ETL, err error)
+	ListETLCtx(ctx context.Context, project string, offset int, size int) (*ListETLResponse, error)
+	DeleteETLCtx(ctx context.Context, project string, etlName string) error
+	StartETLCtx(ctx context.Context, project, name string) error
+	StopETLCtx(ctx context.Context, project, name string) error
+	RestartETLCtx(ctx context.Context, project string, etljob ETL) error
+	CreateEtlMetaCtx(ctx context.Context, project string, etlMeta *EtlMeta) (err error)
+	UpdateEtlMetaCtx(ctx context.Context, project string, etlMeta *EtlMeta) (err error)
+	DeleteEtlMetaCtx(ctx context.Context, project string, etlMetaName, etlMetaKey string) (err error)
+	GetEtlMetaCtx(ctx context.Context, project string, etlMetaName, etlMetaKey string) (etlMeta *EtlMeta, err error)
+	ListEtlMetaCtx(ctx context.Context, project string, etlMetaName string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error)
+	ListEtlMetaWithTagCtx(ctx context.Context, project string, etlMetaName, etlMetaTag string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error)
+	ListEtlMetaNameCtx(ctx context.Context, project string, offset, size int) (total int, count int, etlMetaNameList []string, err error)
+
+	// #################### Shard Operations #####################
+	ListShardsCtx(ctx context.Context, project, logstore string) (shardIDs []*Shard, err error)
+	SplitShardCtx(ctx context.Context, project, logstore string, shardID int, splitKey string) (shards []*Shard, err error)
+	SplitNumShardCtx(ctx context.Context, project, logstore string, shardID, shardsNum int) (shards []*Shard, err error)
+	MergeShardsCtx(ctx context.Context, project, logstore string, shardID int) (shards []*Shard, err error)
+
+	// #################### Log Operations #####################
+	PutLogsWithMetricStoreURLCtx(ctx context.Context, project, logstore string, lg *LogGroup) (err error)
+	PutLogsCtx(ctx context.Context, project, logstore string, lg *LogGroup) (err error)
+	PostLogStoreLogsCtx(ctx context.Context, project, logstore string, lg *LogGroup, hashKey *string) (err error)
+	PostLogStoreLogsV2Ctx(ctx context.Context, project, logstore string, req *PostLogStoreLogsRequest) (err error)
+	PostRawLogWithCompressTypeCtx(ctx context.Context, project, logstore string, rawLogData []byte, compressType int, hashKey *string) (err error)
+	PutLogsWithCompressTypeCtx(ctx context.Context, project, logstore string, lg *LogGroup, compressType int) (err error)
+	PutRawLogWithCompressTypeCtx(ctx context.Context, project, logstore string, rawLogData []byte, compressType int) (err error)
+	GetCursorCtx(ctx context.Context, project, logstore string, shardID int, from string) (cursor string, err error)
+	GetCursorTimeCtx(ctx context.Context, project, logstore string, shardID int, cursor string) (cursorTime time.Time, err error)
+	GetLogsBytesCtx(ctx context.Context, project, logstore string, shardID int, cursor, endCursor string, logGroupMaxCount int) (out []byte, nextCursor string, err error)
+	GetLogsBytesV2Ctx(ctx context.Context, plr *PullLogRequest) (out []byte, nextCursor string, err error)
+	GetLogsBytesWithQueryCtx(ctx context.Context, plr *PullLogRequest) (out []byte, plm *PullLogMeta, err error)
+	PullLogsCtx(ctx context.Context, project, logstore string, shardID int, cursor, endCursor string, logGroupMaxCount int) (gl *LogGroupList, nextCursor string, err error)
+	PullLogsV2Ctx(ctx context.Context, plr *PullLogRequest) (gl *LogGroupList, nextCursor string, err error)
+	PullLogsWithQueryCtx(ctx context.Context, plr *PullLogRequest) (gl *LogGroupList, plm *PullLogMeta, err error)
+	GetHistogramsCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error)
+	GetHistogramsV2Ctx(ctx context.Context, project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error)
+	GetLogsCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error)
+	GetLogLinesCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error)
+	GetLogsByNanoCtx(ctx context.Context, project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error)
+	GetLogLinesByNanoCtx(ctx context.Context, project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error)
+	GetLogsV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsResponse, error)
+	GetLogLinesV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogLinesResponse, error)
+	GetLogsV3Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error)
+	GetHistogramsToCompletedCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error)
+	GetHistogramsToCompletedV2Ctx(ctx context.Context, project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error)
+	GetLogsToCompletedCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error)
+	GetLogsToCompletedV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsResponse, error)
+	GetLogsToCompletedV3Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error)
+
+	// #################### Index Operations #####################
+	CreateIndexCtx(ctx context.Context, project, logstore string, index Index) error
+	CreateIndexStringCtx(ctx context.Context, project, logstore string, index string) error
+	UpdateIndexCtx(ctx context.Context, project, logstore string, index Index) error
+	UpdateIndexStringCtx(ctx context.Context, project, logstore string, index string) error
+	DeleteIndexCtx(ctx context.Context, project, logstore string) error
+	GetIndexCtx(ctx context.Context, project, logstore string) (*Index, error)
+	GetIndexStringCtx(ctx context.Context, project, logstore string) (string, error)
+
+	// #################### SavedSearch&Alert Operations #####################
+	CreateSavedSearchCtx(ctx context.Context, project string, savedSearch *SavedSearch) error
+	UpdateSavedSearchCtx(ctx context.Context, project string, savedSearch *SavedSearch) error
+	DeleteSavedSearchCtx(ctx context.Context, project string, savedSearchName string) error
+	GetSavedSearchCtx(ctx context.Context, project string, savedSearchName string) (*SavedSearch, error)
+	ListSavedSearchCtx(ctx context.Context, project string, savedSearchName string, offset, size int) (savedSearches []string, total int, count int, err error)
+	ListSavedSearchV2Ctx(ctx context.Context, project string, savedSearchName string, offset, size int) (savedSearches []string, savedsearchItems []ResponseSavedSearchItem, total int, count int, err error)
+	CreateAlertCtx(ctx context.Context, project string, alert *Alert) error
+	UpdateAlertCtx(ctx context.Context, project string, alert *Alert) error
+	DeleteAlertCtx(ctx context.Context, project string, alertName string) error
+	GetAlertCtx(ctx context.Context, project string, alertName string) (*Alert, error)
+	DisableAlertCtx(ctx context.Context, project string, alertName string) error
+	EnableAlertCtx(ctx context.Context, project string, alertName string) error
+	ListAlertCtx(ctx context.Context, project, alertName, dashboard string, offset, size int) (alerts []*Alert, total int, count int, err error)
+	CreateAlertStringCtx(ctx context.Context, project string, alert string) error
+	UpdateAlertStringCtx(ctx context.Context, project string, alertName, alert string) error
+	GetAlertStringCtx(ctx context.Context, project string, alertName string) (string, error)
+
+	// #################### Consumer Operations #####################
+	CreateConsumerGroupCtx(ctx context.Context, project, logstore string, cg ConsumerGroup) (err error)
+	UpdateConsumerGroupCtx(ctx context.Context, project, logstore string, cg ConsumerGroup) (err error)
+	DeleteConsumerGroupCtx(ctx context.Context, project, logstore string, cgName string) (err error)
+	ListConsumerGroupCtx(ctx context.Context, project, logstore string) (cgList []*ConsumerGroup, err error)
+	HeartBeatCtx(ctx context.Context, project, logstore string, cgName, consumer string, heartBeatShardIDs []int) (shardIDs []int, err error)
+	UpdateCheckpointCtx(ctx context.Context, project, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) (err error)
+	GetCheckpointCtx(ctx context.Context, project, logstore string, cgName string) (checkPointList []*ConsumerGroupCheckPoint, err error)
+
+	// #################### ScheduledSQL Operations #####################
+	CreateScheduledSQLCtx(ctx context.Context, project string, scheduledsql *ScheduledSQL) error
+	DeleteScheduledSQLCtx(ctx context.Context, project string, name string) error
+	UpdateScheduledSQLCtx(ctx context.Context, project string, scheduledsql *ScheduledSQL) error
+	GetScheduledSQLCtx(ctx context.Context, project string, name string) (*ScheduledSQL, error)
+	ListScheduledSQLCtx(ctx context.Context, project, name, displayName string, offset, size int) (scheduledsqls []*ScheduledSQL, total, count int, error error)
+	GetScheduledSQLJobInstanceCtx(ctx context.Context, projectName, jobName, instanceId string, result bool) (*ScheduledSQLJobInstance, error)
+	ModifyScheduledSQLJobInstanceStateCtx(ctx context.Context, projectName, jobName, instanceId string, state ScheduledSQLState) error
+	ListScheduledSQLJobInstancesCtx(ctx context.Context, projectName, jobName string, status *InstanceStatus) (instances []*ScheduledSQLJobInstance, total, count int64, err error)
+
+	// #################### Ingestion #####################
+	CreateIngestionCtx(ctx context.Context, project string, ingestion *Ingestion) error
+	UpdateIngestionCtx(ctx context.Context, project string, ingestion *Ingestion) error
+	GetIngestionCtx(ctx context.Context, project string, name string) (*Ingestion, error)
+	ListIngestionCtx(ctx context.Context, project, logstore, name, displayName string, offset, size int) (ingestions []*Ingestion, total, count int, error error)
+	DeleteIngestionCtx(ctx context.Context, project string, name string) error
+
+	// #################### Export #####################
+	CreateExportCtx(ctx context.Context, project string, export *Export) error
+	UpdateExportCtx(ctx context.Context, project string, export *Export) error
+	GetExportCtx(ctx context.Context, project, name string) (*Export, error)
+	ListExportCtx(ctx context.Context, project, logstore, name, displayName string, offset, size int) (exports []*Export, total, count int, error error)
+	DeleteExportCtx(ctx context.Context, project string, name string) error
+	RestartExportCtx(ctx context.Context, project string, export *Export) error
+}
+
+var _ ClientInterfaceWithContext = (*Client)(nil)
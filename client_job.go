@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -363,6 +364,11 @@ func (e *ExportConfiguration) UnmarshalJSON(data []byte) error {
 }
 
 func (c *Client) CreateIngestion(project string, ingestion *Ingestion) error {
+	return c.CreateIngestionCtx(context.Background(), project, ingestion)
+}
+
+// CreateIngestionCtx is like CreateIngestion, but the request is bound to ctx.
+func (c *Client) CreateIngestionCtx(ctx context.Context, project string, ingestion *Ingestion) error {
 	body, err := json.Marshal(ingestion)
 	if err != nil {
 		return NewClientError(err)
@@ -372,7 +378,7 @@ func (c *Client) CreateIngestion(project string, ingestion *Ingestion) error {
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -381,6 +387,11 @@ func (c *Client) CreateIngestion(project string, ingestion *Ingestion) error {
 }
 
 func (c *Client) UpdateIngestion(project string, ingestion *Ingestion) error {
+	return c.UpdateIngestionCtx(context.Background(), project, ingestion)
+}
+
+// UpdateIngestionCtx is like UpdateIngestion, but the request is bound to ctx.
+func (c *Client) UpdateIngestionCtx(ctx context.Context, project string, ingestion *Ingestion) error {
 	body, err := json.Marshal(ingestion)
 	if err != nil {
 		return NewClientError(err)
@@ -390,7 +401,7 @@ func (c *Client) UpdateIngestion(project string, ingestion *Ingestion) error {
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + ingestion.Name
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -399,12 +410,17 @@ func (c *Client) UpdateIngestion(project string, ingestion *Ingestion) error {
 }
 
 func (c *Client) GetIngestion(project string, name string) (*Ingestion, error) {
+	return c.GetIngestionCtx(context.Background(), project, name)
+}
+
+// GetIngestionCtx is like GetIngestion, but the request is bound to ctx.
+func (c *Client) GetIngestionCtx(ctx context.Context, project string, name string) (*Ingestion, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + name
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -421,6 +437,11 @@ func (c *Client) GetIngestion(project string, name string) (*Ingestion, error) {
 }
 
 func (c *Client) ListIngestion(project, logstore, name, displayName string, offset, size int) (ingestions []*Ingestion, total, count int, error error) {
+	return c.ListIngestionCtx(context.Background(), project, logstore, name, displayName, offset, size)
+}
+
+// ListIngestionCtx is like ListIngestion, but the request is bound to ctx.
+func (c *Client) ListIngestionCtx(ctx context.Context, project, logstore, name, displayName string, offset, size int) (ingestions []*Ingestion, total, count int, error error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -435,7 +456,7 @@ func (c *Client) ListIngestion(project, logstore, name, displayName string, offs
 	v.Add("offset", fmt.Sprintf("%d", offset))
 	v.Add("size", fmt.Sprintf("%d", size))
 	uri := "/jobs?" + v.Encode()
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -457,12 +478,17 @@ func (c *Client) ListIngestion(project, logstore, name, displayName string, offs
 }
 
 func (c *Client) DeleteIngestion(project string, name string) error {
+	return c.DeleteIngestionCtx(context.Background(), project, name)
+}
+
+// DeleteIngestionCtx is like DeleteIngestion, but the request is bound to ctx.
+func (c *Client) DeleteIngestionCtx(ctx context.Context, project string, name string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + name
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -471,6 +497,11 @@ func (c *Client) DeleteIngestion(project string, name string) error {
 }
 
 func (c *Client) CreateExport(project string, export *Export) error {
+	return c.CreateExportCtx(context.Background(), project, export)
+}
+
+// CreateExportCtx is like CreateExport, but the request is bound to ctx.
+func (c *Client) CreateExportCtx(ctx context.Context, project string, export *Export) error {
 	body, err := json.Marshal(export)
 	if err != nil {
 		return NewClientError(err)
@@ -480,7 +511,7 @@ func (c *Client) CreateExport(project string, export *Export) error {
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -488,6 +519,11 @@ func (c *Client) CreateExport(project string, export *Export) error {
 	return nil
 }
 func (c *Client) UpdateExport(project string, export *Export) error {
+	return c.UpdateExportCtx(context.Background(), project, export)
+}
+
+// UpdateExportCtx is like UpdateExport, but the request is bound to ctx.
+func (c *Client) UpdateExportCtx(ctx context.Context, project string, export *Export) error {
 	body, err := json.Marshal(export)
 	if err != nil {
 		return NewClientError(err)
@@ -497,7 +533,7 @@ func (c *Client) UpdateExport(project string, export *Export) error {
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + export.Name
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -505,12 +541,17 @@ func (c *Client) UpdateExport(project string, export *Export) error {
 	return nil
 }
 func (c *Client) GetExport(project, name string) (*Export, error) {
+	return c.GetExportCtx(context.Background(), project, name)
+}
+
+// GetExportCtx is like GetExport, but the request is bound to ctx.
+func (c *Client) GetExportCtx(ctx context.Context, project, name string) (*Export, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + name
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -526,6 +567,11 @@ func (c *Client) GetExport(project, name string) (*Export, error) {
 	return export, err
 }
 func (c *Client) ListExport(project, logstore, name, displayName string, offset, size int) (exports []*Export, total, count int, error error) {
+	return c.ListExportCtx(context.Background(), project, logstore, name, displayName, offset, size)
+}
+
+// ListExportCtx is like ListExport, but the request is bound to ctx.
+func (c *Client) ListExportCtx(ctx context.Context, project, logstore, name, displayName string, offset, size int) (exports []*Export, total, count int, error error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -540,7 +586,7 @@ func (c *Client) ListExport(project, logstore, name, displayName string, offset,
 	v.Add("offset", fmt.Sprintf("%d", offset))
 	v.Add("size", fmt.Sprintf("%d", size))
 	uri := "/jobs?" + v.Encode()
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -561,12 +607,17 @@ func (c *Client) ListExport(project, logstore, name, displayName string, offset,
 	return el.Results, el.Total, el.Count, err
 }
 func (c *Client) DeleteExport(project string, name string) error {
+	return c.DeleteExportCtx(context.Background(), project, name)
+}
+
+// DeleteExportCtx is like DeleteExport, but the request is bound to ctx.
+func (c *Client) DeleteExportCtx(ctx context.Context, project string, name string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + name
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -575,6 +626,11 @@ func (c *Client) DeleteExport(project string, name string) error {
 }
 
 func (c *Client) RestartExport(project string, export *Export) error {
+	return c.RestartExportCtx(context.Background(), project, export)
+}
+
+// RestartExportCtx is like RestartExport, but the request is bound to ctx.
+func (c *Client) RestartExportCtx(ctx context.Context, project string, export *Export) error {
 	body, err := json.Marshal(export)
 	if err != nil {
 		return NewClientError(err)
@@ -584,7 +640,7 @@ func (c *Client) RestartExport(project string, export *Export) error {
 		"Content-Type":      "application/json",
 	}
 	uri := fmt.Sprintf("/jobs/%s?action=RESTART", export.Name)
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
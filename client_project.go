@@ -1,19 +1,22 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
-
-	"github.com/go-kit/kit/log/level"
 )
 
 // ListLogStore returns all logstore names of project p.
 func (c *Client) ListLogStore(project string) ([]string, error) {
-	proj := convert(c, project)
+	return c.ListLogStoreCtx(context.Background(), project)
+}
+
+// ListLogStoreCtx is like ListLogStore, but the request is bound to ctx.
+func (c *Client) ListLogStoreCtx(ctx context.Context, project string) ([]string, error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.ListLogStore()
 }
 
@@ -23,13 +26,23 @@ func (c *Client) ListLogStore(project string) ([]string, error) {
 //	size: max return size
 //	telemetryType : telemetry type filter
 func (c *Client) ListLogStoreV2(project string, offset, size int, telemetryType string) ([]string, error) {
-	proj := convert(c, project)
+	return c.ListLogStoreV2Ctx(context.Background(), project, offset, size, telemetryType)
+}
+
+// ListLogStoreV2Ctx is like ListLogStoreV2, but the request is bound to ctx.
+func (c *Client) ListLogStoreV2Ctx(ctx context.Context, project string, offset, size int, telemetryType string) ([]string, error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.ListLogStoreV2(offset, size, telemetryType)
 }
 
 // GetLogStore returns logstore according by logstore name.
 func (c *Client) GetLogStore(project string, logstore string) (*LogStore, error) {
-	proj := convert(c, project)
+	return c.GetLogStoreCtx(context.Background(), project, logstore)
+}
+
+// GetLogStoreCtx is like GetLogStore, but the request is bound to ctx.
+func (c *Client) GetLogStoreCtx(ctx context.Context, project string, logstore string) (*LogStore, error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.GetLogStore(logstore)
 }
 
@@ -40,46 +53,81 @@ func (c *Client) GetLogStore(project string, logstore string) (*LogStore, error)
 // and autoSplit is auto split,
 // and maxSplitShard is the max number of shard.
 func (c *Client) CreateLogStore(project string, logstore string, ttl, shardCnt int, autoSplit bool, maxSplitShard int) error {
-	proj := convert(c, project)
+	return c.CreateLogStoreCtx(context.Background(), project, logstore, ttl, shardCnt, autoSplit, maxSplitShard)
+}
+
+// CreateLogStoreCtx is like CreateLogStore, but the request is bound to ctx.
+func (c *Client) CreateLogStoreCtx(ctx context.Context, project string, logstore string, ttl, shardCnt int, autoSplit bool, maxSplitShard int) error {
+	proj := convertWithContext(ctx, c, project)
 	return proj.CreateLogStore(logstore, ttl, shardCnt, autoSplit, maxSplitShard)
 }
 
 // CreateLogStoreV2 creates a new logstore in SLS
 func (c *Client) CreateLogStoreV2(project string, logstore *LogStore) error {
-	proj := convert(c, project)
+	return c.CreateLogStoreV2Ctx(context.Background(), project, logstore)
+}
+
+// CreateLogStoreV2Ctx is like CreateLogStoreV2, but the request is bound to ctx.
+func (c *Client) CreateLogStoreV2Ctx(ctx context.Context, project string, logstore *LogStore) error {
+	proj := convertWithContext(ctx, c, project)
 	return proj.CreateLogStoreV2(logstore)
 }
 
 // DeleteLogStore deletes a logstore according by logstore name.
 func (c *Client) DeleteLogStore(project string, logstore string) (err error) {
-	proj := convert(c, project)
+	return c.DeleteLogStoreCtx(context.Background(), project, logstore)
+}
+
+// DeleteLogStoreCtx is like DeleteLogStore, but the request is bound to ctx.
+func (c *Client) DeleteLogStoreCtx(ctx context.Context, project string, logstore string) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.DeleteLogStore(logstore)
 }
 
 // UpdateLogStore updates a logstore according by logstore name,
 // obviously we can't modify the logstore name itself.
 func (c *Client) UpdateLogStore(project string, logstore string, ttl, shardCnt int) (err error) {
-	proj := convert(c, project)
+	return c.UpdateLogStoreCtx(context.Background(), project, logstore, ttl, shardCnt)
+}
+
+// UpdateLogStoreCtx is like UpdateLogStore, but the request is bound to ctx.
+func (c *Client) UpdateLogStoreCtx(ctx context.Context, project string, logstore string, ttl, shardCnt int) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.UpdateLogStore(logstore, ttl, shardCnt)
 }
 
 // UpdateLogStoreV2 updates a logstore according by logstore name,
 // obviously we can't modify the logstore name itself.
 func (c *Client) UpdateLogStoreV2(project string, logstore *LogStore) (err error) {
-	proj := convert(c, project)
+	return c.UpdateLogStoreV2Ctx(context.Background(), project, logstore)
+}
+
+// UpdateLogStoreV2Ctx is like UpdateLogStoreV2, but the request is bound to ctx.
+func (c *Client) UpdateLogStoreV2Ctx(ctx context.Context, project string, logstore *LogStore) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.UpdateLogStoreV2(logstore)
 }
 
 // GetLogStoreMeteringMode get the metering mode of logstore, eg. ChargeByFunction / ChargeByDataIngest
 func (c *Client) GetLogStoreMeteringMode(project string, logstore string) (*GetMeteringModeResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogStoreMeteringModeCtx(context.Background(), project, logstore)
+}
+
+// GetLogStoreMeteringModeCtx is like GetLogStoreMeteringMode, but the request is bound to ctx.
+func (c *Client) GetLogStoreMeteringModeCtx(ctx context.Context, project string, logstore string) (*GetMeteringModeResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetMeteringMode()
 }
 
 // GetLogStoreMeteringMode update the metering mode of logstore, eg. ChargeByFunction / ChargeByDataIngest
 // Warning: this method may affect your billings, for more details ref: https://www.aliyun.com/price/detail/sls
 func (c *Client) UpdateLogStoreMeteringMode(project string, logstore string, meteringMode string) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.UpdateLogStoreMeteringModeCtx(context.Background(), project, logstore, meteringMode)
+}
+
+// UpdateLogStoreMeteringModeCtx is like UpdateLogStoreMeteringMode, but the request is bound to ctx.
+func (c *Client) UpdateLogStoreMeteringModeCtx(ctx context.Context, project string, logstore string, meteringMode string) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.UpdateMeteringMode(meteringMode)
 }
 
@@ -107,17 +155,7 @@ func (c *Client) ListMachines(project, machineGroupName string) (ms []*Machine,
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to remove config from machine group")
-			if IsDebugLevelMatched(1) {
-				dump, _ := httputil.DumpResponse(r, true)
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 
@@ -149,17 +187,7 @@ func (c *Client) ListMachinesV2(project, machineGroupName string, offset, size i
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to unmarshal list machines response: %v", err)
-			if IsDebugLevelMatched(1) {
-				dump, _ := httputil.DumpResponse(r, true)
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 
@@ -176,7 +204,12 @@ func (c *Client) ListMachinesV2(project, machineGroupName string, offset, size i
 
 // CheckLogstoreExist check logstore exist or not
 func (c *Client) CheckLogstoreExist(project string, logstore string) (bool, error) {
-	proj := convert(c, project)
+	return c.CheckLogstoreExistCtx(context.Background(), project, logstore)
+}
+
+// CheckLogstoreExistCtx is like CheckLogstoreExist, but the request is bound to ctx.
+func (c *Client) CheckLogstoreExistCtx(ctx context.Context, project string, logstore string) (bool, error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.CheckLogstoreExist(logstore)
 }
 
@@ -310,40 +343,75 @@ func (c *Client) RemoveConfigFromMachineGroup(project string, confName, groupNam
 }
 
 func (c *Client) CreateEtlMeta(project string, etlMeta *EtlMeta) (err error) {
-	proj := convert(c, project)
+	return c.CreateEtlMetaCtx(context.Background(), project, etlMeta)
+}
+
+// CreateEtlMetaCtx is like CreateEtlMeta, but the request is bound to ctx.
+func (c *Client) CreateEtlMetaCtx(ctx context.Context, project string, etlMeta *EtlMeta) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.CreateEtlMeta(etlMeta)
 }
 
 func (c *Client) UpdateEtlMeta(project string, etlMeta *EtlMeta) (err error) {
-	proj := convert(c, project)
+	return c.UpdateEtlMetaCtx(context.Background(), project, etlMeta)
+}
+
+// UpdateEtlMetaCtx is like UpdateEtlMeta, but the request is bound to ctx.
+func (c *Client) UpdateEtlMetaCtx(ctx context.Context, project string, etlMeta *EtlMeta) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.UpdateEtlMeta(etlMeta)
 }
 
 func (c *Client) DeleteEtlMeta(project string, etlMetaName, etlMetaKey string) (err error) {
-	proj := convert(c, project)
+	return c.DeleteEtlMetaCtx(context.Background(), project, etlMetaName, etlMetaKey)
+}
+
+// DeleteEtlMetaCtx is like DeleteEtlMeta, but the request is bound to ctx.
+func (c *Client) DeleteEtlMetaCtx(ctx context.Context, project string, etlMetaName, etlMetaKey string) (err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.DeleteEtlMeta(etlMetaName, etlMetaKey)
 }
 
-func (c *Client) listEtlMeta(project string, etlMetaName, etlMetaKey, etlMetaTag string, offset, size int) (total int, count int, etlMeta []*EtlMeta, err error) {
-	proj := convert(c, project)
+func (c *Client) listEtlMeta(ctx context.Context, project string, etlMetaName, etlMetaKey, etlMetaTag string, offset, size int) (total int, count int, etlMeta []*EtlMeta, err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.listEtlMeta(etlMetaName, etlMetaKey, etlMetaTag, offset, size)
 }
 
 func (c *Client) GetEtlMeta(project string, etlMetaName, etlMetaKey string) (etlMeta *EtlMeta, err error) {
-	proj := convert(c, project)
+	return c.GetEtlMetaCtx(context.Background(), project, etlMetaName, etlMetaKey)
+}
+
+// GetEtlMetaCtx is like GetEtlMeta, but the request is bound to ctx.
+func (c *Client) GetEtlMetaCtx(ctx context.Context, project string, etlMetaName, etlMetaKey string) (etlMeta *EtlMeta, err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.GetEtlMeta(etlMetaName, etlMetaKey)
 }
 
 func (c *Client) ListEtlMeta(project string, etlMetaName string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
-	return c.listEtlMeta(project, etlMetaName, "", EtlMetaAllTagMatch, offset, size)
+	return c.ListEtlMetaCtx(context.Background(), project, etlMetaName, offset, size)
+}
+
+// ListEtlMetaCtx is like ListEtlMeta, but the request is bound to ctx.
+func (c *Client) ListEtlMetaCtx(ctx context.Context, project string, etlMetaName string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
+	return c.listEtlMeta(ctx, project, etlMetaName, "", EtlMetaAllTagMatch, offset, size)
 }
 
 func (c *Client) ListEtlMetaWithTag(project string, etlMetaName, etlMetaTag string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
-	return c.listEtlMeta(project, etlMetaName, "", etlMetaTag, offset, size)
+	return c.ListEtlMetaWithTagCtx(context.Background(), project, etlMetaName, etlMetaTag, offset, size)
+}
+
+// ListEtlMetaWithTagCtx is like ListEtlMetaWithTag, but the request is bound to ctx.
+func (c *Client) ListEtlMetaWithTagCtx(ctx context.Context, project string, etlMetaName, etlMetaTag string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
+	return c.listEtlMeta(ctx, project, etlMetaName, "", etlMetaTag, offset, size)
 }
 
 func (c *Client) ListEtlMetaName(project string, offset, size int) (total int, count int, etlMetaNameList []string, err error) {
-	proj := convert(c, project)
+	return c.ListEtlMetaNameCtx(context.Background(), project, offset, size)
+}
+
+// ListEtlMetaNameCtx is like ListEtlMetaName, but the request is bound to ctx.
+func (c *Client) ListEtlMetaNameCtx(ctx context.Context, project string, offset, size int) (total int, count int, etlMetaNameList []string, err error) {
+	proj := convertWithContext(ctx, c, project)
 	return proj.ListEtlMetaName(offset, size)
 }
 
@@ -3,12 +3,14 @@ package sls
 // request sends a request to SLS.
 import (
 	"bytes"
+	"context"
 	"fmt"
 
 	"io/ioutil"
 	"net/http"
 	"net/http/httputil"
 	"strings"
+	"time"
 
 	"github.com/go-kit/kit/log/level"
 )
@@ -16,28 +18,101 @@ import (
 // request sends a request to alibaba cloud Log Service.
 // @note if error is nil, you must call http.Response.Body.Close() to finalize reader
 func (c *Client) request(project, method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
+	return c.requestWithContext(context.Background(), project, method, uri, headers, body)
+}
+
+// requestWithContext sends a request to alibaba cloud Log Service,
+// the in-flight http request is aborted once ctx is done.
+// The request is retried according to the retry policy of the client, if any.
+// @note if error is nil, you must call http.Response.Body.Close() to finalize reader
+func (c *Client) requestWithContext(ctx context.Context, project, method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
+	c.accessKeyLock.RLock()
+	tracer := c.tracer
+	policy := c.retryPolicy
+	retryTimeout := c.RetryTimeOut
+	metrics := c.metrics
+	c.accessKeyLock.RUnlock()
+
+	start := time.Now()
+	op := resolveOperation(method, uri)
+	ctx, span := startOperationSpan(ctx, tracer, project, op)
+	if policy == nil {
+		// send only once without retry policy
+		attemptCtx, attemptSpan := startAttemptSpan(ctx, tracer, method, op, 1)
+		resp, err := c.sendRequest(attemptCtx, project, method, uri, headers, body)
+		endSpan(attemptSpan, resp, err)
+		endSpan(span, resp, err)
+		if metrics != nil {
+			metrics.observe(project, op, start, 1, err)
+		}
+		return resp, err
+	}
+
+	if retryTimeout == 0 {
+		retryTimeout = defaultRetryTimeout
+	}
+	// retryCtx only limits the retries, the response body is read after requestWithContext returns
+	retryCtx, cancel := context.WithTimeout(ctx, retryTimeout)
+	defer cancel()
+	var resp *http.Response
+	var slsErr error
+	attempt := 0
+	err := RetryWithCondition(retryCtx, policy.newBackOff(), func() (bool, error) {
+		attempt++
+		attemptCtx, attemptSpan := startAttemptSpan(ctx, tracer, method, op, attempt)
+		resp, slsErr = c.sendRequest(attemptCtx, project, method, uri, headers, body)
+		endSpan(attemptSpan, resp, slsErr)
+		return policy.shouldRetry(method, slsErr), slsErr
+	})
+	if err == nil {
+		err = slsErr
+	}
+	endSpan(span, resp, err)
+	if metrics != nil {
+		metrics.observe(project, op, start, attempt, err)
+	}
+	return resp, err
+}
+
+// sendRequest sends a request once, to the endpoints of the client in turn on connection errors
+// if there are multiple endpoints.
+func (c *Client) sendRequest(ctx context.Context, project, method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
+	c.accessKeyLock.RLock()
+	endpoint := c.Endpoint
+	endpoints := c.endpoints
+	c.accessKeyLock.RUnlock()
+	if endpoints == nil {
+		return c.sendRequestTo(ctx, endpoint, project, method, uri, headers, body)
+	}
+	return endpoints.do(func(endpoint string) (*http.Response, error) {
+		return c.sendRequestTo(ctx, endpoint, project, method, uri, headers, body)
+	})
+}
+
+// sendRequestTo signs a request and sends it to endpoint through the interceptors of the client.
+func (c *Client) sendRequestTo(ctx context.Context, endpoint, project, method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
 	// The caller should provide 'x-log-bodyrawsize' header
 	if _, ok := headers[HTTPHeaderBodyRawSize]; !ok {
 		return nil, fmt.Errorf("Can't find 'x-log-bodyrawsize' header")
 	}
 
-	var endpoint string
+	var host string
 	var usingHTTPS bool
-	if strings.HasPrefix(c.Endpoint, "https://") {
-		endpoint = c.Endpoint[8:]
+	if strings.HasPrefix(endpoint, "https://") {
+		host = endpoint[8:]
 		usingHTTPS = true
-	} else if strings.HasPrefix(c.Endpoint, "http://") {
-		endpoint = c.Endpoint[7:]
+	} else if strings.HasPrefix(endpoint, "http://") {
+		host = endpoint[7:]
 	} else {
-		endpoint = c.Endpoint
+		host = endpoint
 	}
 
 	// SLS public request headers
 	var hostStr string
 	if len(project) == 0 {
-		hostStr = endpoint
+		hostStr = host
 	} else {
-		hostStr = project + "." + endpoint
+		hostStr = project + "." + host
 	}
 	headers[HTTPHeaderHost] = hostStr
 	headers[HTTPHeaderAPIVersion] = version
@@ -54,6 +129,7 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 	accessKeySecret := c.AccessKeySecret
 	region := c.Region
 	authVersion := c.AuthVersion
+	newSigner := c.newSigner
 	c.accessKeyLock.RUnlock()
 
 	if c.credentialsProvider != nil {
@@ -89,13 +165,15 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 		headers[HTTPHeaderDate] = nowRFC1123()
 		signer = NewSignerV1(accessKeyID, accessKeySecret)
 	}
+	if newSigner != nil {
+		signer = newSigner(Credentials{AccessKeyID: accessKeyID, AccessKeySecret: accessKeySecret, SecurityToken: stsToken})
+	}
 	if err := signer.Sign(method, uri, headers, body); err != nil {
 		return nil, err
 	}
 
 	addHeadersAfterSign(c.CommonHeaders, headers)
-	// Initialize http request
-	reader := bytes.NewReader(body)
+
 	var urlStr string
 	// using http as default
 	if !GlobalForceUsingHTTP && usingHTTPS {
@@ -103,12 +181,49 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 	} else {
 		urlStr = "http://"
 	}
-	urlStr += hostStr + uri
-	req, err := http.NewRequest(method, urlStr, reader)
+	urlStr += hostStr
+
+	c.accessKeyLock.RLock()
+	interceptors := c.interceptors
+	rateLimiter := c.rateLimiter
+	breaker := c.breaker
+	metrics := c.metrics
+	c.accessKeyLock.RUnlock()
+	var rt RoundTrip = func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+		return c.doRequest(ctx, urlStr, req)
+	}
+	rt = chainInterceptors(rt, interceptors)
+	rt = statusErrorInterceptor(func(body []byte, resp *http.Response) error {
+		return httpStatusNotOkError(body, resp.Header, resp.StatusCode)
+	})(rt)
+	if metrics != nil {
+		rt = metrics.interceptor()(rt)
+	}
+	if rateLimiter != nil {
+		rt = rateLimiter.interceptor()(rt)
+	}
+	if breaker != nil {
+		rt = breaker.interceptor(endpoint)(rt)
+	}
+	return rt(ctx, &RoundTripRequest{
+		Project: project,
+		Method:  method,
+		URI:     uri,
+		Headers: headers,
+		Body:    body,
+	})
+}
+
+// doRequest sends a signed request to urlPrefix + req.URI, the body of a non-200 response is read
+// into memory to be parsed into an error after the interceptors.
+func (c *Client) doRequest(ctx context.Context, urlPrefix string, rtReq *RoundTripRequest) (*http.Response, error) {
+	// Initialize http request
+	reader := bytes.NewReader(rtReq.Body)
+	req, err := http.NewRequestWithContext(ctx, rtReq.Method, urlPrefix+rtReq.URI, reader)
 	if err != nil {
 		return nil, err
 	}
-	for k, v := range headers {
+	for k, v := range rtReq.Headers {
 		req.Header.Add(k, v)
 	}
 	if IsDebugLevelMatched(5) {
@@ -129,14 +244,14 @@ func (c *Client) request(project, method, uri string, headers map[string]string,
 		return nil, err
 	}
 
-	// Parse the sls error from body.
 	if resp.StatusCode != http.StatusOK {
 		defer resp.Body.Close()
 		buf, err := ioutil.ReadAll(resp.Body)
 		if err != nil {
 			return nil, readResponseError(err)
 		}
-		return nil, httpStatusNotOkError(buf, resp.Header, resp.StatusCode)
+		resp.Body = newBufferedBody(buf)
+		return resp, nil
 	}
 	if IsDebugLevelMatched(5) {
 		dump, e := httputil.DumpResponse(resp, true)
@@ -1,11 +1,16 @@
 package sls
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -94,3 +99,116 @@ func (s *InvalidResponseTestSuite) TestInvalidResponse() {
 		s.Require().Nil(slsErr)
 	}
 }
+
+func (s *InvalidResponseTestSuite) TestRequestWithContext() {
+	project, logstore := "testProject", "testLogstore"
+	s.transport.Reset()
+	s.transport.RegisterResponder("POST", fmt.Sprintf("http://%s.%s/logstores/%s/logs", project, s.endpoint, logstore),
+		httpmock.NewStringResponder(500, `{"errorCode":"InternalServerError","errorMessage":"mock"}`))
+	s.transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, s.endpoint, logstore),
+		httpmock.NewStringResponder(200, `[]`))
+
+	// server errors are retried until the context is done, not until the retry timeout
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := s.client.GetLogsV2Ctx(ctx, project, logstore, &GetLogRequest{From: 1, To: 2, Query: "*"})
+	s.Require().Error(err)
+	s.Require().Less(time.Since(start), 5*time.Second)
+
+	_, err = s.client.GetCheckpoint(project, logstore, "cg")
+	s.Require().NoError(err)
+}
+
+func TestRequestContextAbortsInFlightRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(10 * time.Second):
+		}
+	}))
+	defer server.Close()
+	client := CreateNormalInterface(server.URL, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := client.GetCheckpointCtx(ctx, "", "testLogstore", "cg")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), context.DeadlineExceeded.Error())
+	require.Less(t, time.Since(start), 5*time.Second)
+
+	start = time.Now()
+	_, err = client.ListShardsCtx(ctx, "", "testLogstore")
+	require.Error(t, err)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+// headerSigner adds a custom header before signing with the wrapped signer.
+type headerSigner struct {
+	Signer
+	key, value string
+}
+
+func (s *headerSigner) Sign(method, uri string, headers map[string]string, body []byte) error {
+	headers[s.key] = s.value
+	return s.Signer.Sign(method, uri, headers, body)
+}
+
+func TestClientSetSigner(t *testing.T) {
+	var creds []Credentials
+	var verifyErr error
+	verify := func(body string) httpmock.Responder {
+		return func(req *http.Request) (*http.Response, error) {
+			require.Equal(t, "custom", req.Header.Get("x-log-custom"))
+			verifyErr = VerifyRequest(req, func(string) (string, error) { return "secret", nil })
+			return httpmock.NewStringResponse(200, body), nil
+		}
+	}
+	transport := httpmock.NewMockTransport()
+	// ListShards is sent by the LogProject, and GetCheckpoint by the Client
+	transport.RegisterResponder("GET", "http://test-project.mock-test-endpoint.aliyuncs.com/logstores/test-logstore/shards", verify(`[]`))
+	transport.RegisterResponder("GET", "http://test-project.mock-test-endpoint.aliyuncs.com/logstores/test-logstore/consumergroups/test-cg", verify(`[]`))
+	client := CreateNormalInterfaceV2("mock-test-endpoint.aliyuncs.com", NewStaticCredentialsProvider("id", "secret", "")).(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	client.SetSigner(func(cred Credentials) Signer {
+		creds = append(creds, cred)
+		return &headerSigner{Signer: NewSignerV1(cred.AccessKeyID, cred.AccessKeySecret), key: "x-log-custom", value: "custom"}
+	})
+	_, err := client.ListShards("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.NoError(t, verifyErr)
+	require.Equal(t, []Credentials{{AccessKeyID: "id", AccessKeySecret: "secret"}}, creds)
+
+	verifyErr = errors.New("not verified")
+	_, err = client.GetCheckpoint("test-project", "test-logstore", "test-cg")
+	require.NoError(t, err)
+	require.NoError(t, verifyErr)
+	require.Len(t, creds, 2)
+	require.Equal(t, Credentials{AccessKeyID: "id", AccessKeySecret: "secret"}, creds[1])
+}
+
+func TestGetToCompletedCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 2 {
+			// canceled while waiting to query again
+			time.AfterFunc(50*time.Millisecond, cancel)
+		}
+		w.Header().Set(ProgressHeader, "Incomplete")
+		w.Header().Set(GetLogsCountHeader, "0")
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	client := CreateNormalInterface(server.URL, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+
+	start := time.Now()
+	resp, err := client.GetHistogramsToCompletedCtx(ctx, "", "testLogstore", "", 1, 2, "*")
+	require.True(t, errors.Is(err, context.Canceled))
+	require.False(t, resp.IsComplete())
+	require.Equal(t, 2, requests)
+	require.Less(t, time.Since(start), 5*time.Second)
+}
@@ -1,6 +1,7 @@
 package sls
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -103,6 +104,11 @@ type ScheduledSQLParameters struct {
 }
 
 func (c *Client) CreateScheduledSQL(project string, scheduledsql *ScheduledSQL) error {
+	return c.CreateScheduledSQLCtx(context.Background(), project, scheduledsql)
+}
+
+// CreateScheduledSQLCtx is like CreateScheduledSQL, but the request is bound to ctx.
+func (c *Client) CreateScheduledSQLCtx(ctx context.Context, project string, scheduledsql *ScheduledSQL) error {
 	fromTime := scheduledsql.Configuration.FromTime
 	toTime := scheduledsql.Configuration.ToTime
 	timeRange := fromTime > 1451577600 && toTime > fromTime
@@ -120,7 +126,7 @@ func (c *Client) CreateScheduledSQL(project string, scheduledsql *ScheduledSQL)
 	}
 
 	uri := "/jobs"
-	r, err := c.request(project, "POST", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -129,13 +135,18 @@ func (c *Client) CreateScheduledSQL(project string, scheduledsql *ScheduledSQL)
 }
 
 func (c *Client) DeleteScheduledSQL(project string, name string) error {
+	return c.DeleteScheduledSQLCtx(context.Background(), project, name)
+}
+
+// DeleteScheduledSQLCtx is like DeleteScheduledSQL, but the request is bound to ctx.
+func (c *Client) DeleteScheduledSQLCtx(ctx context.Context, project string, name string) error {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 
 	uri := "/jobs/" + name
-	r, err := c.request(project, "DELETE", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "DELETE", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -144,6 +155,11 @@ func (c *Client) DeleteScheduledSQL(project string, name string) error {
 }
 
 func (c *Client) UpdateScheduledSQL(project string, scheduledsql *ScheduledSQL) error {
+	return c.UpdateScheduledSQLCtx(context.Background(), project, scheduledsql)
+}
+
+// UpdateScheduledSQLCtx is like UpdateScheduledSQL, but the request is bound to ctx.
+func (c *Client) UpdateScheduledSQLCtx(ctx context.Context, project string, scheduledsql *ScheduledSQL) error {
 	body, err := json.Marshal(scheduledsql)
 	if err != nil {
 		return NewClientError(err)
@@ -154,7 +170,7 @@ func (c *Client) UpdateScheduledSQL(project string, scheduledsql *ScheduledSQL)
 	}
 
 	uri := "/jobs/" + scheduledsql.Name
-	r, err := c.request(project, "PUT", uri, h, body)
+	r, err := c.requestWithContext(ctx, project, "PUT", uri, h, body)
 	if err != nil {
 		return err
 	}
@@ -163,12 +179,17 @@ func (c *Client) UpdateScheduledSQL(project string, scheduledsql *ScheduledSQL)
 }
 
 func (c *Client) GetScheduledSQL(project string, name string) (*ScheduledSQL, error) {
+	return c.GetScheduledSQLCtx(context.Background(), project, name)
+}
+
+// GetScheduledSQLCtx is like GetScheduledSQL, but the request is bound to ctx.
+func (c *Client) GetScheduledSQLCtx(ctx context.Context, project string, name string) (*ScheduledSQL, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := "/jobs/" + name
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -185,6 +206,11 @@ func (c *Client) GetScheduledSQL(project string, name string) (*ScheduledSQL, er
 }
 
 func (c *Client) ListScheduledSQL(project, name, displayName string, offset, size int) (scheduledsqls []*ScheduledSQL, total, count int, error error) {
+	return c.ListScheduledSQLCtx(context.Background(), project, name, displayName, offset, size)
+}
+
+// ListScheduledSQLCtx is like ListScheduledSQL, but the request is bound to ctx.
+func (c *Client) ListScheduledSQLCtx(ctx context.Context, project, name, displayName string, offset, size int) (scheduledsqls []*ScheduledSQL, total, count int, error error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -199,7 +225,7 @@ func (c *Client) ListScheduledSQL(project, name, displayName string, offset, siz
 	v.Add("size", fmt.Sprintf("%d", size))
 
 	uri := "/jobs?" + v.Encode()
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -237,12 +263,17 @@ type ScheduledSQLJobInstance struct {
 }
 
 func (c *Client) GetScheduledSQLJobInstance(projectName, jobName, instanceId string, result bool) (*ScheduledSQLJobInstance, error) {
+	return c.GetScheduledSQLJobInstanceCtx(context.Background(), projectName, jobName, instanceId, result)
+}
+
+// GetScheduledSQLJobInstanceCtx is like GetScheduledSQLJobInstance, but the request is bound to ctx.
+func (c *Client) GetScheduledSQLJobInstanceCtx(ctx context.Context, projectName, jobName, instanceId string, result bool) (*ScheduledSQLJobInstance, error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
 	}
 	uri := fmt.Sprintf("/jobs/%s/jobinstances/%s?result=%t", jobName, instanceId, result)
-	r, err := c.request(projectName, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, projectName, "GET", uri, h, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -259,6 +290,11 @@ func (c *Client) GetScheduledSQLJobInstance(projectName, jobName, instanceId str
 }
 
 func (c *Client) ModifyScheduledSQLJobInstanceState(projectName, jobName, instanceId string, state ScheduledSQLState) error {
+	return c.ModifyScheduledSQLJobInstanceStateCtx(context.Background(), projectName, jobName, instanceId, state)
+}
+
+// ModifyScheduledSQLJobInstanceStateCtx is like ModifyScheduledSQLJobInstanceState, but the request is bound to ctx.
+func (c *Client) ModifyScheduledSQLJobInstanceStateCtx(ctx context.Context, projectName, jobName, instanceId string, state ScheduledSQLState) error {
 	if ScheduledSQL_RUNNING != state {
 		return NewClientError(errors.New(fmt.Sprintf("Invalid state: %s, state must be RUNNING.", state)))
 	}
@@ -268,7 +304,7 @@ func (c *Client) ModifyScheduledSQLJobInstanceState(projectName, jobName, instan
 	}
 
 	uri := fmt.Sprintf("/jobs/%s/jobinstances/%s?state=%s", jobName, instanceId, state)
-	r, err := c.request(projectName, "PUT", uri, h, nil)
+	r, err := c.requestWithContext(ctx, projectName, "PUT", uri, h, nil)
 	if err != nil {
 		return err
 	}
@@ -285,6 +321,11 @@ type InstanceStatus struct {
 }
 
 func (c *Client) ListScheduledSQLJobInstances(projectName, jobName string, status *InstanceStatus) (instances []*ScheduledSQLJobInstance, total, count int64, err error) {
+	return c.ListScheduledSQLJobInstancesCtx(context.Background(), projectName, jobName, status)
+}
+
+// ListScheduledSQLJobInstancesCtx is like ListScheduledSQLJobInstances, but the request is bound to ctx.
+func (c *Client) ListScheduledSQLJobInstancesCtx(ctx context.Context, projectName, jobName string, status *InstanceStatus) (instances []*ScheduledSQLJobInstance, total, count int64, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 		"Content-Type":      "application/json",
@@ -300,7 +341,7 @@ func (c *Client) ListScheduledSQLJobInstances(projectName, jobName string, statu
 	}
 
 	uri := fmt.Sprintf("/jobs/%s/jobinstances?%s", jobName, v.Encode())
-	r, err := c.request(projectName, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, projectName, "GET", uri, h, nil)
 	if err != nil {
 		return nil, 0, 0, err
 	}
@@ -1,20 +1,19 @@
 package sls
 
 import (
+	"context"
 	base64E "encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strconv"
 	"time"
-
-	"github.com/go-kit/kit/log/level"
 )
 
 func convertLogstore(c *Client, project, logstore string) *LogStore {
+	c.initHttpClient()
 	c.accessKeyLock.RLock()
 	proj := convertLocked(c, project)
 	c.accessKeyLock.RUnlock()
@@ -24,23 +23,46 @@ func convertLogstore(c *Client, project, logstore string) *LogStore {
 	}
 }
 
+// convertLogstoreWithContext is like convertLogstore, but all requests sent by
+// the returned logstore are bound to ctx.
+func convertLogstoreWithContext(ctx context.Context, c *Client, project, logstore string) *LogStore {
+	ls := convertLogstore(c, project, logstore)
+	ls.project.ctx = ctx
+	return ls
+}
+
 // ListShards returns shard id list of this logstore.
 func (c *Client) ListShards(project, logstore string) (shardIDs []*Shard, err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.ListShardsCtx(context.Background(), project, logstore)
+}
+
+// ListShardsCtx is like ListShards, but the request is bound to ctx.
+func (c *Client) ListShardsCtx(ctx context.Context, project, logstore string) (shardIDs []*Shard, err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.ListShards()
 }
 
 // SplitShard https://help.aliyun.com/document_detail/29021.html
 func (c *Client) SplitShard(project, logstore string, shardID int, splitKey string) (shards []*Shard, err error) {
-	return c.splitShard(project, logstore, shardID, 0, splitKey)
+	return c.SplitShardCtx(context.Background(), project, logstore, shardID, splitKey)
+}
+
+// SplitShardCtx is like SplitShard, but the request is bound to ctx.
+func (c *Client) SplitShardCtx(ctx context.Context, project, logstore string, shardID int, splitKey string) (shards []*Shard, err error) {
+	return c.splitShard(ctx, project, logstore, shardID, 0, splitKey)
 }
 
 // SplitNumShard https://help.aliyun.com/document_detail/29021.html
 func (c *Client) SplitNumShard(project, logstore string, shardID, shardsNum int) (shards []*Shard, err error) {
-	return c.splitShard(project, logstore, shardID, shardsNum, "")
+	return c.SplitNumShardCtx(context.Background(), project, logstore, shardID, shardsNum)
+}
+
+// SplitNumShardCtx is like SplitNumShard, but the request is bound to ctx.
+func (c *Client) SplitNumShardCtx(ctx context.Context, project, logstore string, shardID, shardsNum int) (shards []*Shard, err error) {
+	return c.splitShard(ctx, project, logstore, shardID, shardsNum, "")
 }
 
-func (c *Client) splitShard(project, logstore string, shardID, shardsNum int, splitKey string) (shards []*Shard, err error) {
+func (c *Client) splitShard(ctx context.Context, project, logstore string, shardID, shardsNum int, splitKey string) (shards []*Shard, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
@@ -54,7 +76,7 @@ func (c *Client) splitShard(project, logstore string, shardID, shardsNum int, sp
 		urlVal.Add("shardCount", strconv.Itoa(shardsNum))
 	}
 	uri := fmt.Sprintf("/logstores/%v/shards/%v?%v", logstore, shardID, urlVal.Encode())
-	r, err := c.request(project, "POST", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, nil)
 	if err != nil {
 		return
 	}
@@ -69,6 +91,11 @@ func (c *Client) splitShard(project, logstore string, shardID, shardsNum int, sp
 
 // MergeShards https://help.aliyun.com/document_detail/29022.html
 func (c *Client) MergeShards(project, logstore string, shardID int) (shards []*Shard, err error) {
+	return c.MergeShardsCtx(context.Background(), project, logstore, shardID)
+}
+
+// MergeShardsCtx is like MergeShards, but the request is bound to ctx.
+func (c *Client) MergeShardsCtx(ctx context.Context, project, logstore string, shardID int) (shards []*Shard, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
@@ -76,7 +103,7 @@ func (c *Client) MergeShards(project, logstore string, shardID int) (shards []*S
 	urlVal := url.Values{}
 	urlVal.Add("action", "merge")
 	uri := fmt.Sprintf("/logstores/%v/shards/%v?%v", logstore, shardID, urlVal.Encode())
-	r, err := c.request(project, "POST", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "POST", uri, h, nil)
 	if err != nil {
 		return
 	}
@@ -92,14 +119,24 @@ func (c *Client) MergeShards(project, logstore string, shardID int) (shards []*S
 // PutLogs put logs into logstore.
 // The callers should transform user logs into LogGroup.
 func (c *Client) PutLogs(project, logstore string, lg *LogGroup) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PutLogsCtx(context.Background(), project, logstore, lg)
+}
+
+// PutLogsCtx is like PutLogs, but the request is bound to ctx.
+func (c *Client) PutLogsCtx(ctx context.Context, project, logstore string, lg *LogGroup) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.PutLogs(lg)
 }
 
 // PostLogStoreLogs put logs into Shard logstore by hashKey.
 // The callers should transform user logs into LogGroup.
 func (c *Client) PostLogStoreLogs(project, logstore string, lg *LogGroup, hashKey *string) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PostLogStoreLogsCtx(context.Background(), project, logstore, lg, hashKey)
+}
+
+// PostLogStoreLogsCtx is like PostLogStoreLogs, but the request is bound to ctx.
+func (c *Client) PostLogStoreLogsCtx(ctx context.Context, project, logstore string, lg *LogGroup, hashKey *string) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	req := &PostLogStoreLogsRequest{
 		LogGroup: lg,
 		HashKey:  hashKey,
@@ -108,19 +145,34 @@ func (c *Client) PostLogStoreLogs(project, logstore string, lg *LogGroup, hashKe
 }
 
 func (c *Client) PutLogsWithMetricStoreURL(project, logstore string, lg *LogGroup) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PutLogsWithMetricStoreURLCtx(context.Background(), project, logstore, lg)
+}
+
+// PutLogsWithMetricStoreURLCtx is like PutLogsWithMetricStoreURL, but the request is bound to ctx.
+func (c *Client) PutLogsWithMetricStoreURLCtx(ctx context.Context, project, logstore string, lg *LogGroup) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	ls.useMetricStoreURL = true
 	return ls.PutLogs(lg)
 }
 
 func (c *Client) PostLogStoreLogsV2(project, logstore string, req *PostLogStoreLogsRequest) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PostLogStoreLogsV2Ctx(context.Background(), project, logstore, req)
+}
+
+// PostLogStoreLogsV2Ctx is like PostLogStoreLogsV2, but the request is bound to ctx.
+func (c *Client) PostLogStoreLogsV2Ctx(ctx context.Context, project, logstore string, req *PostLogStoreLogsRequest) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.PostLogStoreLogs(req)
 }
 
 // PostRawLogWithCompressType put raw log data to log service, no marshal
 func (c *Client) PostRawLogWithCompressType(project, logstore string, rawLogData []byte, compressType int, hashKey *string) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PostRawLogWithCompressTypeCtx(context.Background(), project, logstore, rawLogData, compressType, hashKey)
+}
+
+// PostRawLogWithCompressTypeCtx is like PostRawLogWithCompressType, but the request is bound to ctx.
+func (c *Client) PostRawLogWithCompressTypeCtx(ctx context.Context, project, logstore string, rawLogData []byte, compressType int, hashKey *string) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	if err := ls.SetPutLogCompressType(compressType); err != nil {
 		return err
 	}
@@ -130,7 +182,12 @@ func (c *Client) PostRawLogWithCompressType(project, logstore string, rawLogData
 // PutLogsWithCompressType put logs into logstore with specific compress type.
 // The callers should transform user logs into LogGroup.
 func (c *Client) PutLogsWithCompressType(project, logstore string, lg *LogGroup, compressType int) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PutLogsWithCompressTypeCtx(context.Background(), project, logstore, lg, compressType)
+}
+
+// PutLogsWithCompressTypeCtx is like PutLogsWithCompressType, but the request is bound to ctx.
+func (c *Client) PutLogsWithCompressTypeCtx(ctx context.Context, project, logstore string, lg *LogGroup, compressType int) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	if err := ls.SetPutLogCompressType(compressType); err != nil {
 		return err
 	}
@@ -139,7 +196,12 @@ func (c *Client) PutLogsWithCompressType(project, logstore string, lg *LogGroup,
 
 // PutRawLogWithCompressType put raw log data to log service, no marshal
 func (c *Client) PutRawLogWithCompressType(project, logstore string, rawLogData []byte, compressType int) (err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PutRawLogWithCompressTypeCtx(context.Background(), project, logstore, rawLogData, compressType)
+}
+
+// PutRawLogWithCompressTypeCtx is like PutRawLogWithCompressType, but the request is bound to ctx.
+func (c *Client) PutRawLogWithCompressTypeCtx(ctx context.Context, project, logstore string, rawLogData []byte, compressType int) (err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	if err := ls.SetPutLogCompressType(compressType); err != nil {
 		return err
 	}
@@ -150,12 +212,22 @@ func (c *Client) PutRawLogWithCompressType(project, logstore string, rawLogData
 // The from can be in three form: a) unix timestamp in seccond, b) "begin", c) "end".
 // For more detail please read: https://help.aliyun.com/document_detail/29024.html
 func (c *Client) GetCursor(project, logstore string, shardID int, from string) (cursor string, err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetCursorCtx(context.Background(), project, logstore, shardID, from)
+}
+
+// GetCursorCtx is like GetCursor, but the request is bound to ctx.
+func (c *Client) GetCursorCtx(ctx context.Context, project, logstore string, shardID int, from string) (cursor string, err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetCursor(shardID, from)
 }
 
 // GetCursorTime ...
 func (c *Client) GetCursorTime(project, logstore string, shardID int, cursor string) (cursorTime time.Time, err error) {
+	return c.GetCursorTimeCtx(context.Background(), project, logstore, shardID, cursor)
+}
+
+// GetCursorTimeCtx is like GetCursorTime, but the request is bound to ctx.
+func (c *Client) GetCursorTimeCtx(ctx context.Context, project, logstore string, shardID int, cursor string) (cursorTime time.Time, err error) {
 	h := map[string]string{
 		"x-log-bodyrawsize": "0",
 	}
@@ -164,7 +236,7 @@ func (c *Client) GetCursorTime(project, logstore string, shardID int, cursor str
 	urlVal.Add("cursor", cursor)
 	urlVal.Add("type", "cursor_time")
 	uri := fmt.Sprintf("/logstores/%v/shards/%v?%v", logstore, shardID, urlVal.Encode())
-	r, err := c.request(project, "GET", uri, h, nil)
+	r, err := c.requestWithContext(ctx, project, "GET", uri, h, nil)
 	if err != nil {
 		return
 	}
@@ -200,6 +272,12 @@ func (c *Client) GetPrevCursorTime(project, logstore string, shardID int, cursor
 // The logGroupMaxCount is the max number of logGroup could be returned.
 // The nextCursor is the next curosr can be used to read logs at next time.
 func (c *Client) GetLogsBytes(project, logstore string, shardID int, cursor, endCursor string,
+	logGroupMaxCount int) (out []byte, nextCursor string, err error) {
+	return c.GetLogsBytesCtx(context.Background(), project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+}
+
+// GetLogsBytesCtx is like GetLogsBytes, but the request is bound to ctx.
+func (c *Client) GetLogsBytesCtx(ctx context.Context, project, logstore string, shardID int, cursor, endCursor string,
 	logGroupMaxCount int) (out []byte, nextCursor string, err error) {
 	plr := &PullLogRequest{
 		Project:          project,
@@ -209,16 +287,26 @@ func (c *Client) GetLogsBytes(project, logstore string, shardID int, cursor, end
 		EndCursor:        endCursor,
 		LogGroupMaxCount: logGroupMaxCount,
 	}
-	return c.GetLogsBytesV2(plr)
+	return c.GetLogsBytesV2Ctx(ctx, plr)
 }
 
 func (c *Client) GetLogsBytesV2(plr *PullLogRequest) (out []byte, nextCursor string, err error) {
-	ls := convertLogstore(c, plr.Project, plr.Logstore)
+	return c.GetLogsBytesV2Ctx(context.Background(), plr)
+}
+
+// GetLogsBytesV2Ctx is like GetLogsBytesV2, but the request is bound to ctx.
+func (c *Client) GetLogsBytesV2Ctx(ctx context.Context, plr *PullLogRequest) (out []byte, nextCursor string, err error) {
+	ls := convertLogstoreWithContext(ctx, c, plr.Project, plr.Logstore)
 	return ls.GetLogsBytesV2(plr)
 }
 
 func (c *Client) GetLogsBytesWithQuery(plr *PullLogRequest) (out []byte, plm *PullLogMeta, err error) {
-	ls := convertLogstore(c, plr.Project, plr.Logstore)
+	return c.GetLogsBytesWithQueryCtx(context.Background(), plr)
+}
+
+// GetLogsBytesWithQueryCtx is like GetLogsBytesWithQuery, but the request is bound to ctx.
+func (c *Client) GetLogsBytesWithQueryCtx(ctx context.Context, plr *PullLogRequest) (out []byte, plm *PullLogMeta, err error) {
+	ls := convertLogstoreWithContext(ctx, c, plr.Project, plr.Logstore)
 	return ls.GetLogsBytesWithQuery(plr)
 }
 
@@ -228,144 +316,270 @@ func (c *Client) GetLogsBytesWithQuery(plr *PullLogRequest) (out []byte, plm *Pu
 // @note if you want to pull logs continuous, set endCursor = ""
 func (c *Client) PullLogs(project, logstore string, shardID int, cursor, endCursor string,
 	logGroupMaxCount int) (gl *LogGroupList, nextCursor string, err error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.PullLogsCtx(context.Background(), project, logstore, shardID, cursor, endCursor, logGroupMaxCount)
+}
+
+// PullLogsCtx is like PullLogs, but the request is bound to ctx.
+func (c *Client) PullLogsCtx(ctx context.Context, project, logstore string, shardID int, cursor, endCursor string,
+	logGroupMaxCount int) (gl *LogGroupList, nextCursor string, err error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.PullLogs(shardID, cursor, endCursor, logGroupMaxCount)
 }
 
 func (c *Client) PullLogsV2(plr *PullLogRequest) (gl *LogGroupList, nextCursor string, err error) {
-	ls := convertLogstore(c, plr.Project, plr.Logstore)
+	return c.PullLogsV2Ctx(context.Background(), plr)
+}
+
+// PullLogsV2Ctx is like PullLogsV2, but the request is bound to ctx.
+func (c *Client) PullLogsV2Ctx(ctx context.Context, plr *PullLogRequest) (gl *LogGroupList, nextCursor string, err error) {
+	ls := convertLogstoreWithContext(ctx, c, plr.Project, plr.Logstore)
 	return ls.PullLogsV2(plr)
 }
 
 func (c *Client) PullLogsWithQuery(plr *PullLogRequest) (gl *LogGroupList, plm *PullLogMeta, err error) {
-	ls := convertLogstore(c, plr.Project, plr.Logstore)
+	return c.PullLogsWithQueryCtx(context.Background(), plr)
+}
+
+// PullLogsWithQueryCtx is like PullLogsWithQuery, but the request is bound to ctx.
+func (c *Client) PullLogsWithQueryCtx(ctx context.Context, plr *PullLogRequest) (gl *LogGroupList, plm *PullLogMeta, err error) {
+	ls := convertLogstoreWithContext(ctx, c, plr.Project, plr.Logstore)
 	return ls.PullLogsWithQuery(plr)
 }
 
 // GetHistograms query logs with [from, to) time range
 func (c *Client) GetHistograms(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetHistogramsCtx(context.Background(), project, logstore, topic, from, to, queryExp)
+}
+
+// GetHistogramsCtx is like GetHistograms, but the request is bound to ctx.
+func (c *Client) GetHistogramsCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetHistograms(topic, from, to, queryExp)
 }
 
 func (c *Client) GetHistogramsV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetHistogramsV2Ctx(context.Background(), project, logstore, ghr)
+}
+
+// GetHistogramsV2Ctx is like GetHistogramsV2, but the request is bound to ctx.
+func (c *Client) GetHistogramsV2Ctx(ctx context.Context, project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetHistogramsV2(ghr)
 }
 
 // GetHistogramsToCompleted query logs with [from, to) time range to completed
 func (c *Client) GetHistogramsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetHistogramsToCompletedCtx(context.Background(), project, logstore, topic, from, to, queryExp)
+}
+
+// GetHistogramsToCompletedCtx is like GetHistogramsToCompleted, but the request is bound to ctx.
+func (c *Client) GetHistogramsToCompletedCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetHistogramsToCompleted(topic, from, to, queryExp)
 }
 
 func (c *Client) GetHistogramsToCompletedV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetHistogramsToCompletedV2Ctx(context.Background(), project, logstore, ghr)
+}
+
+// GetHistogramsToCompletedV2Ctx is like GetHistogramsToCompletedV2, but the request is bound to ctx.
+func (c *Client) GetHistogramsToCompletedV2Ctx(ctx context.Context, project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetHistogramsToCompletedV2(ghr)
 }
 
 // GetLogs query logs with [from, to) time range
 func (c *Client) GetLogs(project, logstore string, topic string, from int64, to int64, queryExp string,
 	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsCtx(context.Background(), project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+}
+
+// GetLogsCtx is like GetLogs, but the request is bound to ctx.
+func (c *Client) GetLogsCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogs(topic, from, to, queryExp, maxLineNum, offset, reverse)
 }
 
 func (c *Client) GetLogsByNano(project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
 	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsByNanoCtx(context.Background(), project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+}
+
+// GetLogsByNanoCtx is like GetLogsByNano, but the request is bound to ctx.
+func (c *Client) GetLogsByNanoCtx(ctx context.Context, project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsByNano(topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
 }
 
 // GetLogsToCompleted query logs with [from, to) time range to completed
 func (c *Client) GetLogsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string,
 	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsToCompletedCtx(context.Background(), project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+}
+
+// GetLogsToCompletedCtx is like GetLogsToCompleted, but the request is bound to ctx.
+func (c *Client) GetLogsToCompletedCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsToCompleted(topic, from, to, queryExp, maxLineNum, offset, reverse)
 }
 
 // GetLogLines ...
 func (c *Client) GetLogLines(project, logstore string, topic string, from int64, to int64, queryExp string,
 	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogLinesCtx(context.Background(), project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+}
+
+// GetLogLinesCtx is like GetLogLines, but the request is bound to ctx.
+func (c *Client) GetLogLinesCtx(ctx context.Context, project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogLines(topic, from, to, queryExp, maxLineNum, offset, reverse)
 }
 
 func (c *Client) GetLogLinesByNano(project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
 	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogLinesByNanoCtx(context.Background(), project, logstore, topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
+}
+
+// GetLogLinesByNanoCtx is like GetLogLinesByNano, but the request is bound to ctx.
+func (c *Client) GetLogLinesByNanoCtx(ctx context.Context, project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogLinesByNano(topic, fromInNs, toInNs, queryExp, maxLineNum, offset, reverse)
 }
 
 // GetLogsV2 ...
 func (c *Client) GetLogsV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsV2Ctx(context.Background(), project, logstore, req)
+}
+
+// GetLogsV2Ctx is like GetLogsV2, but the request is bound to ctx.
+func (c *Client) GetLogsV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsV2(req)
 }
 
 // GetLogsV3 ...
 func (c *Client) GetLogsV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsV3Ctx(context.Background(), project, logstore, req)
+}
+
+// GetLogsV3Ctx is like GetLogsV3, but the request is bound to ctx.
+func (c *Client) GetLogsV3Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsV3(req)
 }
 
 // GetLogsToCompletedV2 ...
 func (c *Client) GetLogsToCompletedV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsToCompletedV2Ctx(context.Background(), project, logstore, req)
+}
+
+// GetLogsToCompletedV2Ctx is like GetLogsToCompletedV2, but the request is bound to ctx.
+func (c *Client) GetLogsToCompletedV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsToCompletedV2(req)
 }
 
 // GetLogsToCompletedV3 ...
 func (c *Client) GetLogsToCompletedV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogsToCompletedV3Ctx(context.Background(), project, logstore, req)
+}
+
+// GetLogsToCompletedV3Ctx is like GetLogsToCompletedV3, but the request is bound to ctx.
+func (c *Client) GetLogsToCompletedV3Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogsToCompletedV3(req)
 }
 
 // GetLogLinesV2 ...
 func (c *Client) GetLogLinesV2(project, logstore string, req *GetLogRequest) (*GetLogLinesResponse, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetLogLinesV2Ctx(context.Background(), project, logstore, req)
+}
+
+// GetLogLinesV2Ctx is like GetLogLinesV2, but the request is bound to ctx.
+func (c *Client) GetLogLinesV2Ctx(ctx context.Context, project, logstore string, req *GetLogRequest) (*GetLogLinesResponse, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetLogLinesV2(req)
 }
 
 // CreateIndex ...
 func (c *Client) CreateIndex(project, logstore string, index Index) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.CreateIndexCtx(context.Background(), project, logstore, index)
+}
+
+// CreateIndexCtx is like CreateIndex, but the request is bound to ctx.
+func (c *Client) CreateIndexCtx(ctx context.Context, project, logstore string, index Index) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.CreateIndex(index)
 }
 
 // UpdateIndex ...
 func (c *Client) UpdateIndex(project, logstore string, index Index) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.UpdateIndexCtx(context.Background(), project, logstore, index)
+}
+
+// UpdateIndexCtx is like UpdateIndex, but the request is bound to ctx.
+func (c *Client) UpdateIndexCtx(ctx context.Context, project, logstore string, index Index) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.UpdateIndex(index)
 }
 
 // GetIndex ...
 func (c *Client) GetIndex(project, logstore string) (*Index, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetIndexCtx(context.Background(), project, logstore)
+}
+
+// GetIndexCtx is like GetIndex, but the request is bound to ctx.
+func (c *Client) GetIndexCtx(ctx context.Context, project, logstore string) (*Index, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetIndex()
 }
 
 // CreateIndexString ...
 func (c *Client) CreateIndexString(project, logstore string, index string) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.CreateIndexStringCtx(context.Background(), project, logstore, index)
+}
+
+// CreateIndexStringCtx is like CreateIndexString, but the request is bound to ctx.
+func (c *Client) CreateIndexStringCtx(ctx context.Context, project, logstore string, index string) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.CreateIndexString(index)
 }
 
 // UpdateIndexString ...
 func (c *Client) UpdateIndexString(project, logstore string, index string) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.UpdateIndexStringCtx(context.Background(), project, logstore, index)
+}
+
+// UpdateIndexStringCtx is like UpdateIndexString, but the request is bound to ctx.
+func (c *Client) UpdateIndexStringCtx(ctx context.Context, project, logstore string, index string) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.UpdateIndexString(index)
 }
 
 // GetIndexString ...
 func (c *Client) GetIndexString(project, logstore string) (string, error) {
-	ls := convertLogstore(c, project, logstore)
+	return c.GetIndexStringCtx(context.Background(), project, logstore)
+}
+
+// GetIndexStringCtx is like GetIndexString, but the request is bound to ctx.
+func (c *Client) GetIndexStringCtx(ctx context.Context, project, logstore string) (string, error) {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.GetIndexString()
 }
 
 // DeleteIndex ...
 func (c *Client) DeleteIndex(project, logstore string) error {
-	ls := convertLogstore(c, project, logstore)
+	return c.DeleteIndexCtx(context.Background(), project, logstore)
+}
+
+// DeleteIndexCtx is like DeleteIndex, but the request is bound to ctx.
+func (c *Client) DeleteIndexCtx(ctx context.Context, project, logstore string) error {
+	ls := convertLogstoreWithContext(ctx, c, project, logstore)
 	return ls.DeleteIndex()
 }
 
@@ -387,17 +601,7 @@ func (c *Client) ListSubStore(project, logstore string) (sortedSubStores []strin
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to remove config from machine group")
-			if IsDebugLevelMatched(1) {
-				dump, _ := httputil.DumpResponse(r, true)
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 
@@ -433,17 +637,7 @@ func (c *Client) GetSubStore(project, logstore, name string) (sortedSubStore *Su
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to remove config from machine group")
-			if IsDebugLevelMatched(1) {
-				dump, _ := httputil.DumpResponse(r, true)
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 	sortedSubStore = &SubStore{}
@@ -548,17 +742,7 @@ func (c *Client) GetSubStoreTTL(project, logstore string) (ttl int, err error) {
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to remove config from machine group")
-			if IsDebugLevelMatched(1) {
-				dump, _ := httputil.DumpResponse(r, true)
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 
@@ -1,6 +1,7 @@
 package consumerLibrary
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -91,8 +92,8 @@ func (tracker *DefaultCheckPointTracker) flushCheckPoint() error {
 		if err == nil {
 			break
 		}
-		slsErr, ok := err.(*sls.Error)
-		if ok {
+		var slsErr *sls.Error
+		if errors.As(err, &slsErr) {
 			if strings.EqualFold(slsErr.Code, "ConsumerNotExsit") || strings.EqualFold(slsErr.Code, "ConsumerNotMatch") {
 				tracker.heartBeat.removeHeartShard(tracker.shardId)
 				level.Warn(tracker.logger).Log("msg", "consumer has been removed or shard has been reassigned", "shard", tracker.shardId, "err", slsErr)
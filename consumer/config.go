@@ -1,10 +1,12 @@
 package consumerLibrary
 
 import (
+	"context"
 	"net/http"
 
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type LogHubConfig struct {
@@ -53,6 +55,10 @@ type LogHubConfig struct {
 	//:param Region: region of sls endpoint, eg. cn-hangzhou, region must be set if AuthVersion is sls.AuthV4
 	//:param DisableRuntimeMetrics: disable runtime metrics, runtime metrics prints to local log.
 	//::param MaxIoWorkers: max io workers, default is 50. Smaller io workers will reduce memory usage, but may reduce throughput.
+	//:param RateLimiter: default nil, optional. If set, pulling logs and other requests of the consumer are limited by it, and slowed down once the read quota is exceeded, ignored by clients without SetRateLimiter.
+	//:param TracerProvider: default nil, optional. If set, each fetch of a shard and the api calls of the client are traced by OpenTelemetry.
+	//:param TraceContext: default nil, optional. If set, the span of it is the parent of the spans of the fetches of shards, its deadline and cancellation are not used.
+	//:param Signer: default nil, optional. If set, it creates the signer of each request from the credentials, see sls.Client.SetSigner, ignored by clients without SetSigner.
 	Endpoint                  string
 	AccessKeyID               string
 	AccessKeySecret           string
@@ -85,6 +91,10 @@ type LogHubConfig struct {
 	Region                    string
 	DisableRuntimeMetrics     bool
 	MaxIoWorkers              int
+	RateLimiter               *sls.RateLimiter
+	TracerProvider            trace.TracerProvider
+	TraceContext              context.Context
+	Signer                    func(creds sls.Credentials) sls.Signer
 }
 
 const (
@@ -1,19 +1,25 @@
 package consumerLibrary
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const tracerName = "github.com/aliyun/aliyun-log-go-sdk/consumer"
+
 type ConsumerClient struct {
 	option        LogHubConfig
 	client        sls.ClientInterface
 	consumerGroup sls.ConsumerGroup
 	logger        log.Logger
+	tracer        trace.Tracer
 }
 
 func initConsumerClient(option LogHubConfig, logger log.Logger) *ConsumerClient {
@@ -53,6 +59,26 @@ func initConsumerClient(option LogHubConfig, logger log.Logger) *ConsumerClient
 	if option.Region != "" {
 		client.SetRegion(option.Region)
 	}
+	if option.RateLimiter != nil {
+		if c, ok := client.(interface{ SetRateLimiter(*sls.RateLimiter) }); ok {
+			c.SetRateLimiter(option.RateLimiter)
+		}
+	}
+	if option.Signer != nil {
+		if c, ok := client.(interface {
+			SetSigner(func(creds sls.Credentials) sls.Signer)
+		}); ok {
+			c.SetSigner(option.Signer)
+		}
+	}
+	tracerProvider := option.TracerProvider
+	if tracerProvider != nil {
+		if c, ok := client.(interface{ SetTracerProvider(trace.TracerProvider) }); ok {
+			c.SetTracerProvider(tracerProvider)
+		}
+	} else {
+		tracerProvider = trace.NewNoopTracerProvider()
+	}
 
 	consumerGroup := sls.ConsumerGroup{
 		ConsumerGroupName: option.ConsumerGroupName,
@@ -64,6 +90,7 @@ func initConsumerClient(option LogHubConfig, logger log.Logger) *ConsumerClient
 		client,
 		consumerGroup,
 		logger,
+		tracerProvider.Tracer(tracerName),
 	}
 
 	return consumerClient
@@ -93,7 +120,8 @@ func (consumer *ConsumerClient) createConsumerGroup() error {
 		}
 	} else {
 		if err := consumer.client.CreateConsumerGroup(consumer.option.Project, consumer.option.Logstore, consumer.consumerGroup); err != nil {
-			if slsError, ok := err.(*sls.Error); !ok || slsError.Code != "ConsumerGroupAlreadyExist" {
+			var slsError *sls.Error
+			if !errors.As(err, &slsError) || slsError.Code != "ConsumerGroupAlreadyExist" {
 				return fmt.Errorf("create consumer group failed: %w", err)
 			}
 		}
@@ -139,7 +167,7 @@ func (consumer *ConsumerClient) getCursor(shardId int, from string) (string, err
 	return cursor, err
 }
 
-func (consumer *ConsumerClient) pullLogs(shardId int, cursor string) (gl *sls.LogGroupList, plm *sls.PullLogMeta, err error) {
+func (consumer *ConsumerClient) pullLogs(ctx context.Context, shardId int, cursor string) (gl *sls.LogGroupList, plm *sls.PullLogMeta, err error) {
 	plr := &sls.PullLogRequest{
 		Project:          consumer.option.Project,
 		Logstore:         consumer.option.Logstore,
@@ -149,11 +177,16 @@ func (consumer *ConsumerClient) pullLogs(shardId int, cursor string) (gl *sls.Lo
 		LogGroupMaxCount: consumer.option.MaxFetchLogGroupCount,
 		CompressType:     consumer.option.CompressType,
 	}
+	ctxClient, withCtx := consumer.client.(sls.ClientInterfaceWithContext)
 	for retry := 0; retry < 3; retry++ {
-		gl, plm, err = consumer.client.PullLogsWithQuery(plr)
+		if withCtx {
+			gl, plm, err = ctxClient.PullLogsWithQueryCtx(ctx, plr)
+		} else {
+			gl, plm, err = consumer.client.PullLogsWithQuery(plr)
+		}
 		if err != nil {
-			slsError, ok := err.(*sls.Error)
-			if ok {
+			var slsError *sls.Error
+			if errors.As(err, &slsError) {
 				level.Warn(consumer.logger).Log("msg", "shard pull logs failed, occur sls error",
 					"shard", shardId,
 					"error", slsError,
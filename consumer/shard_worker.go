@@ -1,6 +1,7 @@
 package consumerLibrary
 
 import (
+	"context"
 	"fmt"
 	"runtime"
 	"sync"
@@ -11,6 +12,9 @@ import (
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // todo: refine the sleep time
@@ -100,9 +104,25 @@ func (c *ShardConsumerWorker) fetchLogs(cursor string) (shouldCallProcess bool,
 	c.ioThrottler.Acquire()
 	defer c.ioThrottler.Release()
 
+	parent := context.Background()
+	if c.client.option.TraceContext != nil {
+		parent = trace.ContextWithSpanContext(parent, trace.SpanContextFromContext(c.client.option.TraceContext))
+	}
+	ctx, span := c.client.tracer.Start(parent, "sls.consumer.fetchLogs", trace.WithAttributes(
+		attribute.String("sls.project", c.client.option.Project),
+		attribute.String("sls.logstore", c.client.option.Logstore),
+		attribute.Int("sls.shard", c.shardId),
+	))
 	start := time.Now()
-	logGroupList, plm, err := c.client.pullLogs(c.shardId, cursor)
+	logGroupList, plm, err := c.client.pullLogs(ctx, c.shardId, cursor)
 	c.monitor.RecordFetchRequest(plm, err, start)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	} else {
+		span.SetAttributes(attribute.Int("sls.consumer.log_group_count", len(logGroupList.LogGroups)))
+	}
+	span.End()
 
 	if err != nil {
 		time.Sleep(fetchFailedSleepTime)
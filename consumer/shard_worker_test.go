@@ -0,0 +1,54 @@
+package consumerLibrary
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestFetchLogsTracing(t *testing.T) {
+	client := sls.NewFakeClient()
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 0))
+	require.NoError(t, client.PutLogs("test-project", "test-logstore", &sls.LogGroup{
+		Logs: []*sls.Log{{Time: proto.Uint32(uint32(time.Now().Unix()))}},
+	}))
+	cursor, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx, parent := tp.Tracer("test").Start(ctx, "consumer")
+	// the cancellation of TraceContext does not stop the fetches
+	cancel()
+	consumerClient := &ConsumerClient{
+		option: LogHubConfig{
+			Project:               "test-project",
+			Logstore:              "test-logstore",
+			MaxFetchLogGroupCount: 1000,
+			TraceContext:          ctx,
+		},
+		client: client,
+		logger: log.NewNopLogger(),
+		tracer: tp.Tracer(tracerName),
+	}
+	worker := newShardConsumerWorker(0, consumerClient, nil, nil, log.NewNopLogger(), newSimpleIoThrottler(1))
+	ok, logGroupList, _ := worker.fetchLogs(cursor)
+	require.True(t, ok)
+	require.Len(t, logGroupList.LogGroups, 1)
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	require.Equal(t, "sls.consumer.fetchLogs", spans[0].Name())
+	require.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
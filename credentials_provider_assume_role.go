@@ -0,0 +1,93 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	sts "github.com/alibabacloud-go/sts-20150401/v2/client"
+)
+
+// AssumeRoleConfig is the config of the role assumed by NewAssumeRoleCredentialsProviderWithConfig.
+type AssumeRoleConfig struct {
+	RoleArn         string
+	RoleSessionName string // defaults to DEFAULT_ROLE_SESSION_NAME
+	Policy          string // optional, the policy to further restrict the permissions of the role
+	// The duration of the credentials, defaults to the max session duration of the role if zero,
+	// it is truncated to seconds.
+	Duration    time.Duration
+	ExternalID  string // optional, required by roles of other accounts which are configured with an external id
+	StsEndpoint string // defaults to DEFAULT_STS_ENDPOINT, prefixed with http:// to use http
+}
+
+// NewAssumeRoleCredentialsProvider returns a provider of the credentials of the ram role roleArn,
+// which are fetched from sts with the credentials of baseProvider, and refreshed before the expiration.
+//
+// Roles can be chained by using another assume role provider as baseProvider, eg. to assume a role
+// of another account with a role of the current account.
+//
+//	base := sls.NewAssumeRoleCredentialsProvider(ecsProvider, "acs:ram::1:role/log-shipper", "shipper", "", time.Hour)
+//	provider := sls.NewAssumeRoleCredentialsProvider(base, "acs:ram::2:role/log-writer", "shipper", "", time.Hour)
+func NewAssumeRoleCredentialsProvider(baseProvider CredentialsProvider, roleArn, sessionName, policy string,
+	duration time.Duration) *UpdateFuncProviderAdapter {
+	return NewAssumeRoleCredentialsProviderWithConfig(baseProvider, AssumeRoleConfig{
+		RoleArn:         roleArn,
+		RoleSessionName: sessionName,
+		Policy:          policy,
+		Duration:        duration,
+	})
+}
+
+// NewAssumeRoleCredentialsProviderWithConfig is NewAssumeRoleCredentialsProvider with the external id
+// and the sts endpoint configurable.
+func NewAssumeRoleCredentialsProviderWithConfig(baseProvider CredentialsProvider, config AssumeRoleConfig) *UpdateFuncProviderAdapter {
+	return &UpdateFuncProviderAdapter{
+		fetcher:    fetcherWithRetry(newAssumeRoleFetcher(baseProvider, config), UPDATE_FUNC_RETRY_TIMES),
+		fetchAhead: defaultFetchAhead,
+	}
+}
+
+func newAssumeRoleFetcher(baseProvider CredentialsProvider, config AssumeRoleConfig) CredentialsFetcher {
+	if config.RoleSessionName == "" {
+		config.RoleSessionName = DEFAULT_ROLE_SESSION_NAME
+	}
+	if config.StsEndpoint == "" {
+		config.StsEndpoint = DEFAULT_STS_ENDPOINT
+	}
+	return func() (*tempCredentials, error) {
+		if baseProvider == nil {
+			return nil, errors.New("base credentials provider is nil")
+		}
+		baseCred, err := baseProvider.GetCredentials()
+		if err != nil {
+			return nil, fmt.Errorf("fail to get base credentials: %w", err)
+		}
+		client, err := newSTSClient(config.StsEndpoint, &baseCred)
+		if err != nil {
+			return nil, fmt.Errorf("fail to create sts client: %w", err)
+		}
+		req := &sts.AssumeRoleRequest{
+			RoleArn:         &config.RoleArn,
+			RoleSessionName: &config.RoleSessionName,
+		}
+		if config.Policy != "" {
+			req.Policy = &config.Policy
+		}
+		if config.ExternalID != "" {
+			req.ExternalId = &config.ExternalID
+		}
+		if config.Duration > 0 {
+			seconds := int64(config.Duration / time.Second)
+			req.DurationSeconds = &seconds
+		}
+		resp, err := client.AssumeRole(req)
+		if err != nil {
+			return nil, fmt.Errorf("fail to assume role %s: %w", config.RoleArn, err)
+		}
+		if resp.Body == nil || resp.Body.Credentials == nil {
+			return nil, errors.New("sts response has no credentials")
+		}
+		c := resp.Body.Credentials
+		return stsTempCredentials(c.AccessKeyId, c.AccessKeySecret, c.SecurityToken, c.Expiration)
+	}
+}
@@ -0,0 +1,105 @@
+package sls
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSTSServer responds to AssumeRole and AssumeRoleWithOIDC with credentials whose access key id
+// is the role arn suffixed with the count of requests.
+type fakeSTSServer struct {
+	*httptest.Server
+	expiration time.Duration
+
+	mu       sync.Mutex
+	requests []map[string]string
+}
+
+func newFakeSTSServer(t *testing.T, expiration time.Duration) *fakeSTSServer {
+	s := &fakeSTSServer{expiration: expiration}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		params := map[string]string{}
+		for k := range r.Form {
+			params[k] = r.Form.Get(k)
+		}
+		s.mu.Lock()
+		s.requests = append(s.requests, params)
+		count := len(s.requests)
+		s.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if params["RoleArn"] == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"Code": "MissingRoleArn", "Message": "RoleArn is mandatory"})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"RequestId": "request-id",
+			"Credentials": map[string]string{
+				"AccessKeyId":     params["RoleArn"] + "-" + string(rune('0'+count)),
+				"AccessKeySecret": "secret",
+				"SecurityToken":   "token",
+				"Expiration":      time.Now().Add(s.expiration).UTC().Format(time.RFC3339),
+			},
+		})
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *fakeSTSServer) Requests() []map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]map[string]string{}, s.requests...)
+}
+
+func TestAssumeRoleCredentialsProvider(t *testing.T) {
+	server := newFakeSTSServer(t, time.Hour)
+	base := NewStaticCredentialsProvider("base-id", "base-secret", "")
+	provider := NewAssumeRoleCredentialsProviderWithConfig(base, AssumeRoleConfig{
+		RoleArn:     "role-a",
+		Duration:    time.Hour,
+		ExternalID:  "external-id",
+		StsEndpoint: server.URL,
+	})
+	for i := 0; i < 3; i++ {
+		cred, err := provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, Credentials{AccessKeyID: "role-a-1", AccessKeySecret: "secret", SecurityToken: "token"}, cred)
+	}
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	require.Equal(t, "AssumeRole", requests[0]["Action"])
+	require.Equal(t, "base-id", requests[0]["AccessKeyId"])
+	require.Equal(t, "external-id", requests[0]["ExternalId"])
+	require.Equal(t, "3600", requests[0]["DurationSeconds"])
+	require.Equal(t, DEFAULT_ROLE_SESSION_NAME, requests[0]["RoleSessionName"])
+
+	// credentials expiring within the fetch ahead duration are refreshed
+	server.expiration = time.Minute
+	chained := NewAssumeRoleCredentialsProviderWithConfig(provider, AssumeRoleConfig{
+		RoleArn:         "role-b",
+		RoleSessionName: "chained",
+		StsEndpoint:     server.URL,
+	})
+	cred, err := chained.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "role-b-2", cred.AccessKeyID)
+	cred, err = chained.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "role-b-3", cred.AccessKeyID)
+	requests = server.Requests()
+	require.Equal(t, "role-a-1", requests[1]["AccessKeyId"])
+	require.Equal(t, "token", requests[1]["SecurityToken"])
+	require.Equal(t, "chained", requests[1]["RoleSessionName"])
+
+	_, err = NewAssumeRoleCredentialsProviderWithConfig(base, AssumeRoleConfig{StsEndpoint: server.URL}).GetCredentials()
+	require.Error(t, err)
+}
@@ -0,0 +1,407 @@
+package sls
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	openapi "github.com/alibabacloud-go/darabonba-openapi/v2/client"
+	sts "github.com/alibabacloud-go/sts-20150401/v2/client"
+	"github.com/go-kit/kit/log/level"
+)
+
+// Environment variables read by the default credentials provider chain.
+const (
+	ENV_ACCESS_KEY_ID         = "ALIBABA_CLOUD_ACCESS_KEY_ID"
+	ENV_ACCESS_KEY_SECRET     = "ALIBABA_CLOUD_ACCESS_KEY_SECRET"
+	ENV_SECURITY_TOKEN        = "ALIBABA_CLOUD_SECURITY_TOKEN"
+	ENV_PROFILE               = "ALIBABA_CLOUD_PROFILE"
+	ENV_CONFIG_FILE           = "ALIBABA_CLOUD_CONFIG_FILE"
+	ENV_CREDENTIALS_PROCESS   = "ALIBABA_CLOUD_CREDENTIALS_PROCESS"
+	ENV_ROLE_ARN              = "ALIBABA_CLOUD_ROLE_ARN"
+	ENV_OIDC_PROVIDER_ARN     = "ALIBABA_CLOUD_OIDC_PROVIDER_ARN"
+	ENV_OIDC_TOKEN_FILE       = "ALIBABA_CLOUD_OIDC_TOKEN_FILE"
+	ENV_ROLE_SESSION_NAME     = "ALIBABA_CLOUD_ROLE_SESSION_NAME"
+	ENV_STS_REGION            = "ALIBABA_CLOUD_STS_REGION"
+	ENV_ECS_METADATA          = "ALIBABA_CLOUD_ECS_METADATA"
+	ENV_ECS_METADATA_DISABLED = "ALIBABA_CLOUD_ECS_METADATA_DISABLED"
+)
+
+const (
+	DEFAULT_STS_ENDPOINT        = "sts.aliyuncs.com"
+	DEFAULT_ROLE_SESSION_NAME   = "aliyun-log-go-sdk"
+	PROCESS_CREDENTIALS_TIMEOUT = time.Minute
+	ECS_METADATA_TIMEOUT        = time.Second
+)
+
+// NewDefaultCredentialsProvider returns a chain of credentials providers which tries in order:
+//
+//  1. the environment variables ALIBABA_CLOUD_ACCESS_KEY_ID, ALIBABA_CLOUD_ACCESS_KEY_SECRET
+//     and ALIBABA_CLOUD_SECURITY_TOKEN, see NewEnvCredentialsProvider.
+//  2. the profile of the aliyun cli config file ~/.aliyun/config.json, see NewProfileCredentialsProvider.
+//  3. the command of the environment variable ALIBABA_CLOUD_CREDENTIALS_PROCESS, see NewProcessCredentialsProvider.
+//  4. the OIDC token file of the RRSA on ACK, see NewOIDCCredentialsProviderFromEnv.
+//  5. the ram role of the ecs instance, named by the environment variable ALIBABA_CLOUD_ECS_METADATA or
+//     fetched from the ecs metadata service, unless ALIBABA_CLOUD_ECS_METADATA_DISABLED is true.
+//
+// The first provider succeeding is used from then on.
+func NewDefaultCredentialsProvider() *CredentialsProviderChain {
+	return NewCredentialsProviderChain(
+		NewEnvCredentialsProvider(),
+		NewProfileCredentialsProvider("", ""),
+		newLazyCredentialsProvider(func() (CredentialsProvider, error) {
+			command := os.Getenv(ENV_CREDENTIALS_PROCESS)
+			if command == "" {
+				return nil, fmt.Errorf("env %s is not set", ENV_CREDENTIALS_PROCESS)
+			}
+			return NewProcessCredentialsProvider(command), nil
+		}),
+		newLazyCredentialsProvider(func() (CredentialsProvider, error) {
+			return NewOIDCCredentialsProviderFromEnv()
+		}),
+		newLazyCredentialsProvider(newEcsRamRoleCredentialsProviderFromEnv),
+	)
+}
+
+// CredentialsProviderChain tries its providers in order until one of them succeeds,
+// which is used to get credentials from then on.
+type CredentialsProviderChain struct {
+	providers []CredentialsProvider
+
+	mu      sync.Mutex
+	current CredentialsProvider
+}
+
+// NewCredentialsProviderChain returns a CredentialsProviderChain of providers.
+func NewCredentialsProviderChain(providers ...CredentialsProvider) *CredentialsProviderChain {
+	return &CredentialsProviderChain{
+		providers: providers,
+	}
+}
+
+func (c *CredentialsProviderChain) GetCredentials() (Credentials, error) {
+	c.mu.Lock()
+	current := c.current
+	c.mu.Unlock()
+	if current != nil {
+		return current.GetCredentials()
+	}
+
+	var errs []error
+	for _, provider := range c.providers {
+		cred, err := provider.GetCredentials()
+		if err == nil {
+			c.mu.Lock()
+			c.current = provider
+			c.mu.Unlock()
+			return cred, nil
+		}
+		errs = append(errs, err)
+	}
+	return Credentials{}, fmt.Errorf("no credentials provider in chain succeeds: %w", joinErrors(errs...))
+}
+
+// lazyCredentialsProvider builds its provider on the first call of GetCredentials,
+// and retries on the next call if failed to build.
+type lazyCredentialsProvider struct {
+	build func() (CredentialsProvider, error)
+
+	mu       sync.Mutex
+	provider CredentialsProvider
+}
+
+func newLazyCredentialsProvider(build func() (CredentialsProvider, error)) *lazyCredentialsProvider {
+	return &lazyCredentialsProvider{build: build}
+}
+
+func (p *lazyCredentialsProvider) GetCredentials() (Credentials, error) {
+	p.mu.Lock()
+	if p.provider == nil {
+		provider, err := p.build()
+		if err != nil {
+			p.mu.Unlock()
+			return Credentials{}, err
+		}
+		p.provider = provider
+	}
+	provider := p.provider
+	p.mu.Unlock()
+	return provider.GetCredentials()
+}
+
+// EnvCredentialsProvider reads credentials from the environment variables ALIBABA_CLOUD_ACCESS_KEY_ID,
+// ALIBABA_CLOUD_ACCESS_KEY_SECRET and the optional ALIBABA_CLOUD_SECURITY_TOKEN every time.
+type EnvCredentialsProvider struct{}
+
+func NewEnvCredentialsProvider() *EnvCredentialsProvider {
+	return &EnvCredentialsProvider{}
+}
+
+func (p *EnvCredentialsProvider) GetCredentials() (Credentials, error) {
+	cred := Credentials{
+		AccessKeyID:     os.Getenv(ENV_ACCESS_KEY_ID),
+		AccessKeySecret: os.Getenv(ENV_ACCESS_KEY_SECRET),
+		SecurityToken:   os.Getenv(ENV_SECURITY_TOKEN),
+	}
+	if cred.AccessKeyID == "" || cred.AccessKeySecret == "" {
+		return Credentials{}, fmt.Errorf("env %s or %s is not set", ENV_ACCESS_KEY_ID, ENV_ACCESS_KEY_SECRET)
+	}
+	return cred, nil
+}
+
+// cliConfig is the config file of the aliyun cli.
+type cliConfig struct {
+	Current  string       `json:"current"`
+	Profiles []cliProfile `json:"profiles"`
+}
+
+type cliProfile struct {
+	Name            string `json:"name"`
+	Mode            string `json:"mode"`
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	StsToken        string `json:"sts_token"`
+	RamRoleName     string `json:"ram_role_name"`
+	RamRoleArn      string `json:"ram_role_arn"`
+	RoleSessionName string `json:"ram_session_name"`
+	ExpiredSeconds  int    `json:"expired_seconds"`
+	StsRegion       string `json:"sts_region"`
+	SourceProfile   string `json:"source_profile"`
+	ProcessCommand  string `json:"process_command"`
+	OIDCProviderArn string `json:"oidc_provider_arn"`
+	OIDCTokenFile   string `json:"oidc_token_file"`
+}
+
+// NewProfileCredentialsProvider returns a provider of credentials of a profile of the aliyun cli config file,
+// which is loaded on the first call of GetCredentials.
+//
+// Param path defaults to the environment variable ALIBABA_CLOUD_CONFIG_FILE, or ~/.aliyun/config.json.
+// Param profile defaults to the environment variable ALIBABA_CLOUD_PROFILE, or the current profile of the file.
+//
+// Profiles of mode AK, StsToken, RamRoleArn, ChainableRamRoleArn, EcsRamRole, External and OIDC are supported.
+func NewProfileCredentialsProvider(path, profile string) CredentialsProvider {
+	return newLazyCredentialsProvider(func() (CredentialsProvider, error) {
+		if path == "" {
+			path = os.Getenv(ENV_CONFIG_FILE)
+		}
+		if path == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("fail to get home dir: %w", err)
+			}
+			path = filepath.Join(home, ".aliyun", "config.json")
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("fail to read config file: %w", err)
+		}
+		var config cliConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("fail to unmarshal config file %s: %w", path, err)
+		}
+		name := profile
+		if name == "" {
+			name = os.Getenv(ENV_PROFILE)
+		}
+		if name == "" {
+			name = config.Current
+		}
+		if name == "" {
+			name = "default"
+		}
+		return config.provider(name, nil)
+	})
+}
+
+// provider returns the provider of profile name, visited are the profiles chained to it.
+func (c *cliConfig) provider(name string, visited []string) (CredentialsProvider, error) {
+	for _, v := range visited {
+		if v == name {
+			return nil, fmt.Errorf("profile %s is chained in a loop: %v", name, visited)
+		}
+	}
+	var p *cliProfile
+	for i := range c.Profiles {
+		if c.Profiles[i].Name == name {
+			p = &c.Profiles[i]
+		}
+	}
+	if p == nil {
+		return nil, fmt.Errorf("profile %s not found in config file", name)
+	}
+	switch p.Mode {
+	case "AK", "StsToken":
+		if p.AccessKeyID == "" || p.AccessKeySecret == "" {
+			return nil, fmt.Errorf("profile %s has no access key", name)
+		}
+		return NewStaticCredentialsProvider(p.AccessKeyID, p.AccessKeySecret, p.StsToken), nil
+	case "RamRoleArn":
+		if p.AccessKeyID == "" || p.AccessKeySecret == "" {
+			return nil, fmt.Errorf("profile %s has no access key", name)
+		}
+		base := NewStaticCredentialsProvider(p.AccessKeyID, p.AccessKeySecret, p.StsToken)
+		return NewAssumeRoleCredentialsProviderWithConfig(base, p.assumeRoleConfig()), nil
+	case "ChainableRamRoleArn":
+		base, err := c.provider(p.SourceProfile, append(visited, name))
+		if err != nil {
+			return nil, fmt.Errorf("fail to load source profile of profile %s: %w", name, err)
+		}
+		return NewAssumeRoleCredentialsProviderWithConfig(base, p.assumeRoleConfig()), nil
+	case "EcsRamRole":
+		return NewEcsRamRoleCredentialsProvider(p.RamRoleName), nil
+	case "External":
+		return NewProcessCredentialsProvider(p.ProcessCommand), nil
+	case "OIDC":
+		return NewOIDCCredentialsProvider(OIDCConfig{
+			RoleArn:         p.RamRoleArn,
+			OIDCProviderArn: p.OIDCProviderArn,
+			OIDCTokenFile:   p.OIDCTokenFile,
+			RoleSessionName: p.RoleSessionName,
+			Duration:        time.Duration(p.ExpiredSeconds) * time.Second,
+			StsEndpoint:     stsEndpointOf(p.StsRegion),
+		}), nil
+	}
+	return nil, fmt.Errorf("mode %s of profile %s is not supported", p.Mode, name)
+}
+
+func (p *cliProfile) assumeRoleConfig() AssumeRoleConfig {
+	return AssumeRoleConfig{
+		RoleArn:         p.RamRoleArn,
+		RoleSessionName: p.RoleSessionName,
+		Duration:        time.Duration(p.ExpiredSeconds) * time.Second,
+		StsEndpoint:     stsEndpointOf(p.StsRegion),
+	}
+}
+
+// processCredentialsOutput is the output of the credentials process, in the format of a profile
+// of the aliyun cli, with an optional expiration in RFC3339.
+type processCredentialsOutput struct {
+	Mode            string `json:"mode"`
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	StsToken        string `json:"sts_token"`
+	Expiration      string `json:"expiration"`
+}
+
+// NewProcessCredentialsProvider returns a provider that runs command with the shell to get credentials,
+// which writes a json to stdout as the aliyun cli external profile does, eg.
+//
+//	{"mode": "StsToken", "access_key_id": "id", "access_key_secret": "secret", "sts_token": "token", "expiration": "2024-01-01T00:00:00Z"}
+//
+// The command is run again before the expiration, or never again if there is no expiration.
+func NewProcessCredentialsProvider(command string) CredentialsProvider {
+	return NewUpdateFuncProviderAdapter(func() (accessKeyID, accessKeySecret, securityToken string, expireTime time.Time, err error) {
+		if command == "" {
+			return "", "", "", time.Time{}, errors.New("credentials process command is empty")
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), PROCESS_CREDENTIALS_TIMEOUT)
+		defer cancel()
+		var cmd *exec.Cmd
+		if runtime.GOOS == "windows" {
+			cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+		} else {
+			cmd = exec.CommandContext(ctx, "sh", "-c", command)
+		}
+		cmd.Stderr = os.Stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return "", "", "", time.Time{}, fmt.Errorf("fail to run credentials process: %w", err)
+		}
+		var output processCredentialsOutput
+		if err := json.Unmarshal(out, &output); err != nil {
+			return "", "", "", time.Time{}, fmt.Errorf("fail to unmarshal output of credentials process: %w", err)
+		}
+		// credentials without expiration never expire
+		expireTime = time.Now().AddDate(100, 0, 0)
+		if output.Expiration != "" {
+			if expireTime, err = time.Parse(time.RFC3339, output.Expiration); err != nil {
+				return "", "", "", time.Time{}, fmt.Errorf("invalid expiration of credentials process: %w", err)
+			}
+		}
+		return output.AccessKeyID, output.AccessKeySecret, output.StsToken, expireTime, nil
+	})
+}
+
+func stsEndpointOf(region string) string {
+	if region == "" {
+		return DEFAULT_STS_ENDPOINT
+	}
+	return fmt.Sprintf("sts.%s.aliyuncs.com", region)
+}
+
+// newSTSClient returns a sts client of endpoint, which is prefixed with http:// to use http,
+// the client is anonymous if cred is nil.
+func newSTSClient(endpoint string, cred *Credentials) (*sts.Client, error) {
+	protocol := "HTTPS"
+	if strings.HasPrefix(endpoint, "http://") {
+		protocol = "HTTP"
+	}
+	endpoint = strings.TrimPrefix(strings.TrimPrefix(endpoint, "http://"), "https://")
+	config := &openapi.Config{
+		Endpoint: &endpoint,
+		Protocol: &protocol,
+	}
+	if cred != nil {
+		config.AccessKeyId = &cred.AccessKeyID
+		config.AccessKeySecret = &cred.AccessKeySecret
+		if cred.SecurityToken != "" {
+			config.SecurityToken = &cred.SecurityToken
+		}
+	}
+	return sts.NewClient(config)
+}
+
+func stsTempCredentials(accessKeyID, accessKeySecret, securityToken, expiration *string) (*tempCredentials, error) {
+	if accessKeyID == nil || accessKeySecret == nil || securityToken == nil || expiration == nil {
+		return nil, errors.New("sts response has no credentials")
+	}
+	expireTime, err := time.Parse(time.RFC3339, *expiration)
+	if err != nil {
+		return nil, fmt.Errorf("invalid expiration of sts credentials: %w", err)
+	}
+	return newTempCredentials(*accessKeyID, *accessKeySecret, *securityToken, expireTime, time.Now()), nil
+}
+
+func newEcsRamRoleCredentialsProviderFromEnv() (CredentialsProvider, error) {
+	if strings.EqualFold(os.Getenv(ENV_ECS_METADATA_DISABLED), "true") {
+		return nil, fmt.Errorf("ecs metadata is disabled by env %s", ENV_ECS_METADATA_DISABLED)
+	}
+	roleName := os.Getenv(ENV_ECS_METADATA)
+	if roleName == "" {
+		var err error
+		if roleName, err = fetchEcsRamRoleName(ECS_RAM_ROLE_URL_PREFIX); err != nil {
+			return nil, err
+		}
+	}
+	return NewEcsRamRoleCredentialsProvider(roleName), nil
+}
+
+// fetchEcsRamRoleName returns the name of the ram role attached to the ecs instance.
+func fetchEcsRamRoleName(urlPrefix string) (string, error) {
+	client := &http.Client{Timeout: ECS_METADATA_TIMEOUT}
+	resp, err := client.Get(urlPrefix)
+	if err != nil {
+		return "", fmt.Errorf("fail to fetch ecs ram role name: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("fail to read http resp body: %w", err)
+	}
+	roleName := strings.TrimSpace(string(data))
+	if resp.StatusCode != http.StatusOK || roleName == "" {
+		return "", fmt.Errorf("fail to fetch ecs ram role name, status: %d, body: %s", resp.StatusCode, roleName)
+	}
+	level.Debug(Logger).Log("reason", "fetch ecs ram role name succeed", "roleName", roleName)
+	return roleName, nil
+}
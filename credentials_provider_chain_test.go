@@ -0,0 +1,126 @@
+package sls
+
+import (
+	"errors"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func clearCredentialsEnv(t *testing.T) {
+	for _, env := range []string{ENV_ACCESS_KEY_ID, ENV_ACCESS_KEY_SECRET, ENV_SECURITY_TOKEN, ENV_PROFILE,
+		ENV_CREDENTIALS_PROCESS, ENV_ROLE_ARN, ENV_OIDC_PROVIDER_ARN, ENV_OIDC_TOKEN_FILE, ENV_ECS_METADATA} {
+		t.Setenv(env, "")
+	}
+	t.Setenv(ENV_CONFIG_FILE, filepath.Join(t.TempDir(), "not-exist.json"))
+	t.Setenv(ENV_ECS_METADATA_DISABLED, "true")
+}
+
+func TestEnvCredentialsProvider(t *testing.T) {
+	clearCredentialsEnv(t)
+	provider := NewEnvCredentialsProvider()
+	_, err := provider.GetCredentials()
+	require.Error(t, err)
+
+	t.Setenv(ENV_ACCESS_KEY_ID, "id")
+	t.Setenv(ENV_ACCESS_KEY_SECRET, "secret")
+	t.Setenv(ENV_SECURITY_TOKEN, "token")
+	cred, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, Credentials{AccessKeyID: "id", AccessKeySecret: "secret", SecurityToken: "token"}, cred)
+}
+
+func TestProfileCredentialsProvider(t *testing.T) {
+	clearCredentialsEnv(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	config := `{
+		"current": "ak",
+		"profiles": [
+			{"name": "ak", "mode": "AK", "access_key_id": "id", "access_key_secret": "secret"},
+			{"name": "sts", "mode": "StsToken", "access_key_id": "sts-id", "access_key_secret": "sts-secret", "sts_token": "token"},
+			{"name": "external", "mode": "External", "process_command": "echo '{\"access_key_id\": \"process-id\", \"access_key_secret\": \"process-secret\"}'"},
+			{"name": "loop", "mode": "ChainableRamRoleArn", "source_profile": "loop", "ram_role_arn": "role"},
+			{"name": "unknown", "mode": "Unknown"}
+		]
+	}`
+	require.NoError(t, ioutil.WriteFile(path, []byte(config), 0600))
+
+	cred, err := NewProfileCredentialsProvider(path, "").GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, Credentials{AccessKeyID: "id", AccessKeySecret: "secret"}, cred)
+
+	t.Setenv(ENV_PROFILE, "sts")
+	cred, err = NewProfileCredentialsProvider(path, "").GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, Credentials{AccessKeyID: "sts-id", AccessKeySecret: "sts-secret", SecurityToken: "token"}, cred)
+
+	cred, err = NewProfileCredentialsProvider(path, "external").GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "process-id", cred.AccessKeyID)
+
+	_, err = NewProfileCredentialsProvider(path, "loop").GetCredentials()
+	require.ErrorContains(t, err, "loop")
+	_, err = NewProfileCredentialsProvider(path, "unknown").GetCredentials()
+	require.Error(t, err)
+	_, err = NewProfileCredentialsProvider(path, "not-exist").GetCredentials()
+	require.Error(t, err)
+}
+
+func TestProcessCredentialsProvider(t *testing.T) {
+	provider := NewProcessCredentialsProvider(`echo '{"mode": "StsToken", "access_key_id": "id", "access_key_secret": "secret", "sts_token": "token", "expiration": "2100-01-01T00:00:00Z"}'`)
+	cred, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, Credentials{AccessKeyID: "id", AccessKeySecret: "secret", SecurityToken: "token"}, cred)
+
+	_, err = NewProcessCredentialsProvider("echo invalid").GetCredentials()
+	require.Error(t, err)
+	_, err = NewProcessCredentialsProvider("exit 1").GetCredentials()
+	require.Error(t, err)
+}
+
+type countingCredentialsProvider struct {
+	cred  Credentials
+	err   error
+	calls int
+}
+
+func (p *countingCredentialsProvider) GetCredentials() (Credentials, error) {
+	p.calls++
+	return p.cred, p.err
+}
+
+func TestCredentialsProviderChain(t *testing.T) {
+	failed := &countingCredentialsProvider{err: errors.New("no credentials")}
+	succeeded := &countingCredentialsProvider{cred: Credentials{AccessKeyID: "id", AccessKeySecret: "secret"}}
+	unused := &countingCredentialsProvider{cred: Credentials{AccessKeyID: "unused"}}
+	chain := NewCredentialsProviderChain(failed, succeeded, unused)
+	for i := 0; i < 2; i++ {
+		cred, err := chain.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "id", cred.AccessKeyID)
+	}
+	// the provider succeeded is remembered
+	require.Equal(t, 1, failed.calls)
+	require.Equal(t, 2, succeeded.calls)
+	require.Equal(t, 0, unused.calls)
+
+	_, err := NewCredentialsProviderChain(failed).GetCredentials()
+	require.ErrorContains(t, err, "no credentials")
+
+	clearCredentialsEnv(t)
+	_, err = NewDefaultCredentialsProvider().GetCredentials()
+	require.Error(t, err)
+
+	t.Setenv(ENV_CREDENTIALS_PROCESS, `echo '{"access_key_id": "process-id", "access_key_secret": "process-secret"}'`)
+	cred, err := NewDefaultCredentialsProvider().GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "process-id", cred.AccessKeyID)
+
+	t.Setenv(ENV_ACCESS_KEY_ID, "env-id")
+	t.Setenv(ENV_ACCESS_KEY_SECRET, "env-secret")
+	cred, err = NewDefaultCredentialsProvider().GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "env-id", cred.AccessKeyID)
+}
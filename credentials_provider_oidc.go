@@ -0,0 +1,169 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	sts "github.com/alibabacloud-go/sts-20150401/v2/client"
+	"github.com/go-kit/kit/log/level"
+)
+
+// OIDCConfig is the config of the role assumed by NewOIDCCredentialsProvider.
+type OIDCConfig struct {
+	RoleArn         string
+	OIDCProviderArn string
+	OIDCTokenFile   string // the file of the OIDC token, which is read again once it is rotated
+	RoleSessionName string // defaults to DEFAULT_ROLE_SESSION_NAME
+	Policy          string // optional, the policy to further restrict the permissions of the role
+	// The duration of the credentials, defaults to the max session duration of the role if zero,
+	// it is truncated to seconds.
+	Duration    time.Duration
+	StsEndpoint string // defaults to DEFAULT_STS_ENDPOINT, prefixed with http:// to use http
+}
+
+// OIDCCredentialsProvider provides the credentials of a ram role assumed with an OIDC token,
+// eg. the RRSA of pods on ACK.
+//
+// The credentials are refreshed before the expiration, or once the token file is rotated,
+// which is checked every time GetCredentials is called. If failed to fetch with the rotated
+// token, it is fetched with again after a backoff, unless the token file is rotated again.
+type OIDCCredentialsProvider struct {
+	config OIDCConfig
+
+	mu        sync.Mutex
+	adapter   *UpdateFuncProviderAdapter
+	tokenStat os.FileInfo // stat of the token file when the credentials of adapter are fetched
+
+	failedStat    os.FileInfo // stat of the rotated token file failed to fetch with
+	retryAt       time.Time   // the rotated token file is not fetched with again before
+	retryInterval time.Duration
+}
+
+const (
+	oidcRetryMinInterval = time.Second
+	oidcRetryMaxInterval = time.Minute
+)
+
+// NewOIDCCredentialsProvider returns an OIDCCredentialsProvider of config, which can be used as
+// the CredentialsProvider of CreateNormalInterfaceV2, ProducerConfig and LogHubConfig.
+func NewOIDCCredentialsProvider(config OIDCConfig) *OIDCCredentialsProvider {
+	if config.RoleSessionName == "" {
+		config.RoleSessionName = DEFAULT_ROLE_SESSION_NAME
+	}
+	if config.StsEndpoint == "" {
+		config.StsEndpoint = DEFAULT_STS_ENDPOINT
+	}
+	p := &OIDCCredentialsProvider{config: config}
+	p.adapter = p.newAdapter()
+	return p
+}
+
+// NewOIDCCredentialsProviderFromEnv returns an OIDCCredentialsProvider configured by the environment variables
+// injected into pods by the RRSA of ACK:
+//
+//   - ALIBABA_CLOUD_ROLE_ARN, ALIBABA_CLOUD_OIDC_PROVIDER_ARN and ALIBABA_CLOUD_OIDC_TOKEN_FILE, which are required.
+//   - ALIBABA_CLOUD_ROLE_SESSION_NAME, which is optional.
+//   - ALIBABA_CLOUD_STS_REGION, which is optional, the sts endpoint of the region is used if set.
+func NewOIDCCredentialsProviderFromEnv() (*OIDCCredentialsProvider, error) {
+	config := OIDCConfig{
+		RoleArn:         os.Getenv(ENV_ROLE_ARN),
+		OIDCProviderArn: os.Getenv(ENV_OIDC_PROVIDER_ARN),
+		OIDCTokenFile:   os.Getenv(ENV_OIDC_TOKEN_FILE),
+		RoleSessionName: os.Getenv(ENV_ROLE_SESSION_NAME),
+		StsEndpoint:     stsEndpointOf(os.Getenv(ENV_STS_REGION)),
+	}
+	if config.RoleArn == "" || config.OIDCProviderArn == "" || config.OIDCTokenFile == "" {
+		return nil, fmt.Errorf("env %s, %s or %s is not set", ENV_ROLE_ARN, ENV_OIDC_PROVIDER_ARN, ENV_OIDC_TOKEN_FILE)
+	}
+	return NewOIDCCredentialsProvider(config), nil
+}
+
+func (p *OIDCCredentialsProvider) GetCredentials() (Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stat, err := os.Stat(p.config.OIDCTokenFile)
+	if err == nil && p.tokenStat != nil && tokenFileRotated(p.tokenStat, stat) && p.shouldRetry(stat) {
+		level.Debug(Logger).Log("reason", "oidc token file rotated, fetch new credentials", "file", p.config.OIDCTokenFile)
+		adapter := p.newAdapter()
+		cred, fetchErr := adapter.GetCredentials()
+		if fetchErr == nil {
+			p.adapter, p.tokenStat, p.failedStat = adapter, stat, nil
+			return cred, nil
+		}
+		// keep using the credentials of the last token if failed to fetch with the rotated one
+		p.backoff(stat)
+		level.Warn(Logger).Log("reason", "fail to fetch credentials with rotated oidc token", "err", fetchErr, "retryAt", p.retryAt)
+	}
+	refresh := p.adapter.shouldRefresh()
+	cred, fetchErr := p.adapter.GetCredentials()
+	if refresh && fetchErr == nil && err == nil {
+		p.tokenStat, p.failedStat = stat, nil
+	}
+	return cred, fetchErr
+}
+
+// shouldRetry returns whether to fetch with the rotated token file of stat, which is not fetched with
+// during the backoff after a failure, unless the token file is rotated again.
+func (p *OIDCCredentialsProvider) shouldRetry(stat os.FileInfo) bool {
+	return p.failedStat == nil || tokenFileRotated(p.failedStat, stat) || !time.Now().Before(p.retryAt)
+}
+
+// backoff records the failure to fetch with the token file of stat, the interval doubles on every
+// failure with the same token file.
+func (p *OIDCCredentialsProvider) backoff(stat os.FileInfo) {
+	if p.failedStat == nil || tokenFileRotated(p.failedStat, stat) {
+		p.retryInterval = oidcRetryMinInterval
+	} else if p.retryInterval *= 2; p.retryInterval > oidcRetryMaxInterval {
+		p.retryInterval = oidcRetryMaxInterval
+	}
+	p.failedStat, p.retryAt = stat, time.Now().Add(p.retryInterval)
+}
+
+func tokenFileRotated(last, current os.FileInfo) bool {
+	return !last.ModTime().Equal(current.ModTime()) || last.Size() != current.Size() || !os.SameFile(last, current)
+}
+
+func (p *OIDCCredentialsProvider) newAdapter() *UpdateFuncProviderAdapter {
+	return &UpdateFuncProviderAdapter{
+		fetcher:    fetcherWithRetry(p.fetch, UPDATE_FUNC_RETRY_TIMES),
+		fetchAhead: defaultFetchAhead,
+	}
+}
+
+func (p *OIDCCredentialsProvider) fetch() (*tempCredentials, error) {
+	token, err := ioutil.ReadFile(p.config.OIDCTokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("fail to read oidc token file: %w", err)
+	}
+	client, err := newSTSClient(p.config.StsEndpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("fail to create sts client: %w", err)
+	}
+	oidcToken := string(token)
+	req := &sts.AssumeRoleWithOIDCRequest{
+		RoleArn:         &p.config.RoleArn,
+		OIDCProviderArn: &p.config.OIDCProviderArn,
+		OIDCToken:       &oidcToken,
+		RoleSessionName: &p.config.RoleSessionName,
+	}
+	if p.config.Policy != "" {
+		req.Policy = &p.config.Policy
+	}
+	if p.config.Duration > 0 {
+		seconds := int64(p.config.Duration / time.Second)
+		req.DurationSeconds = &seconds
+	}
+	resp, err := client.AssumeRoleWithOIDC(req)
+	if err != nil {
+		return nil, fmt.Errorf("fail to assume role %s with oidc: %w", p.config.RoleArn, err)
+	}
+	if resp.Body == nil || resp.Body.Credentials == nil {
+		return nil, errors.New("sts response has no credentials")
+	}
+	c := resp.Body.Credentials
+	return stsTempCredentials(c.AccessKeyId, c.AccessKeySecret, c.SecurityToken, c.Expiration)
+}
@@ -0,0 +1,109 @@
+package sls
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCCredentialsProvider(t *testing.T) {
+	server := newFakeSTSServer(t, time.Hour)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token-1"), 0600))
+
+	clearCredentialsEnv(t)
+	_, err := NewOIDCCredentialsProviderFromEnv()
+	require.Error(t, err)
+	t.Setenv(ENV_ROLE_ARN, "role")
+	t.Setenv(ENV_OIDC_PROVIDER_ARN, "oidc-provider")
+	t.Setenv(ENV_OIDC_TOKEN_FILE, tokenFile)
+	provider, err := NewOIDCCredentialsProviderFromEnv()
+	require.NoError(t, err)
+	provider.config.StsEndpoint = server.URL
+	var _ ClientInterface = CreateNormalInterfaceV2("cn-hangzhou.log.aliyuncs.com", provider)
+
+	for i := 0; i < 3; i++ {
+		cred, err := provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, Credentials{AccessKeyID: "role-1", AccessKeySecret: "secret", SecurityToken: "token"}, cred)
+	}
+	requests := server.Requests()
+	require.Len(t, requests, 1)
+	require.Equal(t, "AssumeRoleWithOIDC", requests[0]["Action"])
+	require.Equal(t, "oidc-provider", requests[0]["OIDCProviderArn"])
+	require.Equal(t, "token-1", requests[0]["OIDCToken"])
+	require.Equal(t, DEFAULT_ROLE_SESSION_NAME, requests[0]["RoleSessionName"])
+
+	// the rotated token is used before the credentials expire
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("rotated-token-2"), 0600))
+	cred, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "role-2", cred.AccessKeyID)
+	cred, err = provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "role-2", cred.AccessKeyID)
+	requests = server.Requests()
+	require.Len(t, requests, 2)
+	require.Equal(t, "rotated-token-2", requests[1]["OIDCToken"])
+
+	// the credentials of the last token are used if failed to fetch with the rotated one
+	provider.config.RoleArn = ""
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("rotated-token-33"), 0600))
+	cred, err = provider.GetCredentials()
+	require.NoError(t, err)
+	require.Equal(t, "role-2", cred.AccessKeyID)
+}
+
+func TestOIDCCredentialsProviderRotatedBackoff(t *testing.T) {
+	server := newFakeSTSServer(t, time.Hour)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("token-1"), 0600))
+	provider := NewOIDCCredentialsProvider(OIDCConfig{
+		RoleArn:         "role",
+		OIDCProviderArn: "oidc-provider",
+		OIDCTokenFile:   tokenFile,
+		StsEndpoint:     server.URL,
+	})
+	_, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.Len(t, server.Requests(), 1)
+
+	// sts fails with the rotated token, which is not fetched with again during the backoff
+	provider.config.RoleArn = ""
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("rotated-token-2"), 0600))
+	for i := 0; i < 10; i++ {
+		cred, err := provider.GetCredentials()
+		require.NoError(t, err)
+		require.Equal(t, "role-1", cred.AccessKeyID)
+	}
+	attempts := UPDATE_FUNC_RETRY_TIMES + 1
+	require.Len(t, server.Requests(), 1+attempts)
+	require.Equal(t, oidcRetryMinInterval, provider.retryInterval)
+
+	// fetched with again once the backoff elapses, and the interval doubles
+	provider.retryAt = time.Now()
+	for i := 0; i < 10; i++ {
+		_, err = provider.GetCredentials()
+		require.NoError(t, err)
+	}
+	require.Len(t, server.Requests(), 1+2*attempts)
+	require.Equal(t, 2*oidcRetryMinInterval, provider.retryInterval)
+
+	// fetched with at once if the token file is rotated again
+	require.NoError(t, ioutil.WriteFile(tokenFile, []byte("rotated-token-333"), 0600))
+	_, err = provider.GetCredentials()
+	require.NoError(t, err)
+	require.Len(t, server.Requests(), 1+3*attempts)
+	require.Equal(t, oidcRetryMinInterval, provider.retryInterval)
+
+	provider.config.RoleArn = "role"
+	provider.retryAt = time.Now()
+	cred, err := provider.GetCredentials()
+	require.NoError(t, err)
+	require.NotEqual(t, "role-1", cred.AccessKeyID)
+	require.Len(t, server.Requests(), 2+3*attempts)
+	require.Nil(t, provider.failedStat)
+}
@@ -0,0 +1,160 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// EndpointType is the network type of an SLS endpoint.
+type EndpointType string
+
+const (
+	EndpointPublic       EndpointType = "public"       // {region}.log.aliyuncs.com
+	EndpointIntranet     EndpointType = "intranet"     // {region}-intranet.log.aliyuncs.com, for ECS in the region
+	EndpointVPC          EndpointType = "vpc"          // {region}-vpc.log.aliyuncs.com
+	EndpointShare        EndpointType = "share"        // {region}-share.log.aliyuncs.com
+	EndpointDualStack    EndpointType = "dualstack"    // {region}-dualstack.log.aliyuncs.com, for both IPv4 and IPv6
+	EndpointAcceleration EndpointType = "acceleration" // log-global.aliyuncs.com, global acceleration for all regions
+)
+
+var endpointFormats = map[EndpointType]string{
+	EndpointPublic:       "%s.log.aliyuncs.com",
+	EndpointIntranet:     "%s-intranet.log.aliyuncs.com",
+	EndpointVPC:          "%s-vpc.log.aliyuncs.com",
+	EndpointShare:        "%s-share.log.aliyuncs.com",
+	EndpointDualStack:    "%s-dualstack.log.aliyuncs.com",
+	EndpointAcceleration: "log-global.aliyuncs.com",
+}
+
+// EndpointResolver builds SLS endpoints of a region.
+//
+//	resolver := sls.EndpointResolver{Region: "cn-hangzhou"}
+//	endpoints, err := resolver.ResolveAll(sls.EndpointIntranet, sls.EndpointPublic)
+//	client.SetEndpoints(endpoints...)
+type EndpointResolver struct {
+	Region   string // eg. cn-hangzhou
+	UseHTTPS bool   // prefix endpoints with https://, otherwise endpoints have no scheme and http is used
+}
+
+// Resolve returns the endpoint of type t.
+func (r EndpointResolver) Resolve(t EndpointType) (string, error) {
+	format, ok := endpointFormats[t]
+	if !ok {
+		return "", fmt.Errorf("unknown endpoint type: %s", t)
+	}
+	var endpoint string
+	if t == EndpointAcceleration {
+		endpoint = format
+	} else {
+		if r.Region == "" {
+			return "", fmt.Errorf("region is required to resolve %s endpoint", t)
+		}
+		endpoint = fmt.Sprintf(format, r.Region)
+	}
+	if r.UseHTTPS {
+		endpoint = httpsScheme + endpoint
+	}
+	return endpoint, nil
+}
+
+// ResolveAll returns the endpoints of types in order, it can be passed to Client.SetEndpoints.
+func (r EndpointResolver) ResolveAll(types ...EndpointType) ([]string, error) {
+	endpoints := make([]string, 0, len(types))
+	for _, t := range types {
+		endpoint, err := r.Resolve(t)
+		if err != nil {
+			return nil, err
+		}
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints, nil
+}
+
+// an endpoint that fails to connect is not preferred for a while
+const defaultEndpointCooldown = 30 * time.Second
+
+// endpointGroup is an ordered list of endpoints to fail over between.
+//
+// Requests are sent to the first healthy endpoint, and are resent to the next endpoint
+// on connection errors. The endpoint failed is marked unhealthy for a cooldown time,
+// so the following requests stick to the next endpoint until the cooldown expires.
+type endpointGroup struct {
+	endpoints []string
+	cooldown  time.Duration
+
+	mu             sync.Mutex
+	unhealthyUntil []time.Time
+}
+
+func newEndpointGroup(endpoints []string, cooldown time.Duration) *endpointGroup {
+	return &endpointGroup{
+		endpoints:      endpoints,
+		cooldown:       cooldown,
+		unhealthyUntil: make([]time.Time, len(endpoints)),
+	}
+}
+
+// pick returns the index of the first healthy endpoint not tried,
+// or the first one not tried if all of them are unhealthy.
+func (g *endpointGroup) pick(tried []bool) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	now := time.Now()
+	first := -1
+	for i := range g.endpoints {
+		if tried[i] {
+			continue
+		}
+		if now.After(g.unhealthyUntil[i]) {
+			return i
+		}
+		if first < 0 {
+			first = i
+		}
+	}
+	return first
+}
+
+func (g *endpointGroup) markUnhealthy(i int) {
+	g.mu.Lock()
+	g.unhealthyUntil[i] = time.Now().Add(g.cooldown)
+	g.mu.Unlock()
+}
+
+// do calls send with endpoints until it does not fail with a connection error
+// or all endpoints are tried.
+func (g *endpointGroup) do(send func(endpoint string) (*http.Response, error)) (resp *http.Response, err error) {
+	tried := make([]bool, len(g.endpoints))
+	for n := 0; n < len(g.endpoints); n++ {
+		i := g.pick(tried)
+		tried[i] = true
+		resp, err = send(g.endpoints[i])
+		if errors.Is(err, ErrCircuitOpen) {
+			continue
+		}
+		if !isConnectionError(err) {
+			return resp, err
+		}
+		g.markUnhealthy(i)
+	}
+	return resp, err
+}
+
+// isConnectionError reports whether err is an error of connecting to the endpoint,
+// in which case the request is not sent and it is safe to send it to another endpoint.
+func isConnectionError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,105 @@
+package sls
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointResolver(t *testing.T) {
+	resolver := EndpointResolver{Region: "cn-hangzhou"}
+	endpoints, err := resolver.ResolveAll(EndpointIntranet, EndpointVPC, EndpointPublic, EndpointDualStack, EndpointAcceleration)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"cn-hangzhou-intranet.log.aliyuncs.com",
+		"cn-hangzhou-vpc.log.aliyuncs.com",
+		"cn-hangzhou.log.aliyuncs.com",
+		"cn-hangzhou-dualstack.log.aliyuncs.com",
+		"log-global.aliyuncs.com",
+	}, endpoints)
+
+	resolver.UseHTTPS = true
+	endpoint, err := resolver.Resolve(EndpointShare)
+	require.NoError(t, err)
+	require.Equal(t, "https://cn-hangzhou-share.log.aliyuncs.com", endpoint)
+
+	_, err = EndpointResolver{}.Resolve(EndpointPublic)
+	require.Error(t, err)
+	endpoint, err = EndpointResolver{}.Resolve(EndpointAcceleration)
+	require.NoError(t, err)
+	require.Equal(t, "log-global.aliyuncs.com", endpoint)
+	_, err = resolver.Resolve("unknown")
+	require.Error(t, err)
+}
+
+// countingTransport counts the requests sent to each host.
+type countingTransport struct {
+	mu     sync.Mutex
+	counts map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.mu.Lock()
+	t.counts[req.URL.Host]++
+	t.mu.Unlock()
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *countingTransport) count(host string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.counts[host]
+}
+
+func TestEndpointFailover(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/shards") {
+			w.Write([]byte(`[{"shardID":0,"status":"readwrite"}]`))
+			return
+		}
+		w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+	// an endpoint refusing connections
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := listener.Addr().String()
+	listener.Close()
+	reachable := strings.TrimPrefix(server.URL, "http://")
+
+	transport := &countingTransport{counts: map[string]int{}}
+	client := CreateNormalInterface("", "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	client.SetEndpoints(unreachable, reachable)
+	require.Equal(t, unreachable, client.Endpoint)
+
+	// the client path fails over to the next endpoint
+	_, err = client.GetCheckpoint("", "testLogstore", "cg")
+	require.NoError(t, err)
+	require.Equal(t, 1, transport.count(unreachable))
+	require.Equal(t, 1, transport.count(reachable))
+
+	// the following requests stick to the healthy endpoint, including the project path
+	_, err = client.GetCheckpoint("", "testLogstore", "cg")
+	require.NoError(t, err)
+	shards, err := client.ListShards("", "testLogstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+	require.Equal(t, 1, transport.count(unreachable))
+	require.Equal(t, 3, transport.count(reachable))
+
+	// the preferred endpoint is tried again after the cooldown
+	client.endpoints.cooldown = 0
+	client.endpoints.markUnhealthy(0)
+	time.Sleep(time.Millisecond)
+	_, err = client.ListShards("", "testLogstore")
+	require.NoError(t, err)
+	require.Equal(t, 2, transport.count(unreachable))
+	require.Equal(t, 4, transport.count(reachable))
+}
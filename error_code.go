@@ -16,6 +16,7 @@ const UN_AUTHORIZED = "Unauthorized"
 const MISSING_SIGNATURE_METHOD = "MissingSignatureMethod"
 const INVALID_SIGNATURE_METHOD = "InvalidSignatureMethod"
 const REQUEST_TIME_TOO_SKEWED = "RequestTimeTooSkewed"
+const REQUEST_TIME_EXPIRED = "RequestTimeExpired"
 const PROJECT_NOT_EXIST = "ProjectNotExist"
 const SIGNATURE_NOT_MATCH = "SignatureNotMatch"
 const WRITE_QUOTA_EXCEED = "WriteQuotaExceed"
@@ -53,3 +54,19 @@ const CONFIG_ALREADY_EXIST = "ConfigAlreadyExist"
 const BAD_REQUEST = "BadRequest"
 const INVALID_PARAMETER = "InvalidParameter"
 const NOT_SUPPORTED = "NotSupported"
+const PROJECT_ALREADY_EXIST = "ProjectAlreadyExist"
+const MACHINE_GROUP_NOT_EXIST = "MachineGroupNotExist"
+const MACHINE_GROUP_ALREADY_EXIST = "MachineGroupAlreadyExist"
+const INDEX_CONFIG_NOT_EXIST = "IndexConfigNotExist"
+const INDEX_ALREADY_EXIST = "IndexAlreadyExist"
+const DASHBOARD_NOT_EXIST = "DashboardNotExist"
+const DASHBOARD_ALREADY_EXIST = "DashboardAlreadyExist"
+const CHART_NOT_EXIST = "ChartNotExist"
+const CHART_ALREADY_EXIST = "ChartAlreadyExist"
+const SAVED_SEARCH_NOT_EXIST = "SavedSearchNotExist"
+const SAVED_SEARCH_ALREADY_EXIST = "SavedSearchAlreadyExist"
+const JOB_NOT_EXIST = "JobNotExist"
+const JOB_ALREADY_EXIST = "JobAlreadyExist"
+const CONSUMER_GROUP_NOT_EXIST = "ConsumerGroupNotExist"
+const CONSUMER_GROUP_ALREADY_EXIST = "ConsumerGroupAlreadyExist"
+const CONSUMER_NOT_MATCH = "ConsumerNotMatch"
@@ -2,10 +2,84 @@ package sls
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 )
 
+// Sentinel errors of SLS error codes, the errors returned by the client can be checked with errors.Is, eg.
+//
+//	if errors.Is(err, sls.ErrLogStoreNotExist) {
+//		// create the logstore
+//	}
+//
+// Use errors.As with *Error to get the error code, http code and request id of the error.
+var (
+	ErrProjectNotExist      = errors.New("sls: project not exist")
+	ErrLogStoreNotExist     = errors.New("sls: logstore not exist")
+	ErrLogStoreAlreadyExist = errors.New("sls: logstore already exist")
+	ErrShardNotExist        = errors.New("sls: shard not exist")
+	ErrQuotaExceeded        = errors.New("sls: read or write quota exceeded")
+	ErrUnauthorized         = errors.New("sls: unauthorized")
+	ErrInvalidCursor        = errors.New("sls: invalid cursor")
+	ErrInvalidParameter     = errors.New("sls: invalid parameter")
+	ErrServerBusy           = errors.New("sls: server busy")
+)
+
+// errorCodeSentinels maps error codes to the sentinel errors they match.
+var errorCodeSentinels = map[string]error{
+	PROJECT_NOT_EXIST:        ErrProjectNotExist,
+	LOGSTORE_NOT_EXIST:       ErrLogStoreNotExist,
+	LOGSTORE_ALREADY_EXIST:   ErrLogStoreAlreadyExist,
+	SHARD_NOT_EXIST:          ErrShardNotExist,
+	WRITE_QUOTA_EXCEED:       ErrQuotaExceeded,
+	SHARD_WRITE_QUOTA_EXCEED: ErrQuotaExceeded,
+	READ_QUOTA_EXCEED:        ErrQuotaExceeded,
+	SHARD_READ_QUOTA_EXCEED:  ErrQuotaExceeded,
+	UN_AUTHORIZED:            ErrUnauthorized,
+	SIGNATURE_NOT_MATCH:      ErrUnauthorized,
+	MISS_ACCESS_KEY_ID:       ErrUnauthorized,
+	INVALID_CURSOR:           ErrInvalidCursor,
+	PARAMETER_INVALID:        ErrInvalidParameter,
+	INVALID_PARAMETER:        ErrInvalidParameter,
+	SERVER_BUSY:              ErrServerBusy,
+}
+
+// Is reports whether the error matches target, the sentinel error of its error code.
+func (e *Error) Is(target error) bool {
+	if target == ErrUnauthorized && e.HTTPCode == http.StatusUnauthorized {
+		return true
+	}
+	sentinel, ok := errorCodeSentinels[e.Code]
+	return ok && sentinel == target
+}
+
+// serverErrorOf returns the sls error returned by the server in err's chain, skipping the
+// client errors wrapping it, or nil if there is none.
+func serverErrorOf(err error) *Error {
+	var slsErr *Error
+	for errors.As(err, &slsErr) {
+		if slsErr.HTTPCode > 0 {
+			return slsErr
+		}
+		err = slsErr.cause
+	}
+	return nil
+}
+
+// httpCodeOf returns the http code of the sls error in err's chain,
+// or 0 if there is none or the error is not returned by the server.
+func httpCodeOf(err error) int {
+	if slsErr := serverErrorOf(err); slsErr != nil {
+		return int(slsErr.HTTPCode)
+	}
+	var badRespErr *BadResponseError
+	if errors.As(err, &badRespErr) {
+		return badRespErr.HTTPCode
+	}
+	return 0
+}
+
 func invalidJsonRespError(body string, header http.Header, httpCode int) error {
 	return newBadResponseError(
 		string(body),
@@ -40,6 +114,7 @@ type BadResponseError struct {
 	RespHeader   map[string][]string
 	HTTPCode     int
 	ErrorMessage string
+	RequestID    string
 }
 
 func (e BadResponseError) String() string {
@@ -54,12 +129,18 @@ func (e BadResponseError) Error() string {
 	return e.String()
 }
 
+// Is reports whether the error matches target, only ErrUnauthorized can be matched by http code.
+func (e BadResponseError) Is(target error) bool {
+	return target == ErrUnauthorized && e.HTTPCode == http.StatusUnauthorized
+}
+
 // NewBadResponseError ...
 func NewBadResponseError(body string, header map[string][]string, httpCode int) *BadResponseError {
 	return &BadResponseError{
 		RespBody:   body,
 		RespHeader: header,
 		HTTPCode:   httpCode,
+		RequestID:  http.Header(header).Get(RequestIDHeader),
 	}
 }
 
@@ -69,6 +150,7 @@ func newBadResponseError(body string, header map[string][]string, httpCode int,
 		RespHeader:   header,
 		HTTPCode:     httpCode,
 		ErrorMessage: err.Error(),
+		RequestID:    http.Header(header).Get(RequestIDHeader),
 	}
 }
 
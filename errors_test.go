@@ -0,0 +1,106 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+
+	"github.com/cenkalti/backoff"
+	"github.com/jarcoal/httpmock"
+	pkgerrors "github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorIs(t *testing.T) {
+	header := http.Header{}
+	header.Set(RequestIDHeader, "testRequestID")
+	err := httpStatusNotOkError([]byte(`{"errorCode":"LogStoreNotExist","errorMessage":"logstore not exist"}`), header, 404)
+	require.True(t, errors.Is(err, ErrLogStoreNotExist))
+	require.False(t, errors.Is(err, ErrProjectNotExist))
+	var slsErr *Error
+	require.True(t, errors.As(err, &slsErr))
+	require.Equal(t, int32(404), slsErr.HTTPCode)
+	require.Equal(t, "testRequestID", slsErr.RequestID)
+
+	// client errors keep their code, and the request id and sentinel of the wrapped error
+	clientErr := NewClientError(fmt.Errorf("get logstore: %w", err))
+	require.True(t, errors.Is(clientErr, ErrLogStoreNotExist))
+	require.Equal(t, "ClientError", clientErr.Code)
+	require.Equal(t, int32(-1), clientErr.HTTPCode)
+	require.Equal(t, "testRequestID", clientErr.RequestID)
+	require.Equal(t, 404, httpCodeOf(clientErr))
+
+	for code, sentinel := range map[string]error{
+		SHARD_WRITE_QUOTA_EXCEED: ErrQuotaExceeded,
+		READ_QUOTA_EXCEED:        ErrQuotaExceeded,
+		INVALID_CURSOR:           ErrInvalidCursor,
+		SHARD_NOT_EXIST:          ErrShardNotExist,
+	} {
+		require.True(t, errors.Is(&Error{HTTPCode: 400, Code: code}, sentinel), code)
+	}
+	require.True(t, errors.Is(&Error{HTTPCode: 401, Code: "InvalidAccessKeyId"}, ErrUnauthorized))
+
+	// errors with invalid json body
+	err = httpStatusNotOkError([]byte(`unauthorized`), header, 401)
+	var badRespErr *BadResponseError
+	require.True(t, errors.As(err, &badRespErr))
+	require.Equal(t, "testRequestID", badRespErr.RequestID)
+	require.True(t, errors.Is(err, ErrUnauthorized))
+	clientErr = NewClientError(err)
+	require.Equal(t, "testRequestID", clientErr.RequestID)
+	require.True(t, errors.As(clientErr, &badRespErr))
+	require.Equal(t, 401, badRespErr.HTTPCode)
+}
+
+func TestRetryStoppedError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := RetryWithCondition(ctx, backoff.NewExponentialBackOff(), func() (bool, error) { return false, nil })
+	require.Contains(t, err.Error(), "stopped retrying err")
+	require.True(t, errors.Is(err, context.Canceled))
+
+	lastErr := &Error{HTTPCode: 503, Code: SERVER_BUSY}
+	err = newRetryStoppedError(context.DeadlineExceeded, lastErr)
+	require.True(t, errors.Is(err, context.DeadlineExceeded))
+	require.True(t, errors.Is(err, ErrServerBusy))
+	require.Equal(t, 503, httpCodeOf(err))
+	require.Equal(t, context.DeadlineExceeded, pkgerrors.Cause(err))
+}
+
+func TestRetryErrorCheckWrapped(t *testing.T) {
+	wrapped := fmt.Errorf("wrapped: %w", &Error{HTTPCode: 502, Code: INTERNAL_SERVER_ERROR})
+	retry, err := retryReadErrorCheck(context.Background(), wrapped)
+	require.True(t, retry)
+	require.Equal(t, wrapped, err)
+	retry, _ = retryWriteErrorCheck(context.Background(), wrapped)
+	require.True(t, retry)
+
+	retry, _ = retryReadErrorCheck(context.Background(), fmt.Errorf("wrapped: %w", &url.Error{Op: "Get", Err: errors.New("reset")}))
+	require.True(t, retry)
+	retry, _ = retryReadErrorCheck(context.Background(), NewClientError(&Error{HTTPCode: 404, Code: LOGSTORE_NOT_EXIST}))
+	require.False(t, retry)
+}
+
+func TestGetLogsBytesTypedError(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	transport := httpmock.NewMockTransport()
+	transport.RegisterRegexpResponder("GET", regexp.MustCompile(fmt.Sprintf(`^http://%s\.%s/logstores/%s/shards/0`, project, endpoint, logstore)),
+		func(req *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(400, `{"errorCode":"InvalidCursor","errorMessage":"cursor is invalid"}`)
+			resp.Header.Set(RequestIDHeader, "testRequestID")
+			return resp, nil
+		})
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	_, _, err := client.PullLogs(project, logstore, 0, "invalid", "", 10)
+	require.True(t, errors.Is(err, ErrInvalidCursor), err)
+	var slsErr *Error
+	require.True(t, errors.As(err, &slsErr))
+	require.Equal(t, int32(400), slsErr.HTTPCode)
+	require.Equal(t, "testRequestID", slsErr.RequestID)
+}
@@ -0,0 +1,1226 @@
+package sls
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aliyun/aliyun-log-go-sdk/internal/fakestore"
+	"github.com/gogo/protobuf/proto"
+)
+
+var (
+	projectNameRegexp  = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$`)
+	logstoreNameRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{1,61}[a-z0-9]$`)
+)
+
+// FakeClient is a stateful in-memory implementation of ClientInterface for unit tests.
+//
+// Projects, logstores, shards, indexes, machine groups, configs, dashboards, saved searches,
+// alerts, consumer groups, store views, metric configs, tags, resources and jobs such as ETL,
+// scheduled SQL, ingestion and export are kept in memory and validated like the service does,
+// failing with the same error codes, eg. a *Error with code LogStoreNotExist for a missing logstore.
+// Logs written are readable back with GetCursor and PullLogs, and searchable by keywords with GetLogs.
+// Jobs are never run, so no job instances exist.
+//
+// Operations not modeled, such as SQL analysis, fail with code NotSupported.
+type FakeClient struct {
+	mu              sync.Mutex
+	projects        map[string]*fakeProject
+	resources       *fakeResources
+	resourceRecords map[string]*fakeResources // resource name => records
+}
+
+var _ ClientInterface = (*FakeClient)(nil)
+
+// NewFakeClient returns an empty FakeClient, which has no projects.
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		projects:        make(map[string]*fakeProject),
+		resources:       newFakeResources("resource", "ResourceNotExist", "ResourceAlreadyExist"),
+		resourceRecords: make(map[string]*fakeResources),
+	}
+}
+
+type fakeProject struct {
+	meta           LogProject
+	policy         string
+	logstores      map[string]*fakeLogStore
+	machineGroups  *fakeResources
+	configs        *fakeResources
+	appliedConfigs map[string]map[string]bool // machine group => config names
+	dashboards     *fakeResources
+	savedSearches  *fakeResources
+	alerts         *fakeResources
+	storeViews     *fakeResources
+	metricConfigs  *fakeResources // by metric store
+	etlMetas       *fakeResources // by etlMetaName/etlMetaKey
+	tags           map[string]string
+	systemTags     map[string]map[string]string // tag owner uid => tags
+	etls           *fakeResources
+	scheduledSQLs  *fakeResources
+	ingestions     *fakeResources
+	exports        *fakeResources
+}
+
+type fakeLogStore struct {
+	meta           LogStore
+	meteringMode   string
+	index          []byte
+	shards         []*fakeShard
+	consumerGroups map[string]*fakeConsumerGroup
+	nextShard      int // shard to write to next if no hash key is given
+}
+
+type fakeShard struct {
+	meta   Shard
+	groups []fakeLogGroup
+}
+
+type fakeLogGroup struct {
+	receiveTime int64
+	data        []byte // marshaled LogGroup, so that callers never share it
+}
+
+type fakeConsumerGroup struct {
+	meta        ConsumerGroup
+	checkpoints map[int]*ConsumerGroupCheckPoint
+	assignment  *fakestore.Assignment
+}
+
+func newFakeError(httpCode int, code, format string, args ...interface{}) *Error {
+	return &Error{
+		HTTPCode: int32(httpCode),
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+func fakeNotSupported(operation string) error {
+	return newFakeError(http.StatusBadRequest, NOT_SUPPORTED, "FakeClient does not support %s", operation)
+}
+
+// fakePage returns the bounds of the page at offset of total items, size <= 0 means all of the rest.
+func fakePage(total, offset, size int) (begin, end int) {
+	if offset < 0 || offset > total {
+		offset = total
+	}
+	if size <= 0 || offset+size > total {
+		return offset, total
+	}
+	return offset, offset + size
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]*fakeProject:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*fakeLogStore:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]*fakeConsumerGroup:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]bool:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]string:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *FakeClient) getProject(name string) (*fakeProject, error) {
+	p, ok := c.projects[name]
+	if !ok {
+		return nil, newFakeError(http.StatusNotFound, PROJECT_NOT_EXIST, "project %s does not exist", name)
+	}
+	return p, nil
+}
+
+func (c *FakeClient) getLogStore(project, logstore string) (*fakeLogStore, error) {
+	p, err := c.getProject(project)
+	if err != nil {
+		return nil, err
+	}
+	ls, ok := p.logstores[logstore]
+	if !ok {
+		return nil, newFakeError(http.StatusNotFound, LOGSTORE_NOT_EXIST, "logstore %s does not exist", logstore)
+	}
+	return ls, nil
+}
+
+func (c *FakeClient) getShard(project, logstore string, shardID int) (*fakeShard, error) {
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	return ls.getShard(shardID)
+}
+
+// #################### Client Operations #####################
+
+// SetUserAgent does nothing, as no request is sent.
+func (c *FakeClient) SetUserAgent(userAgent string) {}
+
+// SetHTTPClient does nothing, as no request is sent.
+func (c *FakeClient) SetHTTPClient(client *http.Client) {}
+
+// SetRetryTimeout does nothing, as no request is sent.
+func (c *FakeClient) SetRetryTimeout(timeout time.Duration) {}
+
+// ResetAccessKeyToken does nothing, as requests are not authenticated.
+func (c *FakeClient) ResetAccessKeyToken(accessKeyID, accessKeySecret, securityToken string) {}
+
+// SetRegion does nothing, as requests are not signed.
+func (c *FakeClient) SetRegion(region string) {}
+
+// SetAuthVersion does nothing, as requests are not signed.
+func (c *FakeClient) SetAuthVersion(version AuthVersionType) {}
+
+// Close does nothing, the data is kept.
+func (c *FakeClient) Close() error {
+	return nil
+}
+
+// #################### Project Operations #####################
+
+func (c *FakeClient) CreateProject(name, description string) (*LogProject, error) {
+	return c.CreateProjectV2(name, description, "")
+}
+
+func (c *FakeClient) CreateProjectV2(name, description, dataRedundancyType string) (*LogProject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !projectNameRegexp.MatchString(name) {
+		return nil, newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "project name %s is invalid", name)
+	}
+	if _, ok := c.projects[name]; ok {
+		return nil, newFakeError(http.StatusBadRequest, PROJECT_ALREADY_EXIST, "project %s already exists", name)
+	}
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	p := &fakeProject{
+		meta: LogProject{
+			Name:               name,
+			Description:        description,
+			Status:             "Normal",
+			CreateTime:         now,
+			LastModifyTime:     now,
+			DataRedundancyType: dataRedundancyType,
+		},
+		logstores:      make(map[string]*fakeLogStore),
+		machineGroups:  newFakeResources("machine group", MACHINE_GROUP_NOT_EXIST, MACHINE_GROUP_ALREADY_EXIST),
+		configs:        newFakeResources("config", CONFIG_NOT_EXIST, CONFIG_ALREADY_EXIST),
+		appliedConfigs: make(map[string]map[string]bool),
+		dashboards:     newFakeResources("dashboard", DASHBOARD_NOT_EXIST, DASHBOARD_ALREADY_EXIST),
+		savedSearches:  newFakeResources("saved search", SAVED_SEARCH_NOT_EXIST, SAVED_SEARCH_ALREADY_EXIST),
+		alerts:         newFakeResources("alert", JOB_NOT_EXIST, JOB_ALREADY_EXIST),
+		storeViews:     newFakeResources("store view", "StoreViewNotExist", "StoreViewAlreadyExist"),
+		metricConfigs:  newFakeResources("metric config", "MetricConfigNotExist", "MetricConfigAlreadyExist"),
+		etlMetas:       newFakeResources("etl meta", "EtlMetaNotExist", "EtlMetaAlreadyExist"),
+		tags:           make(map[string]string),
+		systemTags:     make(map[string]map[string]string),
+		etls:           newFakeResources("etl", JOB_NOT_EXIST, JOB_ALREADY_EXIST),
+		scheduledSQLs:  newFakeResources("scheduled sql", JOB_NOT_EXIST, JOB_ALREADY_EXIST),
+		ingestions:     newFakeResources("ingestion", JOB_NOT_EXIST, JOB_ALREADY_EXIST),
+		exports:        newFakeResources("export", JOB_NOT_EXIST, JOB_ALREADY_EXIST),
+	}
+	c.projects[name] = p
+	project := p.meta
+	return &project, nil
+}
+
+func (c *FakeClient) GetProject(name string) (*LogProject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.getProject(name)
+	if err != nil {
+		return nil, err
+	}
+	project := p.meta
+	return &project, nil
+}
+
+func (c *FakeClient) UpdateProject(name, description string) (*LogProject, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.getProject(name)
+	if err != nil {
+		return nil, err
+	}
+	p.meta.Description = description
+	p.meta.LastModifyTime = strconv.FormatInt(time.Now().Unix(), 10)
+	project := p.meta
+	return &project, nil
+}
+
+func (c *FakeClient) ListProject() (projectNames []string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedKeys(c.projects), nil
+}
+
+func (c *FakeClient) ListProjectV2(offset, size int) (projects []LogProject, count, total int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	names := sortedKeys(c.projects)
+	begin, end := fakePage(len(names), offset, size)
+	for _, name := range names[begin:end] {
+		projects = append(projects, c.projects[name].meta)
+	}
+	return projects, len(projects), len(names), nil
+}
+
+func (c *FakeClient) CheckProjectExist(name string) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.projects[name]
+	return ok, nil
+}
+
+func (c *FakeClient) DeleteProject(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.getProject(name); err != nil {
+		return err
+	}
+	delete(c.projects, name)
+	return nil
+}
+
+// #################### Logstore Operations #####################
+
+func (c *FakeClient) ListLogStore(project string) ([]string, error) {
+	return c.ListLogStoreV2(project, 0, 0, "")
+}
+
+func (c *FakeClient) ListLogStoreV2(project string, offset, size int, telemetryType string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.getProject(project)
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, name := range sortedKeys(p.logstores) {
+		if telemetryType == "" || telemetryType == "None" || p.logstores[name].meta.TelemetryType == telemetryType {
+			names = append(names, name)
+		}
+	}
+	begin, end := fakePage(len(names), offset, size)
+	return names[begin:end], nil
+}
+
+func (c *FakeClient) GetLogStore(project string, logstore string) (*LogStore, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	meta := ls.meta
+	return &meta, nil
+}
+
+func (c *FakeClient) CreateLogStore(project string, logstore string, ttl, shardCnt int, autoSplit bool, maxSplitShard int) error {
+	return c.CreateLogStoreV2(project, &LogStore{
+		Name:          logstore,
+		TTL:           ttl,
+		ShardCount:    shardCnt,
+		AutoSplit:     autoSplit,
+		MaxSplitShard: maxSplitShard,
+	})
+}
+
+func (c *FakeClient) CreateLogStoreV2(project string, logstore *LogStore) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.getProject(project)
+	if err != nil {
+		return err
+	}
+	if !logstoreNameRegexp.MatchString(logstore.Name) {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "logstore name %s is invalid", logstore.Name)
+	}
+	if logstore.TTL <= 0 || logstore.TTL > 3650 {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "ttl %d is invalid", logstore.TTL)
+	}
+	if logstore.ShardCount <= 0 || logstore.ShardCount > 256 {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "shardCount %d is invalid", logstore.ShardCount)
+	}
+	if _, ok := p.logstores[logstore.Name]; ok {
+		return newFakeError(http.StatusBadRequest, LOGSTORE_ALREADY_EXIST, "logstore %s already exists", logstore.Name)
+	}
+	p.logstores[logstore.Name] = newFakeLogStore(*logstore)
+	return nil
+}
+
+func newFakeLogStore(meta LogStore) *fakeLogStore {
+	now := time.Now().Unix()
+	meta.CreateTime = uint32(now)
+	meta.LastModifyTime = uint32(now)
+	meta.project = nil
+	ls := &fakeLogStore{
+		meta:           meta,
+		meteringMode:   CHARGE_BY_FUNCTION,
+		consumerGroups: make(map[string]*fakeConsumerGroup),
+	}
+	ls.addShards(strings.Repeat("0", 32), fakestore.MaxHashKey, meta.ShardCount)
+	return ls
+}
+
+// addShards adds n readwrite shards splitting the hash key range [begin, end) evenly.
+func (ls *fakeLogStore) addShards(begin, end string, n int) []*fakeShard {
+	bounds := fakestore.SplitHashKeyRange(begin, end, n)
+	now := int(time.Now().Unix())
+	var shards []*fakeShard
+	for i := 0; i < n; i++ {
+		s := &fakeShard{meta: Shard{
+			ShardID:           len(ls.shards),
+			Status:            "readwrite",
+			InclusiveBeginKey: bounds[i],
+			ExclusiveBeginKey: bounds[i+1],
+			CreateTime:        now,
+		}}
+		ls.shards = append(ls.shards, s)
+		shards = append(shards, s)
+	}
+	return shards
+}
+
+func (ls *fakeLogStore) getShard(id int) (*fakeShard, error) {
+	if id < 0 || id >= len(ls.shards) {
+		return nil, newFakeError(http.StatusBadRequest, SHARD_NOT_EXIST, "shard %d does not exist", id)
+	}
+	return ls.shards[id], nil
+}
+
+func (ls *fakeLogStore) readWriteShards() []*fakeShard {
+	var shards []*fakeShard
+	for _, s := range ls.shards {
+		if s.meta.Status == "readwrite" {
+			shards = append(shards, s)
+		}
+	}
+	return shards
+}
+
+// routeShard returns the readwrite shard whose hash key range contains key, or the shards in turn if key is empty.
+func (ls *fakeLogStore) routeShard(key string) (*fakeShard, error) {
+	shards := ls.readWriteShards()
+	if key == "" {
+		s := shards[ls.nextShard%len(shards)]
+		ls.nextShard++
+		return s, nil
+	}
+	i := fakestore.RouteHashKey(key, len(shards), func(i int) (string, string) {
+		return shards[i].meta.InclusiveBeginKey, shards[i].meta.ExclusiveBeginKey
+	})
+	if i >= 0 {
+		return shards[i], nil
+	}
+	return nil, newFakeError(http.StatusBadRequest, INVALID_KEY, "invalid hash key %s", key)
+}
+
+func (c *FakeClient) DeleteLogStore(project string, logstore string) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.getLogStore(project, logstore); err != nil {
+		return err
+	}
+	delete(c.projects[project].logstores, logstore)
+	return nil
+}
+
+// UpdateLogStore updates the ttl of logstore, the shard count can only be changed by splitting or merging shards.
+func (c *FakeClient) UpdateLogStore(project string, logstore string, ttl, shardCnt int) (err error) {
+	meta, err := c.GetLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	meta.TTL = ttl
+	return c.UpdateLogStoreV2(project, meta)
+}
+
+func (c *FakeClient) UpdateLogStoreV2(project string, logstore *LogStore) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore.Name)
+	if err != nil {
+		return err
+	}
+	if logstore.TTL <= 0 || logstore.TTL > 3650 {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "ttl %d is invalid", logstore.TTL)
+	}
+	meta := *logstore
+	meta.project = nil
+	meta.ShardCount = ls.meta.ShardCount
+	meta.Mode = ls.meta.Mode
+	meta.CreateTime = ls.meta.CreateTime
+	meta.LastModifyTime = uint32(time.Now().Unix())
+	ls.meta = meta
+	return nil
+}
+
+func (c *FakeClient) CheckLogstoreExist(project string, logstore string) (bool, error) {
+	_, err := c.GetLogStore(project, logstore)
+	if err != nil {
+		if slsErr, ok := err.(*Error); ok && slsErr.Code == LOGSTORE_NOT_EXIST {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *FakeClient) GetLogStoreMeteringMode(project string, logstore string) (*GetMeteringModeResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	return &GetMeteringModeResponse{MeteringMode: ls.meteringMode}, nil
+}
+
+func (c *FakeClient) UpdateLogStoreMeteringMode(project string, logstore string, meteringMode string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	if meteringMode != CHARGE_BY_FUNCTION && meteringMode != CHARGE_BY_DATA_INGEST {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "meteringMode %s is invalid", meteringMode)
+	}
+	ls.meteringMode = meteringMode
+	return nil
+}
+
+// #################### MetricStore Operations #####################
+
+func (c *FakeClient) CreateMetricStore(project string, metricStore *LogStore) error {
+	metricStore.TelemetryType = "Metrics"
+	return c.CreateLogStoreV2(project, metricStore)
+}
+
+func (c *FakeClient) UpdateMetricStore(project string, metricStore *LogStore) error {
+	metricStore.TelemetryType = "Metrics"
+	return c.UpdateLogStoreV2(project, metricStore)
+}
+
+func (c *FakeClient) DeleteMetricStore(project, name string) error {
+	return c.DeleteLogStore(project, name)
+}
+
+func (c *FakeClient) GetMetricStore(project, name string) (*LogStore, error) {
+	return c.GetLogStore(project, name)
+}
+
+// #################### EventStore Operations #####################
+
+func (c *FakeClient) CreateEventStore(project string, eventStore *LogStore) error {
+	eventStore.TelemetryType = EventStoreTelemetryType
+	if err := c.CreateLogStoreV2(project, eventStore); err != nil {
+		return err
+	}
+	return c.CreateIndexString(project, eventStore.Name, EventStoreIndex)
+}
+
+func (c *FakeClient) UpdateEventStore(project string, eventStore *LogStore) error {
+	eventStore.TelemetryType = EventStoreTelemetryType
+	return c.UpdateLogStoreV2(project, eventStore)
+}
+
+func (c *FakeClient) DeleteEventStore(project, name string) error {
+	return c.DeleteLogStore(project, name)
+}
+
+func (c *FakeClient) GetEventStore(project, name string) (*LogStore, error) {
+	return c.GetLogStore(project, name)
+}
+
+func (c *FakeClient) ListEventStore(project string, offset, size int) ([]string, error) {
+	return c.ListLogStoreV2(project, offset, size, EventStoreTelemetryType)
+}
+
+// #################### Shard Operations #####################
+
+func (c *FakeClient) ListShards(project, logstore string) (shards []*Shard, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range ls.shards {
+		shard := s.meta
+		shards = append(shards, &shard)
+	}
+	return shards, nil
+}
+
+// SplitShard splits the readwrite shard at splitKey into two new shards, and the shard becomes readonly.
+func (c *FakeClient) SplitShard(project, logstore string, shardID int, splitKey string) (shards []*Shard, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, s, err := c.getReadWriteShard(project, logstore, shardID)
+	if err != nil {
+		return nil, err
+	}
+	splitKey = strings.ToLower(splitKey)
+	if _, ok := new(big.Int).SetString(splitKey, 16); !ok || len(splitKey) != 32 ||
+		splitKey <= s.meta.InclusiveBeginKey || splitKey >= s.meta.ExclusiveBeginKey {
+		return nil, newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "split key %s is invalid", splitKey)
+	}
+	s.meta.Status = "readonly"
+	newShards := append(ls.addShards(s.meta.InclusiveBeginKey, splitKey, 1), ls.addShards(splitKey, s.meta.ExclusiveBeginKey, 1)...)
+	return ls.splitResult(s, newShards), nil
+}
+
+// SplitNumShard splits the readwrite shard into shardsNum new shards evenly, and the shard becomes readonly.
+func (c *FakeClient) SplitNumShard(project, logstore string, shardID, shardsNum int) (shards []*Shard, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, s, err := c.getReadWriteShard(project, logstore, shardID)
+	if err != nil {
+		return nil, err
+	}
+	if shardsNum < 2 || shardsNum > 64 {
+		return nil, newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "shardCount %d is invalid", shardsNum)
+	}
+	s.meta.Status = "readonly"
+	newShards := ls.addShards(s.meta.InclusiveBeginKey, s.meta.ExclusiveBeginKey, shardsNum)
+	return ls.splitResult(s, newShards), nil
+}
+
+// MergeShards merges the readwrite shard with the readwrite shard next to it into a new shard,
+// and both of them become readonly.
+func (c *FakeClient) MergeShards(project, logstore string, shardID int) (shards []*Shard, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, s, err := c.getReadWriteShard(project, logstore, shardID)
+	if err != nil {
+		return nil, err
+	}
+	var next *fakeShard
+	for _, rw := range ls.readWriteShards() {
+		if rw.meta.InclusiveBeginKey == s.meta.ExclusiveBeginKey {
+			next = rw
+		}
+	}
+	if next == nil {
+		return nil, newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "shard %d has no adjacent readwrite shard to merge", shardID)
+	}
+	s.meta.Status = "readonly"
+	next.meta.Status = "readonly"
+	merged := ls.addShards(s.meta.InclusiveBeginKey, next.meta.ExclusiveBeginKey, 1)
+	return ls.splitResult(s, append(merged, next)), nil
+}
+
+func (c *FakeClient) getReadWriteShard(project, logstore string, shardID int) (*fakeLogStore, *fakeShard, error) {
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := ls.getShard(shardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if s.meta.Status != "readwrite" {
+		return nil, nil, newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "shard %d is not readwrite", shardID)
+	}
+	return ls, s, nil
+}
+
+func (ls *fakeLogStore) splitResult(s *fakeShard, shards []*fakeShard) []*Shard {
+	ls.meta.ShardCount = len(ls.readWriteShards())
+	result := []*Shard{}
+	for _, s := range append([]*fakeShard{s}, shards...) {
+		shard := s.meta
+		result = append(result, &shard)
+	}
+	return result
+}
+
+// #################### Log Operations #####################
+
+func (c *FakeClient) PutLogsWithMetricStoreURL(project, logstore string, lg *LogGroup) (err error) {
+	return c.PostLogStoreLogs(project, logstore, lg, nil)
+}
+
+func (c *FakeClient) PutLogs(project, logstore string, lg *LogGroup) (err error) {
+	return c.PostLogStoreLogs(project, logstore, lg, nil)
+}
+
+func (c *FakeClient) PostLogStoreLogs(project, logstore string, lg *LogGroup, hashKey *string) (err error) {
+	return c.PostLogStoreLogsV2(project, logstore, &PostLogStoreLogsRequest{LogGroup: lg, HashKey: hashKey})
+}
+
+func (c *FakeClient) PostLogStoreLogsV2(project, logstore string, req *PostLogStoreLogsRequest) (err error) {
+	if req.LogGroup == nil || len(req.LogGroup.Logs) == 0 {
+		// empty log group
+		return nil
+	}
+	if req.CompressType < 0 || req.CompressType >= Compress_Max {
+		return InvalidCompressError
+	}
+	data, err := proto.Marshal(req.LogGroup)
+	if err != nil {
+		return NewClientError(err)
+	}
+	var hashKey string
+	if req.HashKey != nil {
+		hashKey = *req.HashKey
+	}
+	return c.postLogs(project, logstore, data, hashKey)
+}
+
+func (c *FakeClient) PostRawLogWithCompressType(project, logstore string, rawLogData []byte, compressType int, hashKey *string) (err error) {
+	if len(rawLogData) == 0 {
+		return nil
+	}
+	if compressType < 0 || compressType >= Compress_Max {
+		return InvalidCompressError
+	}
+	var lg LogGroup
+	if err := proto.Unmarshal(rawLogData, &lg); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "fail to unmarshal log group: %v", err)
+	}
+	return c.PostLogStoreLogsV2(project, logstore, &PostLogStoreLogsRequest{LogGroup: &lg, HashKey: hashKey, CompressType: compressType})
+}
+
+func (c *FakeClient) PutLogsWithCompressType(project, logstore string, lg *LogGroup, compressType int) (err error) {
+	return c.PostLogStoreLogsV2(project, logstore, &PostLogStoreLogsRequest{LogGroup: lg, CompressType: compressType})
+}
+
+func (c *FakeClient) PutRawLogWithCompressType(project, logstore string, rawLogData []byte, compressType int) (err error) {
+	return c.PostRawLogWithCompressType(project, logstore, rawLogData, compressType, nil)
+}
+
+func (c *FakeClient) postLogs(project, logstore string, data []byte, hashKey string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	s, err := ls.routeShard(hashKey)
+	if err != nil {
+		return err
+	}
+	s.groups = append(s.groups, fakeLogGroup{receiveTime: time.Now().Unix(), data: data})
+	return nil
+}
+
+func (s *fakeShard) decodeCursor(cursor string) (int, error) {
+	if offset, ok := fakestore.DecodeCursor(cursor, len(s.groups)); ok {
+		return offset, nil
+	}
+	return 0, newFakeError(http.StatusBadRequest, INVALID_CURSOR, "cursor %s is invalid", cursor)
+}
+
+func (c *FakeClient) GetCursor(project, logstore string, shardID int, from string) (cursor string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.getShard(project, logstore, shardID)
+	if err != nil {
+		return "", err
+	}
+	cursor, ok := fakestore.CursorOf(from, len(s.groups), func(i int) int64 {
+		return s.groups[i].receiveTime
+	})
+	if !ok {
+		return "", newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "from %s is invalid", from)
+	}
+	return cursor, nil
+}
+
+// GetCursorTime returns the receive time of the log group at cursor,
+// or of the last log group if cursor is the end cursor.
+func (c *FakeClient) GetCursorTime(project, logstore string, shardID int, cursor string) (cursorTime time.Time, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.getShard(project, logstore, shardID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	offset, err := s.decodeCursor(cursor)
+	if err != nil {
+		return time.Time{}, err
+	}
+	switch {
+	case offset < len(s.groups):
+		return time.Unix(s.groups[offset].receiveTime, 0), nil
+	case len(s.groups) > 0:
+		return time.Unix(s.groups[len(s.groups)-1].receiveTime, 0), nil
+	}
+	return time.Unix(int64(s.meta.CreateTime), 0), nil
+}
+
+func (c *FakeClient) GetLogsBytes(project, logstore string, shardID int, cursor, endCursor string,
+	logGroupMaxCount int) (out []byte, nextCursor string, err error) {
+	return c.GetLogsBytesV2(&PullLogRequest{
+		Project:          project,
+		Logstore:         logstore,
+		ShardID:          shardID,
+		Cursor:           cursor,
+		EndCursor:        endCursor,
+		LogGroupMaxCount: logGroupMaxCount,
+	})
+}
+
+func (c *FakeClient) GetLogsBytesV2(plr *PullLogRequest) (out []byte, nextCursor string, err error) {
+	out, plm, err := c.GetLogsBytesWithQuery(plr)
+	if err != nil {
+		return nil, "", err
+	}
+	return out, plm.NextCursor, nil
+}
+
+// GetLogsBytesWithQuery returns the marshaled LogGroupList, uncompressed whatever the CompressType is.
+// Pulling with a query is not supported.
+func (c *FakeClient) GetLogsBytesWithQuery(plr *PullLogRequest) (out []byte, plm *PullLogMeta, err error) {
+	if plr.Query != "" {
+		return nil, nil, fakeNotSupported("pulling logs with query")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, err := c.getShard(plr.Project, plr.Logstore, plr.ShardID)
+	if err != nil {
+		return nil, nil, err
+	}
+	begin, err := s.decodeCursor(plr.Cursor)
+	if err != nil {
+		return nil, nil, err
+	}
+	end := len(s.groups)
+	if plr.EndCursor != "" {
+		if end, err = s.decodeCursor(plr.EndCursor); err != nil {
+			return nil, nil, err
+		}
+	}
+	if count := plr.LogGroupMaxCount; count > 0 && begin+count < end {
+		end = begin + count
+	}
+	if end < begin {
+		end = begin
+	}
+	// LogGroupList is a repeated LogGroup field, so it is the concatenation of the encoded log groups
+	for _, group := range s.groups[begin:end] {
+		out = append(out, proto.EncodeVarint(uint64(1<<3|proto.WireBytes))...)
+		out = append(out, proto.EncodeVarint(uint64(len(group.data)))...)
+		out = append(out, group.data...)
+	}
+	plm = &PullLogMeta{
+		NextCursor: fakestore.EncodeCursor(end),
+		Netflow:    len(out),
+		RawSize:    len(out),
+		Count:      end - begin,
+	}
+	return out, plm, nil
+}
+
+func (c *FakeClient) PullLogs(project, logstore string, shardID int, cursor, endCursor string,
+	logGroupMaxCount int) (gl *LogGroupList, nextCursor string, err error) {
+	return c.PullLogsV2(&PullLogRequest{
+		Project:          project,
+		Logstore:         logstore,
+		ShardID:          shardID,
+		Cursor:           cursor,
+		EndCursor:        endCursor,
+		LogGroupMaxCount: logGroupMaxCount,
+	})
+}
+
+func (c *FakeClient) PullLogsV2(plr *PullLogRequest) (gl *LogGroupList, nextCursor string, err error) {
+	gl, plm, err := c.PullLogsWithQuery(plr)
+	if err != nil {
+		return nil, "", err
+	}
+	return gl, plm.NextCursor, nil
+}
+
+func (c *FakeClient) PullLogsWithQuery(plr *PullLogRequest) (gl *LogGroupList, plm *PullLogMeta, err error) {
+	out, plm, err := c.GetLogsBytesWithQuery(plr)
+	if err != nil {
+		return nil, nil, err
+	}
+	gl = &LogGroupList{}
+	if err := proto.Unmarshal(out, gl); err != nil {
+		return nil, nil, NewClientError(err)
+	}
+	return gl, plm, nil
+}
+
+// queryLogs returns the logs received in [from, to) matching topic and query.
+//
+// Only keyword queries are supported: terms separated by spaces or "and", each of which is
+// "key: value" or a keyword contained in any value. "*" and "" match all logs.
+func (c *FakeClient) queryLogs(project, logstore, topic string, from, to int64, query string) ([]map[string]string, error) {
+	if strings.Contains(query, "|") {
+		return nil, fakeNotSupported("SQL analysis")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	logs := []map[string]string{}
+	for _, s := range ls.shards {
+		for _, group := range s.groups {
+			var lg LogGroup
+			if err := proto.Unmarshal(group.data, &lg); err != nil {
+				return nil, NewClientError(err)
+			}
+			if topic != "" && lg.GetTopic() != topic {
+				continue
+			}
+			for _, log := range lg.Logs {
+				t := int64(log.GetTime())
+				if t < from || t >= to {
+					continue
+				}
+				m := fakeLogToMap(&lg, log)
+				if fakestore.Matches(query, m) {
+					logs = append(logs, m)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+func fakeLogToMap(lg *LogGroup, log *Log) map[string]string {
+	m := map[string]string{
+		"__time__":   strconv.FormatUint(uint64(log.GetTime()), 10),
+		"__topic__":  lg.GetTopic(),
+		"__source__": lg.GetSource(),
+	}
+	for _, tag := range lg.LogTags {
+		m["__tag__:"+tag.GetKey()] = tag.GetValue()
+	}
+	for _, content := range log.Contents {
+		m[content.GetKey()] = content.GetValue()
+	}
+	return m
+}
+
+func (c *FakeClient) GetHistograms(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	return c.GetHistogramsV2(project, logstore, &GetHistogramRequest{
+		Topic: topic,
+		From:  from,
+		To:    to,
+		Query: queryExp,
+	})
+}
+
+// GetHistogramsV2 returns a single histogram of all the logs matching, the interval is ignored.
+func (c *FakeClient) GetHistogramsV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	logs, err := c.queryLogs(project, logstore, ghr.Topic, ghr.From, ghr.To, ghr.Query)
+	if err != nil {
+		return nil, err
+	}
+	count := int64(len(logs))
+	return &GetHistogramsResponse{
+		Progress: "Complete",
+		Count:    count,
+		Histograms: []SingleHistogram{
+			{Progress: "Complete", Count: count, From: ghr.From, To: ghr.To},
+		},
+	}, nil
+}
+
+func (c *FakeClient) GetLogs(project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	return c.GetLogsV2(project, logstore, &GetLogRequest{
+		Topic:   topic,
+		From:    from,
+		To:      to,
+		Query:   queryExp,
+		Lines:   maxLineNum,
+		Offset:  offset,
+		Reverse: reverse,
+	})
+}
+
+func (c *FakeClient) GetLogLines(project, logstore string, topic string, from int64, to int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
+	return c.GetLogLinesV2(project, logstore, &GetLogRequest{
+		Topic:   topic,
+		From:    from,
+		To:      to,
+		Query:   queryExp,
+		Lines:   maxLineNum,
+		Offset:  offset,
+		Reverse: reverse,
+	})
+}
+
+func (c *FakeClient) GetLogsByNano(project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	return c.GetLogsV2(project, logstore, &GetLogRequest{
+		Topic:      topic,
+		From:       fromInNs / 1e9,
+		To:         toInNs / 1e9,
+		FromNsPart: int32(fromInNs % 1e9),
+		ToNsPart:   int32(toInNs % 1e9),
+		Query:      queryExp,
+		Lines:      maxLineNum,
+		Offset:     offset,
+		Reverse:    reverse,
+	})
+}
+
+func (c *FakeClient) GetLogLinesByNano(project, logstore string, topic string, fromInNs int64, toInNs int64, queryExp string,
+	maxLineNum int64, offset int64, reverse bool) (*GetLogLinesResponse, error) {
+	return c.GetLogLinesV2(project, logstore, &GetLogRequest{
+		Topic:      topic,
+		From:       fromInNs / 1e9,
+		To:         toInNs / 1e9,
+		FromNsPart: int32(fromInNs % 1e9),
+		ToNsPart:   int32(toInNs % 1e9),
+		Query:      queryExp,
+		Lines:      maxLineNum,
+		Offset:     offset,
+		Reverse:    reverse,
+	})
+}
+
+func (c *FakeClient) GetLogsV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	v3Resp, err := c.GetLogsV3(project, logstore, req)
+	if err != nil {
+		return nil, err
+	}
+	return toLogRespV2(v3Resp, http.Header{})
+}
+
+func (c *FakeClient) GetLogLinesV2(project, logstore string, req *GetLogRequest) (*GetLogLinesResponse, error) {
+	v2Resp, err := c.GetLogsV2(project, logstore, req)
+	if err != nil {
+		return nil, err
+	}
+	lines := []json.RawMessage{}
+	for _, log := range v2Resp.Logs {
+		line, _ := json.Marshal(log)
+		lines = append(lines, line)
+	}
+	v2Resp.Logs = nil
+	return &GetLogLinesResponse{GetLogsResponse: *v2Resp, Lines: lines}, nil
+}
+
+// GetLogsV3 returns the logs matching the keyword query in [from, to), sorted by __time__.
+// SQL analysis is not supported.
+func (c *FakeClient) GetLogsV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	logs, err := c.queryLogs(project, logstore, req.Topic, req.From, req.To, req.Query)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(logs, func(i, j int) bool {
+		ti, _ := strconv.ParseInt(logs[i]["__time__"], 10, 64)
+		tj, _ := strconv.ParseInt(logs[j]["__time__"], 10, 64)
+		if req.Reverse {
+			return ti > tj
+		}
+		return ti < tj
+	})
+	lines := int(req.Lines)
+	if lines <= 0 {
+		lines = 100
+	}
+	begin, end := fakePage(len(logs), int(req.Offset), lines)
+	logs = logs[begin:end]
+	return &GetLogsV3Response{
+		Meta: GetLogsV3ResponseMeta{
+			Progress: "Complete",
+			Count:    int64(len(logs)),
+		},
+		Logs: logs,
+	}, nil
+}
+
+func (c *FakeClient) GetHistogramsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string) (*GetHistogramsResponse, error) {
+	return c.GetHistograms(project, logstore, topic, from, to, queryExp)
+}
+
+func (c *FakeClient) GetHistogramsToCompletedV2(project, logstore string, ghr *GetHistogramRequest) (*GetHistogramsResponse, error) {
+	return c.GetHistogramsV2(project, logstore, ghr)
+}
+
+func (c *FakeClient) GetLogsToCompleted(project, logstore string, topic string, from int64, to int64, queryExp string, maxLineNum int64, offset int64, reverse bool) (*GetLogsResponse, error) {
+	return c.GetLogs(project, logstore, topic, from, to, queryExp, maxLineNum, offset, reverse)
+}
+
+func (c *FakeClient) GetLogsToCompletedV2(project, logstore string, req *GetLogRequest) (*GetLogsResponse, error) {
+	return c.GetLogsV2(project, logstore, req)
+}
+
+func (c *FakeClient) GetLogsToCompletedV3(project, logstore string, req *GetLogRequest) (*GetLogsV3Response, error) {
+	return c.GetLogsV3(project, logstore, req)
+}
+
+// #################### Consumer Operations #####################
+
+func (ls *fakeLogStore) getConsumerGroup(name string) (*fakeConsumerGroup, error) {
+	cg, ok := ls.consumerGroups[name]
+	if !ok {
+		return nil, newFakeError(http.StatusNotFound, CONSUMER_GROUP_NOT_EXIST, "consumer group %s does not exist", name)
+	}
+	return cg, nil
+}
+
+func (c *FakeClient) CreateConsumerGroup(project, logstore string, cg ConsumerGroup) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	if cg.ConsumerGroupName == "" || cg.Timeout <= 0 {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "consumer group %s is invalid", cg.String())
+	}
+	if _, ok := ls.consumerGroups[cg.ConsumerGroupName]; ok {
+		return newFakeError(http.StatusBadRequest, CONSUMER_GROUP_ALREADY_EXIST, "consumer group %s already exists", cg.ConsumerGroupName)
+	}
+	ls.consumerGroups[cg.ConsumerGroupName] = &fakeConsumerGroup{
+		meta:        cg,
+		checkpoints: make(map[int]*ConsumerGroupCheckPoint),
+		assignment:  fakestore.NewAssignment(),
+	}
+	return nil
+}
+
+func (c *FakeClient) UpdateConsumerGroup(project, logstore string, cg ConsumerGroup) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	stored, err := ls.getConsumerGroup(cg.ConsumerGroupName)
+	if err != nil {
+		return err
+	}
+	if cg.Timeout <= 0 {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "consumer group %s is invalid", cg.String())
+	}
+	stored.meta = cg
+	return nil
+}
+
+func (c *FakeClient) DeleteConsumerGroup(project, logstore string, cgName string) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	if _, err := ls.getConsumerGroup(cgName); err != nil {
+		return err
+	}
+	delete(ls.consumerGroups, cgName)
+	return nil
+}
+
+func (c *FakeClient) ListConsumerGroup(project, logstore string) (cgList []*ConsumerGroup, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	cgList = []*ConsumerGroup{}
+	for _, name := range sortedKeys(ls.consumerGroups) {
+		cg := ls.consumerGroups[name].meta
+		cgList = append(cgList, &cg)
+	}
+	return cgList, nil
+}
+
+// HeartBeat records the heartbeat of consumer and returns the shards assigned to it.
+//
+// Shards of consumers missing heartbeats for the timeout of the consumer group are released,
+// and free shards are assigned to the consumer until it holds its even share of the shards.
+func (c *FakeClient) HeartBeat(project, logstore string, cgName, consumer string, heartBeatShardIDs []int) (shardIDs []int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	cg, err := ls.getConsumerGroup(cgName)
+	if err != nil {
+		return nil, err
+	}
+	timeout := time.Duration(cg.meta.Timeout) * time.Second
+	return cg.assignment.Heartbeat(consumer, len(ls.shards), timeout), nil
+}
+
+// UpdateCheckpoint saves the checkpoint of shard, which must be held by consumer unless forceSuccess.
+func (c *FakeClient) UpdateCheckpoint(project, logstore string, cgName string, consumer string, shardID int, checkpoint string, forceSuccess bool) (err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	cg, err := ls.getConsumerGroup(cgName)
+	if err != nil {
+		return err
+	}
+	s, err := ls.getShard(shardID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.decodeCursor(checkpoint); err != nil {
+		return err
+	}
+	if owner, _ := cg.assignment.Owner(shardID); !forceSuccess && owner != consumer {
+		return newFakeError(http.StatusBadRequest, CONSUMER_NOT_MATCH, "shard %d is held by consumer %s", shardID, owner)
+	}
+	cg.checkpoints[shardID] = &ConsumerGroupCheckPoint{
+		ShardID:    shardID,
+		CheckPoint: checkpoint,
+		UpdateTime: time.Now().UnixNano() / int64(time.Microsecond),
+		Consumer:   consumer,
+	}
+	return nil
+}
+
+func (c *FakeClient) GetCheckpoint(project, logstore string, cgName string) (checkPointList []*ConsumerGroupCheckPoint, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	cg, err := ls.getConsumerGroup(cgName)
+	if err != nil {
+		return nil, err
+	}
+	checkPointList = []*ConsumerGroupCheckPoint{}
+	for id := range ls.shards {
+		if checkpoint, ok := cg.checkpoints[id]; ok {
+			cp := *checkpoint
+			checkPointList = append(checkPointList, &cp)
+		}
+	}
+	return checkPointList, nil
+}
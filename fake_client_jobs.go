@@ -0,0 +1,336 @@
+package sls
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Jobs of the FakeClient are stored but never run, they are RUNNING or Enabled once created if no status is given.
+
+// jobInstanceNotExistError is returned for any job instance of an existing job, as jobs are never run.
+func jobInstanceNotExistError(jobName, instanceId string) error {
+	return newFakeError(http.StatusNotFound, "JobInstanceNotExist", "instance %s of job %s does not exist", instanceId, jobName)
+}
+
+// matchJob returns whether a job matches the name and display name filters, which match all if empty.
+func matchJob(name, displayName, nameFilter, displayNameFilter string) bool {
+	return strings.Contains(name, nameFilter) && strings.Contains(displayName, displayNameFilter)
+}
+
+// #################### ETL Operations #####################
+
+func (p *fakeProject) putETL(etljob ETL, create bool) error {
+	if etljob.Status == "" {
+		etljob.Status = "RUNNING"
+	}
+	return p.etls.putValue(etljob.Name, &etljob, create)
+}
+
+func (c *FakeClient) CreateETL(project string, etljob ETL) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putETL(etljob, true)
+	})
+}
+
+func (c *FakeClient) UpdateETL(project string, etljob ETL) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putETL(etljob, false)
+	})
+}
+
+func (c *FakeClient) GetETL(project string, etlName string) (ETLJob *ETL, err error) {
+	ETLJob = &ETL{}
+	err = c.withProject(project, func(p *fakeProject) error {
+		return p.etls.getValue(etlName, ETLJob)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ETLJob, nil
+}
+
+func (c *FakeClient) ListETL(project string, offset int, size int) (resp *ListETLResponse, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.etls.names("")
+		begin, end := fakePage(len(names), offset, size)
+		resp = &ListETLResponse{Total: len(names), Count: end - begin, Results: []*ETL{}}
+		for _, name := range names[begin:end] {
+			etljob := &ETL{}
+			if err := p.etls.getValue(name, etljob); err != nil {
+				return err
+			}
+			resp.Results = append(resp.Results, etljob)
+		}
+		return nil
+	})
+	return resp, err
+}
+
+func (c *FakeClient) DeleteETL(project string, etlName string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.etls.delete(etlName)
+	})
+}
+
+func (c *FakeClient) StartETL(project, name string) error {
+	return c.setETLStatus(project, name, "RUNNING")
+}
+
+func (c *FakeClient) StopETL(project, name string) error {
+	return c.setETLStatus(project, name, "STOPPED")
+}
+
+func (c *FakeClient) setETLStatus(project, name, status string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		var etljob ETL
+		if err := p.etls.getValue(name, &etljob); err != nil {
+			return err
+		}
+		etljob.Status = status
+		return p.putETL(etljob, false)
+	})
+}
+
+// RestartETL updates the etl job, which is running after restarted.
+func (c *FakeClient) RestartETL(project string, etljob ETL) error {
+	etljob.Status = "RUNNING"
+	return c.UpdateETL(project, etljob)
+}
+
+// #################### ScheduledSQL Operations #####################
+
+func (p *fakeProject) putScheduledSQL(scheduledsql *ScheduledSQL, create bool) error {
+	stored := *scheduledsql
+	if stored.Status == "" {
+		stored.Status = ENABLED
+	}
+	return p.scheduledSQLs.putValue(stored.Name, &stored, create)
+}
+
+func (c *FakeClient) CreateScheduledSQL(project string, scheduledsql *ScheduledSQL) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putScheduledSQL(scheduledsql, true)
+	})
+}
+
+func (c *FakeClient) DeleteScheduledSQL(project string, name string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.scheduledSQLs.delete(name)
+	})
+}
+
+func (c *FakeClient) UpdateScheduledSQL(project string, scheduledsql *ScheduledSQL) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putScheduledSQL(scheduledsql, false)
+	})
+}
+
+func (c *FakeClient) GetScheduledSQL(project string, name string) (*ScheduledSQL, error) {
+	scheduledsql := &ScheduledSQL{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.scheduledSQLs.getValue(name, scheduledsql)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return scheduledsql, nil
+}
+
+func (c *FakeClient) ListScheduledSQL(project, name, displayName string, offset, size int) (scheduledsqls []*ScheduledSQL, total, count int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		matched := []*ScheduledSQL{}
+		for _, jobName := range p.scheduledSQLs.names(name) {
+			scheduledsql := &ScheduledSQL{}
+			if err := p.scheduledSQLs.getValue(jobName, scheduledsql); err != nil {
+				return err
+			}
+			if matchJob(scheduledsql.Name, scheduledsql.DisplayName, name, displayName) {
+				matched = append(matched, scheduledsql)
+			}
+		}
+		begin, end := fakePage(len(matched), offset, size)
+		scheduledsqls, total = matched[begin:end], len(matched)
+		return nil
+	})
+	return scheduledsqls, total, len(scheduledsqls), err
+}
+
+func (c *FakeClient) GetScheduledSQLJobInstance(projectName, jobName, instanceId string, result bool) (instance *ScheduledSQLJobInstance, err error) {
+	err = c.withProject(projectName, func(p *fakeProject) error {
+		if _, err := p.scheduledSQLs.get(jobName); err != nil {
+			return err
+		}
+		return jobInstanceNotExistError(jobName, instanceId)
+	})
+	return nil, err
+}
+
+func (c *FakeClient) ModifyScheduledSQLJobInstanceState(projectName, jobName, instanceId string, state ScheduledSQLState) error {
+	if ScheduledSQL_RUNNING != state {
+		return NewClientError(errors.New(fmt.Sprintf("Invalid state: %s, state must be RUNNING.", state)))
+	}
+	return c.withProject(projectName, func(p *fakeProject) error {
+		if _, err := p.scheduledSQLs.get(jobName); err != nil {
+			return err
+		}
+		return jobInstanceNotExistError(jobName, instanceId)
+	})
+}
+
+// ListScheduledSQLJobInstances returns no instances of an existing job, as jobs are never run.
+func (c *FakeClient) ListScheduledSQLJobInstances(projectName, jobName string, status *InstanceStatus) (instances []*ScheduledSQLJobInstance, total, count int64, err error) {
+	err = c.withProject(projectName, func(p *fakeProject) error {
+		_, err := p.scheduledSQLs.get(jobName)
+		return err
+	})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	return []*ScheduledSQLJobInstance{}, 0, 0, nil
+}
+
+// #################### Ingestion #####################
+
+func (p *fakeProject) putIngestion(ingestion *Ingestion, create bool) error {
+	stored := *ingestion
+	if stored.Status == "" {
+		stored.Status = "RUNNING"
+	}
+	return p.ingestions.putValue(stored.Name, &stored, create)
+}
+
+func (c *FakeClient) CreateIngestion(project string, ingestion *Ingestion) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putIngestion(ingestion, true)
+	})
+}
+
+func (c *FakeClient) UpdateIngestion(project string, ingestion *Ingestion) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putIngestion(ingestion, false)
+	})
+}
+
+func (c *FakeClient) GetIngestion(project string, name string) (*Ingestion, error) {
+	ingestion := &Ingestion{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.ingestions.getValue(name, ingestion)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ingestion, nil
+}
+
+// ListIngestion returns the ingestions into logstore, or all logstores if empty, matching name and displayName.
+func (c *FakeClient) ListIngestion(project, logstore, name, displayName string, offset, size int) (ingestions []*Ingestion, total, count int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		matched := []*Ingestion{}
+		for _, jobName := range p.ingestions.names(name) {
+			ingestion := &Ingestion{}
+			if err := p.ingestions.getValue(jobName, ingestion); err != nil {
+				return err
+			}
+			if logstore != "" && (ingestion.IngestionConfiguration == nil || ingestion.IngestionConfiguration.LogStore != logstore) {
+				continue
+			}
+			if matchJob(ingestion.Name, ingestion.DisplayName, name, displayName) {
+				matched = append(matched, ingestion)
+			}
+		}
+		begin, end := fakePage(len(matched), offset, size)
+		ingestions, total = matched[begin:end], len(matched)
+		return nil
+	})
+	return ingestions, total, len(ingestions), err
+}
+
+func (c *FakeClient) DeleteIngestion(project string, name string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.ingestions.delete(name)
+	})
+}
+
+// #################### Export #####################
+
+func (p *fakeProject) putExport(export *Export, create bool) error {
+	stored := *export
+	if stored.Status == "" {
+		stored.Status = "RUNNING"
+	}
+	return p.exports.putValue(stored.Name, &stored, create)
+}
+
+func (c *FakeClient) CreateExport(project string, export *Export) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putExport(export, true)
+	})
+}
+
+func (c *FakeClient) UpdateExport(project string, export *Export) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putExport(export, false)
+	})
+}
+
+func (c *FakeClient) GetExport(project, name string) (*Export, error) {
+	export := &Export{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.exports.getValue(name, export)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// ListExport returns the exports of logstore, or all logstores if empty, matching name and displayName.
+func (c *FakeClient) ListExport(project, logstore, name, displayName string, offset, size int) (exports []*Export, total, count int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		matched := []*Export{}
+		for _, jobName := range p.exports.names(name) {
+			export := &Export{}
+			if err := p.exports.getValue(jobName, export); err != nil {
+				return err
+			}
+			if logstore != "" && (export.ExportConfiguration == nil || export.ExportConfiguration.LogStore != logstore) {
+				continue
+			}
+			if matchJob(export.Name, export.DisplayName, name, displayName) {
+				matched = append(matched, export)
+			}
+		}
+		begin, end := fakePage(len(matched), offset, size)
+		exports, total = matched[begin:end], len(matched)
+		return nil
+	})
+	return exports, total, len(exports), err
+}
+
+func (c *FakeClient) DeleteExport(project string, name string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.exports.delete(name)
+	})
+}
+
+// RestartExport updates the export, which is running after restarted.
+func (c *FakeClient) RestartExport(project string, export *Export) error {
+	stored := *export
+	stored.Status = "RUNNING"
+	return c.UpdateExport(project, &stored)
+}
+
+// #################### AlertPub Msg  #####################
+
+// PublishAlertEvent validates the events, which are dropped as no alert hub is modeled.
+func (c *FakeClient) PublishAlertEvent(project string, alertResult []byte) error {
+	if !json.Valid(alertResult) {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "alert event is not valid json")
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return nil
+	})
+}
@@ -0,0 +1,1227 @@
+package sls
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// fakeResources stores resources of a kind by name, as JSON so that callers never share them.
+type fakeResources struct {
+	kind         string
+	notExist     string // error code if the resource does not exist
+	alreadyExist string // error code if the resource already exists
+	items        map[string][]byte
+}
+
+func newFakeResources(kind, notExist, alreadyExist string) *fakeResources {
+	return &fakeResources{
+		kind:         kind,
+		notExist:     notExist,
+		alreadyExist: alreadyExist,
+		items:        make(map[string][]byte),
+	}
+}
+
+func (r *fakeResources) notExistError(name string) error {
+	return newFakeError(http.StatusNotFound, r.notExist, "%s %s does not exist", r.kind, name)
+}
+
+// put validates and stores data of resource name, which must or must not exist before as create.
+func (r *fakeResources) put(name string, data []byte, create bool) error {
+	if name == "" {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "%s name is empty", r.kind)
+	}
+	if !json.Valid(data) {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "%s %s is not valid json", r.kind, name)
+	}
+	_, ok := r.items[name]
+	if create && ok {
+		return newFakeError(http.StatusBadRequest, r.alreadyExist, "%s %s already exists", r.kind, name)
+	}
+	if !create && !ok {
+		return r.notExistError(name)
+	}
+	r.items[name] = data
+	return nil
+}
+
+// putValue is like put, but stores v marshaled.
+func (r *fakeResources) putValue(name string, v interface{}, create bool) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return NewClientError(err)
+	}
+	return r.put(name, data, create)
+}
+
+func (r *fakeResources) get(name string) ([]byte, error) {
+	data, ok := r.items[name]
+	if !ok {
+		return nil, r.notExistError(name)
+	}
+	return data, nil
+}
+
+// getValue unmarshals resource name into v.
+func (r *fakeResources) getValue(name string, v interface{}) error {
+	data, err := r.get(name)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return NewClientError(err)
+	}
+	return nil
+}
+
+func (r *fakeResources) delete(name string) error {
+	if _, ok := r.items[name]; !ok {
+		return r.notExistError(name)
+	}
+	delete(r.items, name)
+	return nil
+}
+
+// names returns the sorted names of resources containing substr.
+func (r *fakeResources) names(substr string) []string {
+	names := []string{}
+	for name := range r.items {
+		if strings.Contains(name, substr) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// withProject calls f with the project locked, or returns ProjectNotExist.
+func (c *FakeClient) withProject(project string, f func(p *fakeProject) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p, err := c.getProject(project)
+	if err != nil {
+		return err
+	}
+	return f(p)
+}
+
+// #################### Index Operations #####################
+
+func (c *FakeClient) CreateIndex(project, logstore string, index Index) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return NewClientError(err)
+	}
+	return c.putIndex(project, logstore, data, true)
+}
+
+func (c *FakeClient) CreateIndexString(project, logstore string, indexStr string) error {
+	return c.putIndex(project, logstore, []byte(indexStr), true)
+}
+
+func (c *FakeClient) UpdateIndex(project, logstore string, index Index) error {
+	data, err := json.Marshal(index)
+	if err != nil {
+		return NewClientError(err)
+	}
+	return c.putIndex(project, logstore, data, false)
+}
+
+func (c *FakeClient) UpdateIndexString(project, logstore string, indexStr string) error {
+	return c.putIndex(project, logstore, []byte(indexStr), false)
+}
+
+func (c *FakeClient) putIndex(project, logstore string, data []byte, create bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	if !json.Valid(data) {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "index of logstore %s is not valid json", logstore)
+	}
+	if create && ls.index != nil {
+		return newFakeError(http.StatusBadRequest, INDEX_ALREADY_EXIST, "index of logstore %s already exists", logstore)
+	}
+	if !create && ls.index == nil {
+		return newFakeError(http.StatusNotFound, INDEX_CONFIG_NOT_EXIST, "index of logstore %s does not exist", logstore)
+	}
+	ls.index = data
+	return nil
+}
+
+func (c *FakeClient) DeleteIndex(project, logstore string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return err
+	}
+	if ls.index == nil {
+		return newFakeError(http.StatusNotFound, INDEX_CONFIG_NOT_EXIST, "index of logstore %s does not exist", logstore)
+	}
+	ls.index = nil
+	return nil
+}
+
+func (c *FakeClient) GetIndex(project, logstore string) (*Index, error) {
+	data, err := c.GetIndexString(project, logstore)
+	if err != nil {
+		return nil, err
+	}
+	index := &Index{}
+	if err := json.Unmarshal([]byte(data), index); err != nil {
+		return nil, NewClientError(err)
+	}
+	return index, nil
+}
+
+func (c *FakeClient) GetIndexString(project, logstore string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ls, err := c.getLogStore(project, logstore)
+	if err != nil {
+		return "", err
+	}
+	if ls.index == nil {
+		return "", newFakeError(http.StatusNotFound, INDEX_CONFIG_NOT_EXIST, "index of logstore %s does not exist", logstore)
+	}
+	return string(ls.index), nil
+}
+
+// #################### Logtail Operations #####################
+
+func (c *FakeClient) ListMachineGroup(project string, offset, size int) (m []string, total int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.machineGroups.names("")
+		begin, end := fakePage(len(names), offset, size)
+		m, total = names[begin:end], len(names)
+		return nil
+	})
+	return m, total, err
+}
+
+// ListMachines returns no machines, as no logtail connects to the FakeClient.
+func (c *FakeClient) ListMachines(project, machineGroupName string) (ms []*Machine, total int, err error) {
+	return c.ListMachinesV2(project, machineGroupName, 0, 0)
+}
+
+func (c *FakeClient) ListMachinesV2(project, machineGroupName string, offset, size int) (ms []*Machine, total int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		_, err := p.machineGroups.get(machineGroupName)
+		return err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return []*Machine{}, 0, nil
+}
+
+func (c *FakeClient) CheckMachineGroupExist(project string, machineGroup string) (bool, error) {
+	return c.checkExist(project, func(p *fakeProject) *fakeResources { return p.machineGroups }, machineGroup)
+}
+
+// checkExist returns whether resource name exists, or the error if the project does not exist.
+func (c *FakeClient) checkExist(project string, resources func(p *fakeProject) *fakeResources, name string) (ok bool, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		_, ok = resources(p).items[name]
+		return nil
+	})
+	return ok, err
+}
+
+func (c *FakeClient) GetMachineGroup(project string, machineGroup string) (m *MachineGroup, err error) {
+	m = &MachineGroup{}
+	err = c.withProject(project, func(p *fakeProject) error {
+		return p.machineGroups.getValue(machineGroup, m)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *FakeClient) CreateMachineGroup(project string, m *MachineGroup) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.machineGroups.putValue(m.Name, m, true)
+	})
+}
+
+func (c *FakeClient) UpdateMachineGroup(project string, m *MachineGroup) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.machineGroups.putValue(m.Name, m, false)
+	})
+}
+
+func (c *FakeClient) DeleteMachineGroup(project string, machineGroup string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		if err := p.machineGroups.delete(machineGroup); err != nil {
+			return err
+		}
+		delete(p.appliedConfigs, machineGroup)
+		return nil
+	})
+}
+
+func (c *FakeClient) ListConfig(project string, offset, size int) (cfgNames []string, total int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.configs.names("")
+		begin, end := fakePage(len(names), offset, size)
+		cfgNames, total = names[begin:end], len(names)
+		return nil
+	})
+	return cfgNames, total, err
+}
+
+func (c *FakeClient) CheckConfigExist(project string, config string) (ok bool, err error) {
+	return c.checkExist(project, func(p *fakeProject) *fakeResources { return p.configs }, config)
+}
+
+func (c *FakeClient) GetConfig(project string, config string) (logConfig *LogConfig, err error) {
+	logConfig = &LogConfig{}
+	err = c.withProject(project, func(p *fakeProject) error {
+		return p.configs.getValue(config, logConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return logConfig, nil
+}
+
+func (c *FakeClient) GetConfigString(name string, config string) (s string, err error) {
+	err = c.withProject(name, func(p *fakeProject) error {
+		data, err := p.configs.get(config)
+		s = string(data)
+		return err
+	})
+	return s, err
+}
+
+func (c *FakeClient) UpdateConfig(project string, config *LogConfig) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.configs.putValue(config.Name, config, false)
+	})
+}
+
+func (c *FakeClient) UpdateConfigString(project string, configName, configDetail string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.configs.put(configName, []byte(configDetail), false)
+	})
+}
+
+func (c *FakeClient) CreateConfig(project string, config *LogConfig) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.configs.putValue(config.Name, config, true)
+	})
+}
+
+func (c *FakeClient) CreateConfigString(project string, config string) (err error) {
+	var logConfig LogConfig
+	if err := json.Unmarshal([]byte(config), &logConfig); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "config is not valid json: %v", err)
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.configs.put(logConfig.Name, []byte(config), true)
+	})
+}
+
+func (c *FakeClient) DeleteConfig(project string, config string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		if err := p.configs.delete(config); err != nil {
+			return err
+		}
+		for _, configs := range p.appliedConfigs {
+			delete(configs, config)
+		}
+		return nil
+	})
+}
+
+func (c *FakeClient) GetAppliedMachineGroups(project string, confName string) (groupNames []string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		if _, err := p.configs.get(confName); err != nil {
+			return err
+		}
+		groupNames = []string{}
+		for _, group := range p.machineGroups.names("") {
+			if p.appliedConfigs[group][confName] {
+				groupNames = append(groupNames, group)
+			}
+		}
+		return nil
+	})
+	return groupNames, err
+}
+
+func (c *FakeClient) GetAppliedConfigs(project string, groupName string) (confNames []string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		if _, err := p.machineGroups.get(groupName); err != nil {
+			return err
+		}
+		confNames = sortedKeys(p.appliedConfigs[groupName])
+		if confNames == nil {
+			confNames = []string{}
+		}
+		return nil
+	})
+	return confNames, err
+}
+
+func (c *FakeClient) ApplyConfigToMachineGroup(project string, confName, groupName string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		if _, err := p.machineGroups.get(groupName); err != nil {
+			return err
+		}
+		if _, err := p.configs.get(confName); err != nil {
+			return err
+		}
+		if p.appliedConfigs[groupName] == nil {
+			p.appliedConfigs[groupName] = make(map[string]bool)
+		}
+		p.appliedConfigs[groupName][confName] = true
+		return nil
+	})
+}
+
+func (c *FakeClient) RemoveConfigFromMachineGroup(project string, confName, groupName string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		if _, err := p.machineGroups.get(groupName); err != nil {
+			return err
+		}
+		if _, err := p.configs.get(confName); err != nil {
+			return err
+		}
+		delete(p.appliedConfigs[groupName], confName)
+		return nil
+	})
+}
+
+// #################### Chart&Dashboard Operations #####################
+
+func (c *FakeClient) ListDashboard(project string, dashboardName string, offset, size int) (dashboardList []string, count, total int, err error) {
+	dashboardList, _, count, total, err = c.ListDashboardV2(project, dashboardName, offset, size)
+	return dashboardList, count, total, err
+}
+
+func (c *FakeClient) ListDashboardV2(project string, dashboardName string, offset, size int) (dashboardList []string, dashboardItems []ResponseDashboardItem, count, total int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.dashboards.names(dashboardName)
+		begin, end := fakePage(len(names), offset, size)
+		dashboardList, total = names[begin:end], len(names)
+		dashboardItems = []ResponseDashboardItem{}
+		for _, name := range dashboardList {
+			var dashboard Dashboard
+			if err := p.dashboards.getValue(name, &dashboard); err != nil {
+				return err
+			}
+			dashboardItems = append(dashboardItems, ResponseDashboardItem{
+				DashboardName: dashboard.DashboardName,
+				DisplayName:   dashboard.DisplayName,
+			})
+		}
+		return nil
+	})
+	return dashboardList, dashboardItems, len(dashboardList), total, err
+}
+
+func (c *FakeClient) GetDashboard(project, name string) (dashboard *Dashboard, err error) {
+	dashboard = &Dashboard{}
+	err = c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.getValue(name, dashboard)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dashboard, nil
+}
+
+func (c *FakeClient) GetDashboardString(project, name string) (dashboard string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		data, err := p.dashboards.get(name)
+		dashboard = string(data)
+		return err
+	})
+	return dashboard, err
+}
+
+func (c *FakeClient) DeleteDashboard(project, name string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.delete(name)
+	})
+}
+
+func (c *FakeClient) UpdateDashboard(project string, dashboard Dashboard) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.putValue(dashboard.DashboardName, dashboard, false)
+	})
+}
+
+func (c *FakeClient) UpdateDashboardString(project string, dashboardName, dashboardStr string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.put(dashboardName, []byte(dashboardStr), false)
+	})
+}
+
+func (c *FakeClient) CreateDashboard(project string, dashboard Dashboard) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.putValue(dashboard.DashboardName, dashboard, true)
+	})
+}
+
+func (c *FakeClient) CreateDashboardString(project string, dashboardStr string) error {
+	var dashboard Dashboard
+	if err := json.Unmarshal([]byte(dashboardStr), &dashboard); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "dashboard is not valid json: %v", err)
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.dashboards.put(dashboard.DashboardName, []byte(dashboardStr), true)
+	})
+}
+
+// updateCharts calls f with the charts of the dashboard and the index of the chart titled chartName, or -1,
+// and saves the charts returned.
+func (c *FakeClient) updateCharts(project, dashboardName, chartName string, f func(charts []Chart, i int) ([]Chart, error)) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		var dashboard Dashboard
+		if err := p.dashboards.getValue(dashboardName, &dashboard); err != nil {
+			return err
+		}
+		i := -1
+		for j, chart := range dashboard.ChartList {
+			if chart.Title == chartName {
+				i = j
+			}
+		}
+		charts, err := f(dashboard.ChartList, i)
+		if err != nil {
+			return err
+		}
+		dashboard.ChartList = charts
+		return p.dashboards.putValue(dashboardName, dashboard, false)
+	})
+}
+
+func chartNotExistError(dashboardName, chartName string) error {
+	return newFakeError(http.StatusNotFound, CHART_NOT_EXIST, "chart %s does not exist in dashboard %s", chartName, dashboardName)
+}
+
+func (c *FakeClient) GetChart(project, dashboardName, chartName string) (chart *Chart, err error) {
+	err = c.updateCharts(project, dashboardName, chartName, func(charts []Chart, i int) ([]Chart, error) {
+		if i < 0 {
+			return nil, chartNotExistError(dashboardName, chartName)
+		}
+		chart = &charts[i]
+		return charts, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chart, nil
+}
+
+func (c *FakeClient) DeleteChart(project, dashboardName, chartName string) error {
+	return c.updateCharts(project, dashboardName, chartName, func(charts []Chart, i int) ([]Chart, error) {
+		if i < 0 {
+			return nil, chartNotExistError(dashboardName, chartName)
+		}
+		return append(charts[:i], charts[i+1:]...), nil
+	})
+}
+
+func (c *FakeClient) UpdateChart(project, dashboardName string, chart Chart) error {
+	return c.updateCharts(project, dashboardName, chart.Title, func(charts []Chart, i int) ([]Chart, error) {
+		if i < 0 {
+			return nil, chartNotExistError(dashboardName, chart.Title)
+		}
+		charts[i] = chart
+		return charts, nil
+	})
+}
+
+func (c *FakeClient) CreateChart(project, dashboardName string, chart Chart) error {
+	return c.updateCharts(project, dashboardName, chart.Title, func(charts []Chart, i int) ([]Chart, error) {
+		if i >= 0 {
+			return nil, newFakeError(http.StatusBadRequest, CHART_ALREADY_EXIST, "chart %s already exists in dashboard %s", chart.Title, dashboardName)
+		}
+		return append(charts, chart), nil
+	})
+}
+
+// #################### SavedSearch&Alert Operations #####################
+
+func (c *FakeClient) CreateSavedSearch(project string, savedSearch *SavedSearch) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.savedSearches.putValue(savedSearch.SavedSearchName, savedSearch, true)
+	})
+}
+
+func (c *FakeClient) UpdateSavedSearch(project string, savedSearch *SavedSearch) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.savedSearches.putValue(savedSearch.SavedSearchName, savedSearch, false)
+	})
+}
+
+func (c *FakeClient) DeleteSavedSearch(project string, savedSearchName string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.savedSearches.delete(savedSearchName)
+	})
+}
+
+func (c *FakeClient) GetSavedSearch(project string, savedSearchName string) (*SavedSearch, error) {
+	savedSearch := &SavedSearch{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.savedSearches.getValue(savedSearchName, savedSearch)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return savedSearch, nil
+}
+
+func (c *FakeClient) ListSavedSearch(project string, savedSearchName string, offset, size int) (savedSearches []string, total int, count int, err error) {
+	savedSearches, _, total, count, err = c.ListSavedSearchV2(project, savedSearchName, offset, size)
+	return savedSearches, total, count, err
+}
+
+func (c *FakeClient) ListSavedSearchV2(project string, savedSearchName string, offset, size int) (savedSearches []string, savedsearchItems []ResponseSavedSearchItem, total int, count int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.savedSearches.names(savedSearchName)
+		begin, end := fakePage(len(names), offset, size)
+		savedSearches, total = names[begin:end], len(names)
+		savedsearchItems = []ResponseSavedSearchItem{}
+		for _, name := range savedSearches {
+			var savedSearch SavedSearch
+			if err := p.savedSearches.getValue(name, &savedSearch); err != nil {
+				return err
+			}
+			savedsearchItems = append(savedsearchItems, ResponseSavedSearchItem{
+				SavedSearchName: savedSearch.SavedSearchName,
+				DisplayName:     savedSearch.DisplayName,
+			})
+		}
+		return nil
+	})
+	return savedSearches, savedsearchItems, total, len(savedSearches), err
+}
+
+// putAlert stores alert, which is enabled if the status is not given.
+func (p *fakeProject) putAlert(alert *Alert, create bool) error {
+	stored := *alert
+	if stored.Status == "" {
+		stored.Status = "ENABLED"
+	}
+	return p.alerts.putValue(stored.Name, &stored, create)
+}
+
+func (c *FakeClient) CreateAlert(project string, alert *Alert) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putAlert(alert, true)
+	})
+}
+
+func (c *FakeClient) UpdateAlert(project string, alert *Alert) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.putAlert(alert, false)
+	})
+}
+
+func (c *FakeClient) DeleteAlert(project string, alertName string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.alerts.delete(alertName)
+	})
+}
+
+func (c *FakeClient) GetAlert(project string, alertName string) (*Alert, error) {
+	alert := &Alert{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.alerts.getValue(alertName, alert)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alert, nil
+}
+
+func (c *FakeClient) DisableAlert(project string, alertName string) error {
+	return c.setAlertStatus(project, alertName, "DISABLED")
+}
+
+func (c *FakeClient) EnableAlert(project string, alertName string) error {
+	return c.setAlertStatus(project, alertName, "ENABLED")
+}
+
+func (c *FakeClient) setAlertStatus(project, alertName, status string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		var alert Alert
+		if err := p.alerts.getValue(alertName, &alert); err != nil {
+			return err
+		}
+		alert.Status = status
+		return p.putAlert(&alert, false)
+	})
+}
+
+// ListAlert returns the alerts whose name contains alertName, and whose dashboard is dashboard if given.
+func (c *FakeClient) ListAlert(project, alertName, dashboard string, offset, size int) (alerts []*Alert, total int, count int, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		matched := []*Alert{}
+		for _, name := range p.alerts.names(alertName) {
+			alert := &Alert{}
+			if err := p.alerts.getValue(name, alert); err != nil {
+				return err
+			}
+			if dashboard == "" || (alert.Configuration != nil && alert.Configuration.Dashboard == dashboard) {
+				matched = append(matched, alert)
+			}
+		}
+		begin, end := fakePage(len(matched), offset, size)
+		alerts, total = matched[begin:end], len(matched)
+		return nil
+	})
+	return alerts, total, len(alerts), err
+}
+
+func (c *FakeClient) CreateAlertString(project string, alert string) error {
+	var a Alert
+	if err := json.Unmarshal([]byte(alert), &a); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "alert is not valid json: %v", err)
+	}
+	return c.CreateAlert(project, &a)
+}
+
+func (c *FakeClient) UpdateAlertString(project string, alertName, alert string) error {
+	var a Alert
+	if err := json.Unmarshal([]byte(alert), &a); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "alert is not valid json: %v", err)
+	}
+	a.Name = alertName
+	return c.UpdateAlert(project, &a)
+}
+
+func (c *FakeClient) GetAlertString(project string, alertName string) (alert string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		data, err := p.alerts.get(alertName)
+		alert = string(data)
+		return err
+	})
+	return alert, err
+}
+
+// #################### Project Policy Operations #####################
+
+func (c *FakeClient) UpdateProjectPolicy(project string, policy string) error {
+	if !json.Valid([]byte(policy)) {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "policy is not valid json")
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		p.policy = policy
+		return nil
+	})
+}
+
+func (c *FakeClient) DeleteProjectPolicy(project string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		p.policy = ""
+		return nil
+	})
+}
+
+func (c *FakeClient) GetProjectPolicy(project string) (policy string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		policy = p.policy
+		return nil
+	})
+	return policy, err
+}
+
+// #################### StoreView Operations #####################
+
+func validateFakeStoreView(storeView *StoreView) error {
+	if storeView.StoreType != STORE_VIEW_STORE_TYPE_LOGSTORE && storeView.StoreType != STORE_VIEW_STORE_TYPE_METRICSTORE {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "store type %s of store view %s is invalid", storeView.StoreType, storeView.Name)
+	}
+	return nil
+}
+
+func (c *FakeClient) CreateStoreView(project string, storeView *StoreView) error {
+	if err := validateFakeStoreView(storeView); err != nil {
+		return err
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.storeViews.putValue(storeView.Name, storeView, true)
+	})
+}
+
+func (c *FakeClient) UpdateStoreView(project string, storeView *StoreView) error {
+	if err := validateFakeStoreView(storeView); err != nil {
+		return err
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.storeViews.putValue(storeView.Name, storeView, false)
+	})
+}
+
+func (c *FakeClient) DeleteStoreView(project string, storeViewName string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.storeViews.delete(storeViewName)
+	})
+}
+
+func (c *FakeClient) GetStoreView(project string, storeViewName string) (*StoreView, error) {
+	storeView := &StoreView{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.storeViews.getValue(storeViewName, storeView)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return storeView, nil
+}
+
+func (c *FakeClient) ListStoreViews(project string, req *ListStoreViewsRequest) (resp *ListStoreViewsResponse, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		names := p.storeViews.names("")
+		begin, end := fakePage(len(names), req.Offset, req.Size)
+		resp = &ListStoreViewsResponse{Total: len(names), Count: end - begin, StoreViews: names[begin:end]}
+		return nil
+	})
+	return resp, err
+}
+
+// GetStoreViewIndex returns the indexes of the stores of the store view, and an error for each store
+// missing or without index.
+func (c *FakeClient) GetStoreViewIndex(project string, storeViewName string) (resp *GetStoreViewIndexResponse, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		var storeView StoreView
+		if err := p.storeViews.getValue(storeViewName, &storeView); err != nil {
+			return err
+		}
+		resp = &GetStoreViewIndexResponse{Indexes: []*StoreViewIndex{}, StoreViewErrors: []*StoreViewErrors{}}
+		for _, store := range storeView.Stores {
+			ls, err := c.getLogStore(store.Project, store.StoreName)
+			if err == nil && ls.index == nil {
+				err = newFakeError(http.StatusNotFound, INDEX_CONFIG_NOT_EXIST, "index of logstore %s does not exist", store.StoreName)
+			}
+			var index Index
+			if err == nil {
+				if jsonErr := json.Unmarshal(ls.index, &index); jsonErr != nil {
+					err = NewClientError(jsonErr)
+				}
+			}
+			if err != nil {
+				resp.StoreViewErrors = append(resp.StoreViewErrors, &StoreViewErrors{
+					ProjectName: store.Project,
+					LogStore:    store.StoreName,
+					Status:      "Error",
+					Message:     err.Error(),
+				})
+				continue
+			}
+			resp.Indexes = append(resp.Indexes, &StoreViewIndex{ProjectName: store.Project, LogStore: store.StoreName, Index: index})
+		}
+		return nil
+	})
+	return resp, err
+}
+
+// #################### MetricConfig Operations #####################
+
+func (c *FakeClient) putMetricConfig(project, metricStore string, metricConfig *MetricsConfig, create bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := c.getLogStore(project, metricStore); err != nil {
+		return err
+	}
+	return c.projects[project].metricConfigs.putValue(metricStore, metricConfig, create)
+}
+
+func (c *FakeClient) CreateMetricConfig(project string, metricStore string, metricConfig *MetricsConfig) error {
+	return c.putMetricConfig(project, metricStore, metricConfig, true)
+}
+
+func (c *FakeClient) DeleteMetricConfig(project string, metricStore string) error {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.metricConfigs.delete(metricStore)
+	})
+}
+
+func (c *FakeClient) UpdateMetricConfig(project string, metricStore string, metricConfig *MetricsConfig) error {
+	return c.putMetricConfig(project, metricStore, metricConfig, false)
+}
+
+func (c *FakeClient) GetMetricConfig(project string, metricStore string) (*MetricsConfig, error) {
+	metricConfig := &MetricsConfig{}
+	err := c.withProject(project, func(p *fakeProject) error {
+		return p.metricConfigs.getValue(metricStore, metricConfig)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metricConfig, nil
+}
+
+// #################### EtlMeta Operations #####################
+
+func fakeEtlMetaID(etlMetaName, etlMetaKey string) string {
+	return etlMetaName + "/" + etlMetaKey
+}
+
+func (c *FakeClient) putEtlMeta(project string, etlMeta *EtlMeta, create bool) error {
+	if etlMeta.MetaName == "" || etlMeta.MetaKey == "" {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "etlMetaName and etlMetaKey must not be empty")
+	}
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.etlMetas.putValue(fakeEtlMetaID(etlMeta.MetaName, etlMeta.MetaKey), etlMeta, create)
+	})
+}
+
+func (c *FakeClient) CreateEtlMeta(project string, etlMeta *EtlMeta) (err error) {
+	return c.putEtlMeta(project, etlMeta, true)
+}
+
+func (c *FakeClient) UpdateEtlMeta(project string, etlMeta *EtlMeta) (err error) {
+	return c.putEtlMeta(project, etlMeta, false)
+}
+
+func (c *FakeClient) DeleteEtlMeta(project string, etlMetaName, etlMetaKey string) (err error) {
+	return c.withProject(project, func(p *fakeProject) error {
+		return p.etlMetas.delete(fakeEtlMetaID(etlMetaName, etlMetaKey))
+	})
+}
+
+func (c *FakeClient) GetEtlMeta(project string, etlMetaName, etlMetaKey string) (etlMeta *EtlMeta, err error) {
+	etlMeta = &EtlMeta{}
+	err = c.withProject(project, func(p *fakeProject) error {
+		return p.etlMetas.getValue(fakeEtlMetaID(etlMetaName, etlMetaKey), etlMeta)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return etlMeta, nil
+}
+
+func (c *FakeClient) ListEtlMeta(project string, etlMetaName string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
+	return c.ListEtlMetaWithTag(project, etlMetaName, EtlMetaAllTagMatch, offset, size)
+}
+
+func (c *FakeClient) ListEtlMetaWithTag(project string, etlMetaName, etlMetaTag string, offset, size int) (total int, count int, etlMetaList []*EtlMeta, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		matched := []*EtlMeta{}
+		for _, id := range p.etlMetas.names(fakeEtlMetaID(etlMetaName, "")) {
+			etlMeta := &EtlMeta{}
+			if err := p.etlMetas.getValue(id, etlMeta); err != nil {
+				return err
+			}
+			if etlMeta.MetaName == etlMetaName && (etlMetaTag == EtlMetaAllTagMatch || etlMeta.MetaTag == etlMetaTag) {
+				matched = append(matched, etlMeta)
+			}
+		}
+		begin, end := fakePage(len(matched), offset, size)
+		etlMetaList, total = matched[begin:end], len(matched)
+		return nil
+	})
+	return total, len(etlMetaList), etlMetaList, err
+}
+
+func (c *FakeClient) ListEtlMetaName(project string, offset, size int) (total int, count int, etlMetaNameList []string, err error) {
+	err = c.withProject(project, func(p *fakeProject) error {
+		nameSet := make(map[string]bool)
+		for _, id := range p.etlMetas.names("") {
+			var etlMeta EtlMeta
+			if err := p.etlMetas.getValue(id, &etlMeta); err != nil {
+				return err
+			}
+			nameSet[etlMeta.MetaName] = true
+		}
+		names := append([]string{}, sortedKeys(nameSet)...)
+		begin, end := fakePage(len(names), offset, size)
+		etlMetaNameList, total = names[begin:end], len(names)
+		return nil
+	})
+	return total, len(etlMetaNameList), etlMetaNameList, err
+}
+
+// ####################### Resource Tags API ######################
+
+// withTaggedProjects calls f with each project of resourceIDs, only projects can be tagged.
+func (c *FakeClient) withTaggedProjects(resourceType string, resourceIDs []string, f func(p *fakeProject)) error {
+	if resourceType != "project" {
+		return newFakeError(http.StatusBadRequest, PARAMETER_INVALID, "resource type %s is not supported", resourceType)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, id := range resourceIDs {
+		if _, err := c.getProject(id); err != nil {
+			return err
+		}
+	}
+	for _, id := range resourceIDs {
+		f(c.projects[id])
+	}
+	return nil
+}
+
+func (c *FakeClient) TagResources(project string, tags *ResourceTags) error {
+	return c.withTaggedProjects(tags.ResourceType, tags.ResourceID, func(p *fakeProject) {
+		for _, tag := range tags.Tags {
+			p.tags[tag.Key] = tag.Value
+		}
+	})
+}
+
+func (c *FakeClient) UnTagResources(project string, tags *ResourceUnTags) error {
+	return c.withTaggedProjects(tags.ResourceType, tags.ResourceID, func(p *fakeProject) {
+		if tags.All {
+			p.tags = make(map[string]string)
+		}
+		for _, key := range tags.Tags {
+			delete(p.tags, key)
+		}
+	})
+}
+
+func (c *FakeClient) TagResourcesSystemTags(project string, tags *ResourceSystemTags) error {
+	return c.withTaggedProjects(tags.ResourceType, tags.ResourceID, func(p *fakeProject) {
+		if p.systemTags[tags.TagOwnerUid] == nil {
+			p.systemTags[tags.TagOwnerUid] = make(map[string]string)
+		}
+		for _, tag := range tags.Tags {
+			p.systemTags[tags.TagOwnerUid][tag.Key] = tag.Value
+		}
+	})
+}
+
+func (c *FakeClient) UnTagResourcesSystemTags(project string, tags *ResourceUnSystemTags) error {
+	return c.withTaggedProjects(tags.ResourceType, tags.ResourceID, func(p *fakeProject) {
+		if tags.All {
+			delete(p.systemTags, tags.TagOwnerUid)
+		}
+		for _, key := range tags.Tags {
+			delete(p.systemTags[tags.TagOwnerUid], key)
+		}
+	})
+}
+
+// ListTagResources returns the tags of the projects of resourceIDs, or all projects if empty, which have
+// all of the filter tags. All of the tags are returned in one page.
+func (c *FakeClient) ListTagResources(project string,
+	resourceType string,
+	resourceIDs []string,
+	tags []ResourceFilterTag,
+	nextToken string) (respTags []*ResourceTagResponse, respNextToken string, err error) {
+	respTags, err = c.listTags(resourceType, resourceIDs, tags, func(p *fakeProject) map[string]string {
+		return p.tags
+	})
+	return respTags, "", err
+}
+
+func (c *FakeClient) ListSystemTagResources(project string,
+	resourceType string,
+	resourceIDs []string,
+	tags []ResourceFilterTag,
+	tagOwnerUid string,
+	category string,
+	scope string,
+	nextToken string) (respTags []*ResourceTagResponse, respNextToken string, err error) {
+	respTags, err = c.listTags(resourceType, resourceIDs, tags, func(p *fakeProject) map[string]string {
+		return p.systemTags[tagOwnerUid]
+	})
+	return respTags, "", err
+}
+
+func (c *FakeClient) listTags(resourceType string, resourceIDs []string, filters []ResourceFilterTag,
+	tagsOf func(p *fakeProject) map[string]string) ([]*ResourceTagResponse, error) {
+	if len(resourceIDs) == 0 {
+		c.mu.Lock()
+		resourceIDs = sortedKeys(c.projects)
+		c.mu.Unlock()
+	}
+	respTags := []*ResourceTagResponse{}
+	err := c.withTaggedProjects(resourceType, resourceIDs, func(p *fakeProject) {
+		tags := tagsOf(p)
+		for _, filter := range filters {
+			if v, ok := tags[*filter.Key]; !ok || (filter.Value != nil && *filter.Value != v) {
+				return
+			}
+		}
+		for _, key := range sortedKeys(tags) {
+			respTags = append(respTags, &ResourceTagResponse{
+				ResourceType: resourceType,
+				ResourceID:   p.meta.Name,
+				TagKey:       key,
+				TagValue:     tags[key],
+			})
+		}
+	})
+	return respTags, err
+}
+
+// #################### Resource Operations #####################
+
+func (c *FakeClient) ListResource(resourceType string, resourceName string, offset, size int) (resourceList []*Resource, count, total int, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	matched := []*Resource{}
+	for _, name := range c.resources.names(resourceName) {
+		resource := &Resource{}
+		if err := c.resources.getValue(name, resource); err != nil {
+			return nil, 0, 0, err
+		}
+		if resourceType == "" || resource.Type == resourceType {
+			matched = append(matched, resource)
+		}
+	}
+	begin, end := fakePage(len(matched), offset, size)
+	return matched[begin:end], end - begin, len(matched), nil
+}
+
+func (c *FakeClient) GetResource(name string) (resource *Resource, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	resource = &Resource{}
+	if err := c.resources.getValue(name, resource); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+func (c *FakeClient) GetResourceString(name string) (resource string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	data, err := c.resources.get(name)
+	return string(data), err
+}
+
+// DeleteResource deletes the resource with its records.
+func (c *FakeClient) DeleteResource(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.resources.delete(name); err != nil {
+		return err
+	}
+	delete(c.resourceRecords, name)
+	return nil
+}
+
+func (c *FakeClient) UpdateResource(resource *Resource) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resources.putValue(resource.Name, resource, false)
+}
+
+func (c *FakeClient) UpdateResourceString(resourceName, resourceStr string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.resources.put(resourceName, []byte(resourceStr), false)
+}
+
+func (c *FakeClient) CreateResource(resource *Resource) error {
+	data, err := json.Marshal(resource)
+	if err != nil {
+		return NewClientError(err)
+	}
+	return c.CreateResourceString(string(data))
+}
+
+func (c *FakeClient) CreateResourceString(resourceStr string) error {
+	var resource Resource
+	if err := json.Unmarshal([]byte(resourceStr), &resource); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "resource is not valid json: %v", err)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.resources.put(resource.Name, []byte(resourceStr), true); err != nil {
+		return err
+	}
+	c.resourceRecords[resource.Name] = newFakeResources("resource record", "ResourceRecordNotExist", "ResourceRecordAlreadyExist")
+	return nil
+}
+
+// #################### Resource Record Operations #####################
+
+// withResourceRecords calls f with the records of the resource locked, or returns ResourceNotExist.
+func (c *FakeClient) withResourceRecords(resourceName string, f func(records *fakeResources) error) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	records, ok := c.resourceRecords[resourceName]
+	if !ok {
+		return c.resources.notExistError(resourceName)
+	}
+	return f(records)
+}
+
+func (c *FakeClient) ListResourceRecord(resourceName string, offset, size int) (recordList []*ResourceRecord, count, total int, err error) {
+	err = c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		ids := records.names("")
+		begin, end := fakePage(len(ids), offset, size)
+		recordList, total = []*ResourceRecord{}, len(ids)
+		for _, id := range ids[begin:end] {
+			record := &ResourceRecord{}
+			if err := records.getValue(id, record); err != nil {
+				return err
+			}
+			recordList = append(recordList, record)
+		}
+		return nil
+	})
+	return recordList, len(recordList), total, err
+}
+
+func (c *FakeClient) GetResourceRecord(resourceName, recordId string) (record *ResourceRecord, err error) {
+	record = &ResourceRecord{}
+	err = c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		return records.getValue(recordId, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func (c *FakeClient) GetResourceRecordString(resourceName, name string) (record string, err error) {
+	err = c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		data, err := records.get(name)
+		record = string(data)
+		return err
+	})
+	return record, err
+}
+
+func (c *FakeClient) DeleteResourceRecord(resourceName, recordId string) error {
+	return c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		return records.delete(recordId)
+	})
+}
+
+func (c *FakeClient) UpdateResourceRecord(resourceName string, record *ResourceRecord) error {
+	return c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		return records.putValue(record.Id, record, false)
+	})
+}
+
+func (c *FakeClient) UpdateResourceRecordString(resourceName, recordStr string) error {
+	return c.putResourceRecordString(resourceName, recordStr, false)
+}
+
+func (c *FakeClient) CreateResourceRecord(resourceName string, record *ResourceRecord) error {
+	return c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		return records.putValue(record.Id, record, true)
+	})
+}
+
+func (c *FakeClient) CreateResourceRecordString(resourceName, recordStr string) error {
+	return c.putResourceRecordString(resourceName, recordStr, true)
+}
+
+func (c *FakeClient) putResourceRecordString(resourceName, recordStr string, create bool) error {
+	var record ResourceRecord
+	if err := json.Unmarshal([]byte(recordStr), &record); err != nil {
+		return newFakeError(http.StatusBadRequest, POST_BODY_INVALID, "resource record is not valid json: %v", err)
+	}
+	return c.withResourceRecords(resourceName, func(records *fakeResources) error {
+		return records.put(record.Id, []byte(recordStr), create)
+	})
+}
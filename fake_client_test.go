@@ -0,0 +1,275 @@
+package sls
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aliyun/aliyun-log-go-sdk/internal/fakestore"
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestFakeClient(t *testing.T) *FakeClient {
+	client := NewFakeClient()
+	_, err := client.CreateProject("test-project", "test")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 2, false, 0))
+	return client
+}
+
+func TestFakeClientMeta(t *testing.T) {
+	client := newTestFakeClient(t)
+
+	_, err := client.CreateProject("test-project", "")
+	require.Equal(t, PROJECT_ALREADY_EXIST, err.(*Error).Code)
+	_, err = client.CreateProject("Invalid_Project", "")
+	require.ErrorIs(t, err, ErrInvalidParameter)
+	_, err = client.GetProject("not-exist")
+	require.ErrorIs(t, err, ErrProjectNotExist)
+	exist, err := client.CheckProjectExist("not-exist")
+	require.NoError(t, err)
+	require.False(t, exist)
+
+	err = client.CreateLogStore("test-project", "test-logstore", 1, 2, false, 0)
+	require.ErrorIs(t, err, ErrLogStoreAlreadyExist)
+	err = client.CreateLogStore("test-project", "other-logstore", 0, 2, false, 0)
+	require.ErrorIs(t, err, ErrInvalidParameter)
+	require.NoError(t, client.UpdateLogStore("test-project", "test-logstore", 7, 4))
+	logstore, err := client.GetLogStore("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Equal(t, 7, logstore.TTL)
+	require.Equal(t, 2, logstore.ShardCount)
+	exist, err = client.CheckLogstoreExist("test-project", "not-exist")
+	require.NoError(t, err)
+	require.False(t, exist)
+	_, err = client.CheckLogstoreExist("not-exist", "test-logstore")
+	require.ErrorIs(t, err, ErrProjectNotExist)
+
+	_, err = client.GetIndex("test-project", "test-logstore")
+	require.Equal(t, INDEX_CONFIG_NOT_EXIST, err.(*Error).Code)
+	require.NoError(t, client.CreateIndex("test-project", "test-logstore", Index{Line: &IndexLine{Token: []string{" "}}}))
+	index, err := client.GetIndex("test-project", "test-logstore")
+	require.NoError(t, err)
+	require.Equal(t, []string{" "}, index.Line.Token)
+
+	require.NoError(t, client.CreateMachineGroup("test-project", &MachineGroup{Name: "test-group"}))
+	require.NoError(t, client.CreateConfig("test-project", &LogConfig{Name: "test-config", InputType: "file"}))
+	err = client.ApplyConfigToMachineGroup("test-project", "not-exist", "test-group")
+	require.Equal(t, CONFIG_NOT_EXIST, err.(*Error).Code)
+	require.NoError(t, client.ApplyConfigToMachineGroup("test-project", "test-config", "test-group"))
+	configs, err := client.GetAppliedConfigs("test-project", "test-group")
+	require.NoError(t, err)
+	require.Equal(t, []string{"test-config"}, configs)
+	require.NoError(t, client.DeleteMachineGroup("test-project", "test-group"))
+	_, err = client.GetMachineGroup("test-project", "test-group")
+	require.Equal(t, MACHINE_GROUP_NOT_EXIST, err.(*Error).Code)
+
+	require.NoError(t, client.CreateDashboard("test-project", Dashboard{DashboardName: "test-dashboard"}))
+	require.NoError(t, client.CreateChart("test-project", "test-dashboard", Chart{Title: "test-chart", Type: "table"}))
+	err = client.CreateChart("test-project", "test-dashboard", Chart{Title: "test-chart"})
+	require.Equal(t, CHART_ALREADY_EXIST, err.(*Error).Code)
+	chart, err := client.GetChart("test-project", "test-dashboard", "test-chart")
+	require.NoError(t, err)
+	require.Equal(t, "table", chart.Type)
+	dashboards, count, total, err := client.ListDashboard("test-project", "test", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"test-dashboard"}, dashboards)
+	require.Equal(t, 1, count)
+	require.Equal(t, 1, total)
+
+	require.NoError(t, client.CreateAlert("test-project", &Alert{
+		Name:          "test-alert",
+		Configuration: &AlertConfiguration{Dashboard: "test-dashboard"},
+	}))
+	require.NoError(t, client.DisableAlert("test-project", "test-alert"))
+	alerts, _, _, err := client.ListAlert("test-project", "", "test-dashboard", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	require.False(t, alerts[0].IsEnabled())
+	err = client.EnableAlert("test-project", "not-exist")
+	require.Equal(t, JOB_NOT_EXIST, err.(*Error).Code)
+
+	require.NoError(t, client.CreateStoreView("test-project", &StoreView{
+		Name:      "test-view",
+		StoreType: STORE_VIEW_STORE_TYPE_LOGSTORE,
+		Stores:    []*StoreViewStore{{Project: "test-project", StoreName: "test-logstore"}, {Project: "test-project", StoreName: "not-exist"}},
+	}))
+	storeViewIndex, err := client.GetStoreViewIndex("test-project", "test-view")
+	require.NoError(t, err)
+	require.Len(t, storeViewIndex.Indexes, 1)
+	require.Equal(t, []string{" "}, storeViewIndex.Indexes[0].Index.Line.Token)
+	require.Len(t, storeViewIndex.StoreViewErrors, 1)
+	require.Equal(t, "not-exist", storeViewIndex.StoreViewErrors[0].LogStore)
+	err = client.CreateMetricConfig("test-project", "not-exist", &MetricsConfig{})
+	require.ErrorIs(t, err, ErrLogStoreNotExist)
+
+	require.NoError(t, client.TagResources("", NewProjectTags("test-project", []ResourceTag{{Key: "env", Value: "test"}})))
+	tags, _, err := client.ListTagResources("", "project", nil, []ResourceFilterTag{{Key: proto.String("env")}}, "")
+	require.NoError(t, err)
+	require.Len(t, tags, 1)
+	require.Equal(t, "test-project", tags[0].ResourceID)
+	require.NoError(t, client.UnTagResources("", &ResourceUnTags{ResourceType: "project", ResourceID: []string{"test-project"}, All: true}))
+	tags, _, err = client.ListTagResources("", "project", []string{"test-project"}, nil, "")
+	require.NoError(t, err)
+	require.Empty(t, tags)
+
+	require.NoError(t, client.DeleteProject("test-project"))
+	_, err = client.ListLogStore("test-project")
+	require.ErrorIs(t, err, ErrProjectNotExist)
+}
+
+func TestFakeClientJobs(t *testing.T) {
+	client := newTestFakeClient(t)
+
+	require.NoError(t, client.CreateETL("test-project", NewETL("endpoint", "id", "secret", "test-logstore", "test-etl", "test-project")))
+	err := client.CreateETL("test-project", ETL{Name: "test-etl"})
+	require.Equal(t, JOB_ALREADY_EXIST, err.(*Error).Code)
+	require.NoError(t, client.StopETL("test-project", "test-etl"))
+	etl, err := client.GetETL("test-project", "test-etl")
+	require.NoError(t, err)
+	require.Equal(t, "STOPPED", etl.Status)
+	require.Equal(t, "test-logstore", etl.Configuration.Logstore)
+	etls, err := client.ListETL("test-project", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, etls.Total)
+	require.NoError(t, client.DeleteETL("test-project", "test-etl"))
+	_, err = client.GetETL("test-project", "test-etl")
+	require.Equal(t, JOB_NOT_EXIST, err.(*Error).Code)
+
+	require.NoError(t, client.CreateEtlMeta("test-project", &EtlMeta{MetaName: "meta", MetaKey: "a", MetaTag: "x"}))
+	require.NoError(t, client.CreateEtlMeta("test-project", &EtlMeta{MetaName: "meta", MetaKey: "b", MetaTag: "y"}))
+	total, _, metas, err := client.ListEtlMetaWithTag("test-project", "meta", "y", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "b", metas[0].MetaKey)
+	_, _, names, err := client.ListEtlMetaName("test-project", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, []string{"meta"}, names)
+
+	require.NoError(t, client.CreateScheduledSQL("test-project", &ScheduledSQL{Name: "test-sql", DisplayName: "sql"}))
+	sqls, total, _, err := client.ListScheduledSQL("test-project", "", "sql", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, ENABLED, sqls[0].Status)
+	_, err = client.GetScheduledSQLJobInstance("test-project", "test-sql", "instance", false)
+	require.Equal(t, "JobInstanceNotExist", err.(*Error).Code)
+
+	export := &Export{ExportConfiguration: &ExportConfiguration{LogStore: "test-logstore", Parameters: map[string]string{}, DataSink: &AliyunOSSSink{Type: DataSinkOSS, Bucket: "bucket"}}}
+	export.Name = "test-export"
+	require.NoError(t, client.CreateExport("test-project", export))
+	exports, total, _, err := client.ListExport("test-project", "test-logstore", "", "", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "bucket", exports[0].ExportConfiguration.DataSink.(*AliyunOSSSink).Bucket)
+	_, total, _, err = client.ListExport("test-project", "other-logstore", "", "", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 0, total)
+
+	require.NoError(t, client.CreateResource(&Resource{Name: "test-resource", Type: "userdefine"}))
+	require.NoError(t, client.CreateResourceRecord("test-resource", &ResourceRecord{Id: "1", Value: "{}"}))
+	err = client.CreateResourceRecord("test-resource", &ResourceRecord{Id: "1"})
+	require.Equal(t, "ResourceRecordAlreadyExist", err.(*Error).Code)
+	records, _, total, err := client.ListResourceRecord("test-resource", 0, 10)
+	require.NoError(t, err)
+	require.Equal(t, 1, total)
+	require.Equal(t, "{}", records[0].Value)
+	require.NoError(t, client.DeleteResource("test-resource"))
+	_, err = client.GetResourceRecord("test-resource", "1")
+	require.Equal(t, "ResourceNotExist", err.(*Error).Code)
+
+	require.NoError(t, client.PublishAlertEvent("test-project", []byte(`[]`)))
+	err = client.PublishAlertEvent("test-project", []byte(`{`))
+	require.Equal(t, POST_BODY_INVALID, err.(*Error).Code)
+}
+
+func TestFakeClientLogs(t *testing.T) {
+	client := newTestFakeClient(t)
+	now := uint32(time.Now().Unix())
+	topic := "topic"
+	hashKey := "00000000000000000000000000000000"
+	for i, level := range []string{"info", "error", "error"} {
+		err := client.PostLogStoreLogs("test-project", "test-logstore", &LogGroup{
+			Topic: &topic,
+			Logs: []*Log{
+				{Time: &now, Contents: []*LogContent{
+					{Key: proto.String("level"), Value: &level},
+					{Key: proto.String("index"), Value: proto.String(strconv.Itoa(i))},
+				}},
+			},
+		}, &hashKey)
+		require.NoError(t, err)
+	}
+
+	cursor, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	gl, nextCursor, err := client.PullLogs("test-project", "test-logstore", 0, cursor, "", 2)
+	require.NoError(t, err)
+	require.Len(t, gl.LogGroups, 2)
+	require.Equal(t, "topic", gl.LogGroups[0].GetTopic())
+	gl, nextCursor, err = client.PullLogs("test-project", "test-logstore", 0, nextCursor, "", 10)
+	require.NoError(t, err)
+	require.Len(t, gl.LogGroups, 1)
+	endCursor, err := client.GetCursor("test-project", "test-logstore", 0, "end")
+	require.NoError(t, err)
+	require.Equal(t, endCursor, nextCursor)
+	gl, _, err = client.PullLogs("test-project", "test-logstore", 1, cursor, "", 10)
+	require.NoError(t, err)
+	require.Len(t, gl.LogGroups, 0)
+	_, _, err = client.PullLogs("test-project", "test-logstore", 0, "invalid", "", 10)
+	require.ErrorIs(t, err, ErrInvalidCursor)
+	_, _, err = client.PullLogs("test-project", "test-logstore", 2, cursor, "", 10)
+	require.ErrorIs(t, err, ErrShardNotExist)
+
+	resp, err := client.GetLogs("test-project", "test-logstore", "", int64(now), int64(now)+1, "level: error", 100, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), resp.Count)
+	require.Equal(t, "1", resp.Logs[0]["index"])
+	_, err = client.GetLogs("test-project", "test-logstore", "", int64(now), int64(now)+1, "* | select count(*)", 100, 0, false)
+	require.Equal(t, NOT_SUPPORTED, err.(*Error).Code)
+
+	// split shard 0, and logs are written to the new shards
+	shards, err := client.SplitShard("test-project", "test-logstore", 0, "40000000000000000000000000000000")
+	require.NoError(t, err)
+	require.Len(t, shards, 3)
+	require.Equal(t, "readonly", shards[0].Status)
+	require.Equal(t, 3, shards[2].ShardID)
+	require.Equal(t, "40000000000000000000000000000000", shards[2].InclusiveBeginKey)
+	hashKey = "50000000000000000000000000000000"
+	require.NoError(t, client.PostLogStoreLogs("test-project", "test-logstore", &LogGroup{
+		Logs: []*Log{{Time: &now, Contents: []*LogContent{{Key: proto.String("k"), Value: proto.String("v")}}}},
+	}, &hashKey))
+	cursor, err = client.GetCursor("test-project", "test-logstore", 3, "end")
+	require.NoError(t, err)
+	require.Equal(t, fakestore.EncodeCursor(1), cursor)
+}
+
+func TestFakeClientConsumerGroup(t *testing.T) {
+	client := newTestFakeClient(t)
+	cg := ConsumerGroup{ConsumerGroupName: "test-cg", Timeout: 10}
+	require.NoError(t, client.CreateConsumerGroup("test-project", "test-logstore", cg))
+	err := client.CreateConsumerGroup("test-project", "test-logstore", cg)
+	require.Equal(t, CONSUMER_GROUP_ALREADY_EXIST, err.(*Error).Code)
+
+	shards, err := client.HeartBeat("test-project", "test-logstore", "test-cg", "consumer-1", nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, shards)
+	shards, err = client.HeartBeat("test-project", "test-logstore", "test-cg", "consumer-2", nil)
+	require.NoError(t, err)
+	require.Empty(t, shards)
+
+	cursor, err := client.GetCursor("test-project", "test-logstore", 0, "end")
+	require.NoError(t, err)
+	err = client.UpdateCheckpoint("test-project", "test-logstore", "test-cg", "consumer-2", 0, cursor, false)
+	require.Equal(t, CONSUMER_NOT_MATCH, err.(*Error).Code)
+	require.NoError(t, client.UpdateCheckpoint("test-project", "test-logstore", "test-cg", "consumer-1", 0, cursor, false))
+	checkpoints, err := client.GetCheckpoint("test-project", "test-logstore", "test-cg")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 1)
+	require.Equal(t, cursor, checkpoints[0].CheckPoint)
+	require.Equal(t, "consumer-1", checkpoints[0].Consumer)
+
+	require.NoError(t, client.DeleteConsumerGroup("test-project", "test-logstore", "test-cg"))
+	_, err = client.GetCheckpoint("test-project", "test-logstore", "test-cg")
+	require.Equal(t, CONSUMER_GROUP_NOT_EXIST, err.(*Error).Code)
+}
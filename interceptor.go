@@ -0,0 +1,98 @@
+package sls
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+)
+
+// RoundTripRequest is a signed request to SLS passed through the interceptor chain.
+type RoundTripRequest struct {
+	Project string // empty for requests that are not bound to a project
+	Method  string
+	URI     string // path and query, eg. /logstores/test/shards/0?type=cursor&from=end
+	Headers map[string]string
+	Body    []byte
+}
+
+// RoundTrip sends a request to SLS.
+//
+// If the returned error is nil, the caller must close the response body. Responses of any status code
+// are passed through the interceptors, the body of a non-200 response is read into memory, and it is
+// turned into an *Error or *BadResponseError once returned by the outermost interceptor.
+// Otherwise the response is nil, eg. on connection errors.
+type RoundTrip func(ctx context.Context, req *RoundTripRequest) (*http.Response, error)
+
+// Interceptor wraps a RoundTrip, it can inspect or modify the request before calling next,
+// inspect the response or error after calling next, or return without calling next at all.
+//
+//	client.Use(func(next sls.RoundTrip) sls.RoundTrip {
+//		return func(ctx context.Context, req *sls.RoundTripRequest) (*http.Response, error) {
+//			start := time.Now()
+//			resp, err := next(ctx, req)
+//			log.Printf("%s %s cost %v, err: %v", req.Method, req.URI, time.Since(start), err)
+//			return resp, err
+//		}
+//	})
+type Interceptor func(next RoundTrip) RoundTrip
+
+// Use appends interceptors to the client, they are called in the order they are added
+// for every http request sent to SLS, including each retry, after the circuit breaker,
+// rate limiter and metrics collector of the client.
+func (c *Client) Use(interceptors ...Interceptor) {
+	c.accessKeyLock.Lock()
+	c.interceptors = append(c.interceptors, interceptors...)
+	c.accessKeyLock.Unlock()
+}
+
+// Use appends interceptors to the project, see Client.Use.
+//
+// Deprecated: use Client.Use instead.
+func (p *LogProject) Use(interceptors ...Interceptor) *LogProject {
+	p.interceptors = append(p.interceptors, interceptors...)
+	return p
+}
+
+// bufferedBody is the body of a non-200 response read into memory, it is parsed into an error
+// after the interceptors even if they have read it.
+type bufferedBody struct {
+	*bytes.Reader
+	data []byte
+}
+
+func newBufferedBody(data []byte) *bufferedBody {
+	return &bufferedBody{Reader: bytes.NewReader(data), data: data}
+}
+
+func (b *bufferedBody) Close() error {
+	return nil
+}
+
+// statusErrorInterceptor turns a non-200 response returned by next into the error returned by toError.
+func statusErrorInterceptor(toError func(body []byte, resp *http.Response) error) Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			if err != nil || resp == nil || resp.StatusCode == http.StatusOK {
+				return resp, err
+			}
+			defer resp.Body.Close()
+			var body []byte
+			if b, ok := resp.Body.(*bufferedBody); ok {
+				body = b.data
+			} else if body, err = ioutil.ReadAll(resp.Body); err != nil {
+				return nil, readResponseError(err)
+			}
+			return nil, toError(body, resp)
+		}
+	}
+}
+
+// chainInterceptors returns a RoundTrip that calls interceptors in order and then rt.
+func chainInterceptors(rt RoundTrip, interceptors []Interceptor) RoundTrip {
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		rt = interceptors[i](rt)
+	}
+	return rt
+}
@@ -0,0 +1,115 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterceptor(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore),
+		httpmock.NewStringResponder(200, `[]`))
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore),
+		httpmock.NewStringResponder(200, `[{"shardID":0,"status":"readwrite"}]`))
+
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	var calls []string
+	client.Use(func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			calls = append(calls, "outer:"+req.Method+" "+req.URI)
+			return next(ctx, req)
+		}
+	}, func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			require.Equal(t, project, req.Project)
+			require.NotEmpty(t, req.Headers[HTTPHeaderAuthorization])
+			resp, err := next(ctx, req)
+			if err != nil {
+				calls = append(calls, "inner:"+err.(*Error).Code)
+			} else {
+				calls = append(calls, fmt.Sprintf("inner:%d", resp.StatusCode))
+			}
+			return resp, err
+		}
+	})
+
+	// Client path
+	_, err := client.GetCheckpoint(project, logstore, "cg")
+	require.NoError(t, err)
+	require.Equal(t, []string{"outer:GET /logstores/testLogstore/consumergroups/cg", "inner:200"}, calls)
+
+	// LogProject path
+	calls = nil
+	shards, err := client.ListShards(project, logstore)
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+	require.Equal(t, []string{"outer:GET /logstores/testLogstore/shards", "inner:200"}, calls)
+
+	// short-circuit the chain without sending the request
+	client.Use(func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			return nil, &Error{HTTPCode: 403, Code: WRITE_QUOTA_EXCEED, Message: "injected"}
+		}
+	})
+	calls = nil
+	_, err = client.GetCheckpoint(project, logstore, "cg")
+	require.Error(t, err)
+	require.Equal(t, WRITE_QUOTA_EXCEED, err.(*Error).Code)
+	require.Equal(t, []string{"outer:GET /logstores/testLogstore/consumergroups/cg", "inner:" + WRITE_QUOTA_EXCEED}, calls)
+	require.Equal(t, 1, transport.GetCallCountInfo()["GET "+fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore)])
+}
+
+func TestInterceptorErrorResponse(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	transport := httpmock.NewMockTransport()
+	errorResponder := func(req *http.Request) (*http.Response, error) {
+		resp := httpmock.NewStringResponse(500, `{"errorCode":"InternalServerError","errorMessage":"internal error"}`)
+		resp.Header.Set(RequestIDHeader, "testRequestID")
+		return resp, nil
+	}
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore), errorResponder)
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore), errorResponder)
+
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	client.SetRetryTimeout(100 * time.Millisecond)
+	var observed []string
+	client.Use(func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			resp, err := next(ctx, req)
+			require.NoError(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			require.NoError(t, err)
+			observed = append(observed, fmt.Sprintf("%d %s %s", resp.StatusCode, resp.Header.Get(RequestIDHeader), body))
+			return resp, nil
+		}
+	})
+
+	// Client path and LogProject path
+	_, err := client.GetCheckpoint(project, logstore, "cg")
+	slsErr := serverErrorOf(err)
+	require.NotNil(t, slsErr)
+	require.Equal(t, int32(500), slsErr.HTTPCode)
+	require.Equal(t, INTERNAL_SERVER_ERROR, slsErr.Code)
+	require.Equal(t, "testRequestID", slsErr.RequestID)
+	_, err = client.ListShards(project, logstore)
+	slsErr = serverErrorOf(err)
+	require.NotNil(t, slsErr)
+	require.Equal(t, int32(500), slsErr.HTTPCode)
+	require.Equal(t, "testRequestID", slsErr.RequestID)
+
+	require.NotEmpty(t, observed)
+	for _, o := range observed {
+		require.Equal(t, `500 testRequestID {"errorCode":"InternalServerError","errorMessage":"internal error"}`, o)
+	}
+}
@@ -0,0 +1,177 @@
+// Package fakestore holds the logic shared by the in-memory stores of sls.FakeClient and slstest.Server,
+// so that both behave the same way.
+package fakestore
+
+import (
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxHashKey is the end of the md5 hash key space, which is inclusive for the last shard.
+const MaxHashKey = "ffffffffffffffffffffffffffffffff"
+
+// RouteHashKey returns the index of the shard whose hash key range [begin, end) contains key,
+// or -1 if there is none, keyRange returns the range of the i-th of n shards.
+func RouteHashKey(key string, n int, keyRange func(i int) (begin, end string)) int {
+	key = strings.ToLower(key)
+	if len(key) < 32 {
+		key += strings.Repeat("0", 32-len(key))
+	}
+	for i := 0; i < n; i++ {
+		begin, end := keyRange(i)
+		if key >= begin && (key < end || end == MaxHashKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// SplitHashKeyRange splits the hash key range [begin, end) evenly into n ranges, whose bounds are returned
+// in order, so that the i-th range is [bounds[i], bounds[i+1]). end is MaxHashKey for the last range.
+func SplitHashKeyRange(begin, end string, n int) (bounds []string) {
+	b, _ := new(big.Int).SetString(begin, 16)
+	e, _ := new(big.Int).SetString(end, 16)
+	if end == MaxHashKey {
+		e.Add(e, big.NewInt(1))
+	}
+	step := new(big.Int).Sub(e, b)
+	step.Div(step, big.NewInt(int64(n)))
+	for i := 0; i < n; i++ {
+		bounds = append(bounds, fmt.Sprintf("%032x", new(big.Int).Add(b, new(big.Int).Mul(step, big.NewInt(int64(i))))))
+	}
+	return append(bounds, end)
+}
+
+// EncodeCursor returns the cursor pointing to the log group at offset of a shard.
+func EncodeCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+// DecodeCursor returns the offset of cursor in a shard of size log groups, ok is false if cursor is invalid.
+func DecodeCursor(cursor string, size int) (offset int, ok bool) {
+	b, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+	offset, err = strconv.Atoi(string(b))
+	if err != nil || offset < 0 || offset > size {
+		return 0, false
+	}
+	return offset, true
+}
+
+// CursorOf returns the cursor of from in a shard of size log groups, which is begin, end or a unix timestamp
+// of receive time, receiveTime returns the receive time of the i-th log group. ok is false if from is invalid.
+func CursorOf(from string, size int, receiveTime func(i int) int64) (cursor string, ok bool) {
+	switch from {
+	case "begin":
+		return EncodeCursor(0), true
+	case "end":
+		return EncodeCursor(size), true
+	}
+	ts, err := strconv.ParseInt(from, 10, 64)
+	if err != nil {
+		return "", false
+	}
+	offset := sort.Search(size, func(i int) bool {
+		return receiveTime(i) >= ts
+	})
+	return EncodeCursor(offset), true
+}
+
+// Assignment is the assignment of the shards of a consumer group to its consumers.
+type Assignment struct {
+	heartbeats map[string]time.Time // consumer => time of last heartbeat
+	owners     map[int]string       // shard => consumer
+}
+
+func NewAssignment() *Assignment {
+	return &Assignment{
+		heartbeats: make(map[string]time.Time),
+		owners:     make(map[int]string),
+	}
+}
+
+// Heartbeat records the heartbeat of consumer and returns the shards assigned to it.
+//
+// Shards of consumers missing heartbeats for timeout are released. The shards are balanced among the
+// consumers alive, so the consumer releases the shards beyond its even share to the consumers joining
+// later, and is assigned free shards until it holds its even share.
+func (a *Assignment) Heartbeat(consumer string, shardCount int, timeout time.Duration) []int {
+	now := time.Now()
+	a.heartbeats[consumer] = now
+	var consumers []string
+	for c, last := range a.heartbeats {
+		if now.Sub(last) > timeout {
+			delete(a.heartbeats, c)
+		} else {
+			consumers = append(consumers, c)
+		}
+	}
+	// the first shardCount % len(consumers) consumers hold one more shard
+	sort.Strings(consumers)
+	share := shardCount / len(consumers)
+	if i := sort.SearchStrings(consumers, consumer); i < shardCount%len(consumers) {
+		share++
+	}
+	held := []int{}
+	for id, owner := range a.owners {
+		if _, alive := a.heartbeats[owner]; !alive || id >= shardCount {
+			delete(a.owners, id)
+		} else if owner == consumer {
+			held = append(held, id)
+		}
+	}
+	sort.Ints(held)
+	for len(held) > share {
+		delete(a.owners, held[len(held)-1])
+		held = held[:len(held)-1]
+	}
+	for id := 0; id < shardCount && len(held) < share; id++ {
+		if _, ok := a.owners[id]; !ok {
+			a.owners[id] = consumer
+			held = append(held, id)
+		}
+	}
+	sort.Ints(held)
+	return held
+}
+
+// Owner returns the consumer holding shard.
+func (a *Assignment) Owner(shard int) (consumer string, ok bool) {
+	consumer, ok = a.owners[shard]
+	return consumer, ok
+}
+
+// Matches returns whether log matches a simple keyword query: terms separated by spaces or "and",
+// each of which is "key: value" or a keyword contained in any value. "*" and "" match all logs.
+func Matches(query string, log map[string]string) bool {
+	query = strings.ReplaceAll(query, ": ", ":")
+	for _, term := range strings.Fields(query) {
+		if term == "*" || strings.EqualFold(term, "and") {
+			continue
+		}
+		if i := strings.Index(term, ":"); i > 0 {
+			if !strings.Contains(log[term[:i]], term[i+1:]) {
+				return false
+			}
+			continue
+		}
+		found := false
+		for k, v := range log {
+			if !strings.HasPrefix(k, "__") && strings.Contains(v, term) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
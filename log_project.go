@@ -3,6 +3,7 @@ package sls
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -13,6 +14,7 @@ import (
 	"time"
 
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -56,6 +58,7 @@ type LogProject struct {
 	AuthVersion        AuthVersionType // Deprecated: will be made private in the next version
 	baseURL            string
 	retryTimeout       time.Duration
+	retryPolicy        *RetryPolicy
 	httpClient         *http.Client
 	credentialProvider CredentialsProvider
 
@@ -65,6 +68,17 @@ type LogProject struct {
 	// be ignored
 	commonHeaders map[string]string
 	innerHeaders  map[string]string
+	interceptors  []Interceptor
+	tracer        trace.Tracer
+	rateLimiter   *RateLimiter
+	breaker       *CircuitBreaker
+	endpoints     *endpointGroup
+	metrics       *MetricsCollector
+	newSigner     func(creds Credentials) Signer
+
+	// ctx is the parent context of all requests sent by this project,
+	// it is only set by the context-aware methods of Client.
+	ctx context.Context
 }
 
 // NewLogProject creates a new SLS project.
@@ -125,6 +139,31 @@ func (p *LogProject) WithRetryTimeout(timeout time.Duration) *LogProject {
 	return p
 }
 
+// WithRetryPolicy sets the retry policy of requests sent by the project,
+// nil means the default retry behavior controlled by package level variables, eg. RetryOnServerErrorEnabled.
+func (p *LogProject) WithRetryPolicy(policy *RetryPolicy) *LogProject {
+	p.retryPolicy = policy
+	return p
+}
+
+// WithRateLimiter sets the rate limiter of requests sent by the project, nil means no limit.
+func (p *LogProject) WithRateLimiter(limiter *RateLimiter) *LogProject {
+	p.rateLimiter = limiter
+	return p
+}
+
+// WithCircuitBreaker sets the circuit breaker of requests sent by the project, nil means no circuit breaker.
+func (p *LogProject) WithCircuitBreaker(breaker *CircuitBreaker) *LogProject {
+	p.breaker = breaker
+	return p
+}
+
+// WithMetricsCollector sets the collector of metrics of requests sent by the project, nil means no metrics.
+func (p *LogProject) WithMetricsCollector(collector *MetricsCollector) *LogProject {
+	p.metrics = collector
+	return p
+}
+
 // RawRequest send raw http request to LogService and return the raw http response
 // @note you should call http.Response.Body.Close() to close body stream
 func (p *LogProject) RawRequest(method, uri string, headers map[string]string, body []byte) (*http.Response, error) {
@@ -436,8 +475,8 @@ func (p *LogProject) CheckLogstoreExist(name string) (bool, error) {
 	r, err := request(p, "GET", "/logstores/"+name, h, nil)
 
 	if err != nil {
-		if _, ok := err.(*Error); ok {
-			slsErr := err.(*Error)
+		var slsErr *Error
+		if errors.As(err, &slsErr) {
 			if slsErr.Code == "LogStoreNotExist" {
 				return false, nil
 			}
@@ -456,8 +495,8 @@ func (p *LogProject) CheckMachineGroupExist(name string) (bool, error) {
 	}
 	r, err := request(p, "GET", "/machinegroups/"+name, h, nil)
 	if err != nil {
-		if _, ok := err.(*Error); ok {
-			slsErr := err.(*Error)
+		var slsErr *Error
+		if errors.As(err, &slsErr) {
 			if slsErr.Code == "MachineGroupNotExist" {
 				return false, nil
 			}
@@ -743,8 +782,8 @@ func (p *LogProject) CheckConfigExist(name string) (bool, error) {
 	}
 	r, err := request(p, "GET", "/configs/"+name, h, nil)
 	if err != nil {
-		if _, ok := err.(*Error); ok {
-			slsErr := err.(*Error)
+		var slsErr *Error
+		if errors.As(err, &slsErr) {
 			if slsErr.Code == "ConfigNotExist" {
 				return false, nil
 			}
@@ -1326,6 +1365,14 @@ func (p *LogProject) init() {
 	}
 }
 
+// requestContext returns the parent context of requests sent by this project.
+func (p *LogProject) requestContext() context.Context {
+	if p.ctx == nil {
+		return context.Background()
+	}
+	return p.ctx
+}
+
 func (p *LogProject) getBaseURL() string {
 	p.parseEndpointIfNeeded()
 	return p.baseURL
@@ -1339,23 +1386,27 @@ func (p *LogProject) parseEndpointIfNeeded() {
 }
 
 func (p *LogProject) parseEndpoint() {
+	p.baseURL = p.baseURLOf(p.Endpoint)
+}
+
+// baseURLOf returns the base url of requests of the project sent to endpoint.
+func (p *LogProject) baseURLOf(endpoint string) string {
 	scheme := httpScheme // default to http scheme
-	host := p.Endpoint
+	host := endpoint
 
-	if strings.HasPrefix(p.Endpoint, httpScheme) {
+	if strings.HasPrefix(endpoint, httpScheme) {
 		scheme = httpScheme
-		host = strings.TrimPrefix(p.Endpoint, scheme)
-	} else if strings.HasPrefix(p.Endpoint, httpsScheme) {
+		host = strings.TrimPrefix(endpoint, scheme)
+	} else if strings.HasPrefix(endpoint, httpsScheme) {
 		scheme = httpsScheme
-		host = strings.TrimPrefix(p.Endpoint, scheme)
+		host = strings.TrimPrefix(endpoint, scheme)
 	}
 
 	if GlobalForceUsingHTTP || p.UsingHTTP {
 		scheme = httpScheme
 	}
 	if len(p.Name) == 0 {
-		p.baseURL = fmt.Sprintf("%s%s", scheme, host)
-	} else {
-		p.baseURL = fmt.Sprintf("%s%s.%s", scheme, p.Name, host)
+		return fmt.Sprintf("%s%s", scheme, host)
 	}
+	return fmt.Sprintf("%s%s.%s", scheme, p.Name, host)
 }
@@ -2,16 +2,15 @@ package sls
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"io/ioutil"
 	"net/http"
-	"net/http/httputil"
 	"net/url"
 	"strconv"
 
-	"github.com/go-kit/kit/log/level"
 	"github.com/gogo/protobuf/proto"
 	"github.com/pierrec/lz4/v4"
 )
@@ -290,59 +289,18 @@ func (s *LogStore) PutLogs(lg *LogGroup) (err error) {
 		return nil
 	}
 
-	body, err := proto.Marshal(lg)
+	out, h, release, err := encodeLogGroup(lg, s.putLogCompressType)
 	if err != nil {
 		return NewClientError(err)
 	}
-
-	var out []byte
-	var h map[string]string
-	var outLen int
-	switch s.putLogCompressType {
-	case Compress_LZ4:
-		// Compresse body with lz4
-		out = make([]byte, lz4.CompressBlockBound(len(body)))
-		var hashTable [1 << 16]int
-		n, err := lz4.CompressBlock(body, out, hashTable[:])
-		if err != nil {
-			return NewClientError(err)
-		}
-		// copy incompressible data as lz4 format
-		if n == 0 {
-			n, _ = copyIncompressible(body, out)
-		}
-
-		h = map[string]string{
-			"x-log-compresstype": "lz4",
-			"x-log-bodyrawsize":  strconv.Itoa(len(body)),
-			"Content-Type":       "application/x-protobuf",
-		}
-		outLen = n
-	case Compress_ZSTD:
-		// Compress body with zstd
-		out, _ = slsZstdCompressor.Compress(body, nil)
-		h = map[string]string{
-			"x-log-compresstype": "zstd",
-			"x-log-bodyrawsize":  strconv.Itoa(len(body)),
-			"Content-Type":       "application/x-protobuf",
-		}
-		outLen = len(out)
-	case Compress_None:
-		// no compress
-		out = body
-		h = map[string]string{
-			"x-log-bodyrawsize": strconv.Itoa(len(body)),
-			"Content-Type":      "application/x-protobuf",
-		}
-		outLen = len(out)
-	}
+	defer release()
 	var uri string
 	if s.useMetricStoreURL {
 		uri = fmt.Sprintf("/prometheus/%s/%s/api/v1/write", s.project.Name, s.Name)
 	} else {
 		uri = fmt.Sprintf("/logstores/%v", s.Name)
 	}
-	r, err := request(s.project, "POST", uri, h, out[:outLen])
+	r, err := request(s.project, "POST", uri, h, out)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -377,52 +335,11 @@ func (s *LogStore) PostLogStoreLogs(req *PostLogStoreLogsRequest) (err error) {
 		return s.PutLogs(req.LogGroup)
 	}
 
-	body, err := proto.Marshal(req.LogGroup)
+	out, h, release, err := encodeLogGroup(req.LogGroup, s.putLogCompressType)
 	if err != nil {
 		return NewClientError(err)
 	}
-
-	var out []byte
-	var h map[string]string
-	var outLen int
-	switch s.putLogCompressType {
-	case Compress_LZ4:
-		// Compresse body with lz4
-		out = make([]byte, lz4.CompressBlockBound(len(body)))
-		var hashTable [1 << 16]int
-		n, err := lz4.CompressBlock(body, out, hashTable[:])
-		if err != nil {
-			return NewClientError(err)
-		}
-		// copy incompressible data as lz4 format
-		if n == 0 {
-			n, _ = copyIncompressible(body, out)
-		}
-
-		h = map[string]string{
-			"x-log-compresstype": "lz4",
-			"x-log-bodyrawsize":  strconv.Itoa(len(body)),
-			"Content-Type":       "application/x-protobuf",
-		}
-		outLen = n
-	case Compress_ZSTD:
-		// Compress body with zstd
-		out, _ = slsZstdCompressor.Compress(body, nil)
-		h = map[string]string{
-			"x-log-compresstype": "zstd",
-			"x-log-bodyrawsize":  strconv.Itoa(len(body)),
-			"Content-Type":       "application/x-protobuf",
-		}
-		outLen = len(out)
-	case Compress_None:
-		// no compress
-		out = body
-		h = map[string]string{
-			"x-log-bodyrawsize": strconv.Itoa(len(body)),
-			"Content-Type":      "application/x-protobuf",
-		}
-		outLen = len(out)
-	}
+	defer release()
 
 	var uri = fmt.Sprintf("/logstores/%s", s.Name)
 	var params = url.Values{}
@@ -437,7 +354,7 @@ func (s *LogStore) PostLogStoreLogs(req *PostLogStoreLogsRequest) (err error) {
 		uri = fmt.Sprintf("%s?%s", uri, params.Encode())
 	}
 
-	r, err := request(s.project, "POST", uri, h, out[:outLen])
+	r, err := request(s.project, "POST", uri, h, out)
 	if err != nil {
 		return NewClientError(err)
 	}
@@ -476,17 +393,7 @@ func (s *LogStore) GetCursor(shardID int, from string) (cursor string, err error
 	}
 
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			err = fmt.Errorf("failed to get cursor")
-			dump, _ := httputil.DumpResponse(r, true)
-			if IsDebugLevelMatched(1) {
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return
-		}
-		err = fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		err = httpStatusNotOkError(buf, r.Header, r.StatusCode)
 		return
 	}
 
@@ -547,21 +454,13 @@ func (s *LogStore) GetLogsBytesWithQuery(plr *PullLogRequest) ([]byte, *PullLogM
 		return nil, nil, err
 	}
 	defer r.Body.Close()
-	buf, err := ioutil.ReadAll(r.Body)
+	buf, err := readBody(r)
 	if err != nil {
 		return nil, nil, err
 	}
+	defer putBytes(buf)
 	if r.StatusCode != http.StatusOK {
-		errMsg := &Error{}
-		err = json.Unmarshal(buf, errMsg)
-		if err != nil {
-			dump, _ := httputil.DumpResponse(r, true)
-			if IsDebugLevelMatched(1) {
-				level.Error(Logger).Log("msg", string(dump))
-			}
-			return nil, nil, fmt.Errorf("failed parse errorCode json: %w", err)
-		}
-		return nil, nil, fmt.Errorf("%v:%v", errMsg.Code, errMsg.Message)
+		return nil, nil, httpStatusNotOkError(buf, r.Header, r.StatusCode)
 	}
 	netflow := len(buf)
 
@@ -598,32 +497,47 @@ func (s *LogStore) GetLogsBytesWithQuery(plr *PullLogRequest) ([]byte, *PullLogM
 	}
 
 	// decompress data
-	out := make([]byte, rawSize)
 	compressType, err := parseHeaderString(r.Header, "X-Log-Compresstype")
 	if err != nil {
 		return nil, nil, err
 	}
+	out, err := decompressLogsBytes(compressType, buf, rawSize)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, pullMeta, nil
+}
+
+// decompressLogsBytes decompresses buf of rawSize into a pooled buffer.
+func decompressLogsBytes(compressType string, buf []byte, rawSize int) ([]byte, error) {
+	out := getBytes(rawSize)
 	switch compressType {
 	case "lz4":
-		uncompressedSize := 0
-		if uncompressedSize, err = lz4.UncompressBlock(buf, out); err != nil {
-			return nil, nil, err
+		uncompressedSize, err := lz4.UncompressBlock(buf, out)
+		if err != nil {
+			putBytes(out)
+			return nil, err
 		}
 		if uncompressedSize != rawSize {
-			return nil, nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", uncompressedSize, rawSize)
+			putBytes(out)
+			return nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", uncompressedSize, rawSize)
 		}
 	case "zstd":
+		var err error
 		out, err = slsZstdCompressor.Decompress(buf, out)
 		if err != nil {
-			return nil, nil, err
+			putBytes(out)
+			return nil, err
 		}
 		if len(out) != rawSize {
-			return nil, nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", len(out), rawSize)
+			putBytes(out)
+			return nil, fmt.Errorf("uncompressed size %d does not match 'x-log-bodyrawsize' %d", len(out), rawSize)
 		}
 	default:
-		return nil, nil, fmt.Errorf("unexpected compress type: %s", compressType)
+		putBytes(out)
+		return nil, fmt.Errorf("unexpected compress type: %s", compressType)
 	}
-	return out, pullMeta, nil
+	return out, nil
 }
 
 // LogsBytesDecode decodes logs binary data returned by GetLogsBytes API
@@ -668,7 +582,9 @@ func (s *LogStore) PullLogsWithQuery(plr *PullLogRequest) (gl *LogGroupList, plm
 		return nil, nil, err
 	}
 
+	// the decoded logs do not refer to out
 	gl, err = LogsBytesDecode(out)
+	ReleaseLogsBytes(out)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -819,18 +735,23 @@ func (s *LogStore) GetLogsByNano(topic string, fromInNS int64, toInNs int64, que
 	return s.GetLogsV2(&req)
 }
 
-func (s *LogStore) getToCompleted(f func() (bool, error)) {
+// getToCompleted calls f until the result is completed, f returns an error, or the retries are exhausted.
+// It returns the error of f, or the error of the request context if it is done before completed.
+func (s *LogStore) getToCompleted(f func() (bool, error)) error {
 	interval := 100 * time.Millisecond
-	retryCount := MaxCompletedRetryCount
-	isCompleted := false
-	timeoutTime := time.Now().Add(MaxCompletedRetryLatency)
+	retryCount := s.project.retryPolicy.maxCompletedRetryCount()
+	timeoutTime := time.Now().Add(s.project.retryPolicy.maxCompletedRetryLatency())
+	ctx := s.project.requestContext()
 	for retryCount > 0 && timeoutTime.After(time.Now()) {
-		var err error
-		isCompleted, err = f()
+		isCompleted, err := f()
 		if err != nil || isCompleted {
-			return
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
 		}
-		time.Sleep(interval)
 		retryCount--
 		if interval < 10*time.Second {
 			interval = interval * 2
@@ -839,7 +760,7 @@ func (s *LogStore) getToCompleted(f func() (bool, error)) {
 			interval = 10 * time.Second
 		}
 	}
-	return
+	return nil
 }
 
 // GetLogsToCompleted query logs with [from, to) time range to completed
@@ -854,7 +775,7 @@ func (s *LogStore) GetLogsToCompleted(topic string, from int64, to int64, queryE
 		}
 		return false, err
 	}
-	s.getToCompleted(f)
+	err = s.getToCompleted(f)
 	return res, err
 }
 
@@ -869,7 +790,7 @@ func (s *LogStore) GetLogsToCompletedV2(req *GetLogRequest) (*GetLogsResponse, e
 		}
 		return false, err
 	}
-	s.getToCompleted(f)
+	err = s.getToCompleted(f)
 	return res, err
 }
 
@@ -884,7 +805,7 @@ func (s *LogStore) GetLogsToCompletedV3(req *GetLogRequest) (*GetLogsV3Response,
 		}
 		return false, err
 	}
-	s.getToCompleted(f)
+	err = s.getToCompleted(f)
 	return res, err
 }
 
@@ -899,7 +820,7 @@ func (s *LogStore) GetHistogramsToCompleted(topic string, from int64, to int64,
 		}
 		return false, err
 	}
-	s.getToCompleted(f)
+	err = s.getToCompleted(f)
 	return res, err
 }
 
@@ -913,7 +834,7 @@ func (s *LogStore) GetHistogramsToCompletedV2(ghr *GetHistogramRequest) (*GetHis
 		}
 		return false, err
 	}
-	s.getToCompleted(f)
+	err = s.getToCompleted(f)
 	return res, err
 }
 
@@ -1209,7 +1130,8 @@ func (s *LogStore) GetIndexString() (string, error) {
 // CheckIndexExist check index exist or not
 func (s *LogStore) CheckIndexExist() (bool, error) {
 	if _, err := s.GetIndex(); err != nil {
-		if slsErr, ok := err.(*Error); ok {
+		var slsErr *Error
+		if errors.As(err, &slsErr) {
 			if slsErr.Code == "IndexConfigNotExist" {
 				return false, nil
 			}
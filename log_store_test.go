@@ -1,8 +1,10 @@
 package sls
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strconv"
@@ -11,6 +13,9 @@ import (
 	"time"
 
 	"github.com/Netflix/go-env"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pierrec/lz4/v4"
+	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -287,3 +292,164 @@ func getLogsOld(s *LogStore, req *GetLogRequest) (*http.Response, []byte, *GetLo
 		Header:   r.Header,
 	}, nil
 }
+
+// staticTransport responds to all requests with the same response.
+type staticTransport struct {
+	header http.Header
+	body   []byte
+}
+
+func (t *staticTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		io.Copy(ioutil.Discard, req.Body)
+		req.Body.Close()
+	}
+	return &http.Response{
+		StatusCode:    http.StatusOK,
+		Header:        t.header.Clone(),
+		Body:          ioutil.NopCloser(bytes.NewReader(t.body)),
+		ContentLength: int64(len(t.body)),
+		Request:       req,
+	}, nil
+}
+
+func newBenchmarkLogGroup(n int) *LogGroup {
+	logGroup := &LogGroup{Topic: proto.String("topic"), Source: proto.String("127.0.0.1")}
+	for i := 0; i < n; i++ {
+		logGroup.Logs = append(logGroup.Logs, &Log{
+			Time: proto.Uint32(uint32(time.Now().Unix())),
+			Contents: []*LogContent{
+				{Key: proto.String("level"), Value: proto.String("INFO")},
+				{Key: proto.String("message"), Value: proto.String(fmt.Sprintf("request %d handled in %dms", i, i%100))},
+			},
+		})
+	}
+	return logGroup
+}
+
+func newStaticLogStore(transport http.RoundTripper) *LogStore {
+	client := CreateNormalInterface("mock-test-endpoint.aliyuncs.com", "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	return convertLogstore(client, "testProject", "testLogstore")
+}
+
+func TestEncodeLogGroup(t *testing.T) {
+	logGroup := newBenchmarkLogGroup(100)
+	raw, err := proto.Marshal(logGroup)
+	require.NoError(t, err)
+	for _, compressType := range []int{Compress_LZ4, Compress_ZSTD, Compress_None} {
+		body, h, release, err := encodeLogGroup(logGroup, compressType)
+		require.NoError(t, err)
+		require.Equal(t, strconv.Itoa(len(raw)), h["x-log-bodyrawsize"])
+		var out []byte
+		switch compressType {
+		case Compress_LZ4:
+			out, err = decompressLogsBytes("lz4", body, len(raw))
+		case Compress_ZSTD:
+			out, err = decompressLogsBytes("zstd", body, len(raw))
+		default:
+			out = append([]byte(nil), body...)
+		}
+		require.NoError(t, err)
+		require.Equal(t, raw, out)
+		release()
+		ReleaseLogsBytes(out)
+	}
+}
+
+func TestGetLogsBytesWithQueryPooled(t *testing.T) {
+	logGroupList := &LogGroupList{LogGroups: []*LogGroup{newBenchmarkLogGroup(100)}}
+	raw, err := proto.Marshal(logGroupList)
+	require.NoError(t, err)
+	body, err := slsZstdCompressor.Compress(raw, nil)
+	require.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Log-Cursor", "next")
+	header.Set("X-Log-Bodyrawsize", strconv.Itoa(len(raw)))
+	header.Set("X-Log-Count", "1")
+	header.Set("X-Log-Compresstype", "zstd")
+	store := newStaticLogStore(&staticTransport{header: header, body: body})
+
+	for i := 0; i < 3; i++ {
+		gl, plm, err := store.PullLogsWithQuery(&PullLogRequest{ShardID: 0, Cursor: "cursor", LogGroupMaxCount: 10, CompressType: Compress_ZSTD})
+		require.NoError(t, err)
+		require.Equal(t, "next", plm.NextCursor)
+		require.Len(t, gl.LogGroups, 1)
+		require.Len(t, gl.LogGroups[0].Logs, 100)
+		require.Equal(t, "request 99 handled in 99ms", gl.LogGroups[0].Logs[99].Contents[1].GetValue())
+	}
+}
+
+func BenchmarkPostLogStoreLogs(b *testing.B) {
+	store := newStaticLogStore(&staticTransport{header: http.Header{}})
+	logGroup := newBenchmarkLogGroup(1000)
+	for name, compressType := range map[string]int{"lz4": Compress_LZ4, "zstd": Compress_ZSTD} {
+		b.Run(name, func(b *testing.B) {
+			req := &PostLogStoreLogsRequest{LogGroup: logGroup, CompressType: compressType}
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if err := store.PostLogStoreLogs(req); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkEncodeLogGroup compares encoding with pooled buffers to allocating buffers on each call.
+func BenchmarkEncodeLogGroup(b *testing.B) {
+	logGroup := newBenchmarkLogGroup(1000)
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_, _, release, err := encodeLogGroup(logGroup, Compress_LZ4)
+			if err != nil {
+				b.Fatal(err)
+			}
+			release()
+		}
+	})
+	b.Run("unpooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			body, err := proto.Marshal(logGroup)
+			if err != nil {
+				b.Fatal(err)
+			}
+			out := make([]byte, lz4.CompressBlockBound(len(body)))
+			var hashTable [1 << 16]int
+			if _, err = lz4.CompressBlock(body, out, hashTable[:]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetLogsBytesWithQuery(b *testing.B) {
+	raw, err := proto.Marshal(&LogGroupList{LogGroups: []*LogGroup{newBenchmarkLogGroup(1000)}})
+	if err != nil {
+		b.Fatal(err)
+	}
+	body := make([]byte, lz4.CompressBlockBound(len(raw)))
+	n, err := lz4.CompressBlock(raw, body, nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+	header := http.Header{}
+	header.Set("X-Log-Cursor", "next")
+	header.Set("X-Log-Bodyrawsize", strconv.Itoa(len(raw)))
+	header.Set("X-Log-Count", "1")
+	header.Set("X-Log-Compresstype", "lz4")
+	store := newStaticLogStore(&staticTransport{header: header, body: body[:n]})
+	plr := &PullLogRequest{ShardID: 0, Cursor: "cursor", LogGroupMaxCount: 10}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		out, _, err := store.GetLogsBytesWithQuery(plr)
+		if err != nil {
+			b.Fatal(err)
+		}
+		ReleaseLogsBytes(out)
+	}
+}
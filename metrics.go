@@ -0,0 +1,132 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// MetricsCollector is a prometheus.Collector of the requests sent by clients.
+//
+// The metrics are labeled by operation, project and logstore, where operation is the
+// name of the SLS api, eg. PostLogStoreLogs, and logstore is empty for apis not bound to a logstore.
+//
+//	collector := sls.NewMetricsCollector()
+//	prometheus.MustRegister(collector)
+//	client.SetMetricsCollector(collector)
+type MetricsCollector struct {
+	requests      *prometheus.CounterVec
+	latency       *prometheus.HistogramVec
+	retries       *prometheus.CounterVec
+	errors        *prometheus.CounterVec
+	sentBytes     *prometheus.CounterVec
+	rawBytes      *prometheus.CounterVec
+	receivedBytes *prometheus.CounterVec
+}
+
+var metricLabels = []string{"operation", "project", "logstore"}
+
+// NewMetricsCollector creates a MetricsCollector, it can be shared by clients.
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_requests_total",
+			Help: "Number of api calls sent to SLS, retries are not counted.",
+		}, metricLabels),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "sls_client_request_duration_seconds",
+			Help:    "Latency of api calls sent to SLS, including retries.",
+			Buckets: prometheus.DefBuckets,
+		}, metricLabels),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_retries_total",
+			Help: "Number of retried http requests of api calls.",
+		}, metricLabels),
+		errors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_errors_total",
+			Help: "Number of failed api calls by error code.",
+		}, append(metricLabels, "error_code")),
+		sentBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_sent_bytes_total",
+			Help: "Size of request bodies sent to SLS, after compression.",
+		}, metricLabels),
+		rawBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_sent_raw_bytes_total",
+			Help: "Size of request bodies sent to SLS, before compression.",
+		}, metricLabels),
+		receivedBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "sls_client_received_bytes_total",
+			Help: "Size of successful response bodies received from SLS.",
+		}, metricLabels),
+	}
+}
+
+func (m *MetricsCollector) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.latency, m.retries, m.errors, m.sentBytes, m.rawBytes, m.receivedBytes}
+}
+
+// Describe implements prometheus.Collector.
+func (m *MetricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *MetricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// observe records an api call that sent attempts http requests.
+func (m *MetricsCollector) observe(project string, op apiOperation, start time.Time, attempts int, err error) {
+	m.requests.WithLabelValues(op.Name, project, op.Logstore).Inc()
+	m.latency.WithLabelValues(op.Name, project, op.Logstore).Observe(time.Since(start).Seconds())
+	if attempts > 1 {
+		m.retries.WithLabelValues(op.Name, project, op.Logstore).Add(float64(attempts - 1))
+	}
+	if err != nil {
+		m.errors.WithLabelValues(op.Name, project, op.Logstore, metricErrorCode(err)).Inc()
+	}
+}
+
+// interceptor returns an Interceptor that records the size of each http request and response.
+func (m *MetricsCollector) interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			op := resolveOperation(req.Method, req.URI)
+			m.sentBytes.WithLabelValues(op.Name, req.Project, op.Logstore).Add(float64(len(req.Body)))
+			if rawSize, err := strconv.Atoi(req.Headers[HTTPHeaderBodyRawSize]); err == nil {
+				m.rawBytes.WithLabelValues(op.Name, req.Project, op.Logstore).Add(float64(rawSize))
+			}
+			resp, err := next(ctx, req)
+			if resp != nil && resp.ContentLength > 0 {
+				m.receivedBytes.WithLabelValues(op.Name, req.Project, op.Logstore).Add(float64(resp.ContentLength))
+			}
+			return resp, err
+		}
+	}
+}
+
+// metricErrorCode returns the error code label of err.
+func metricErrorCode(err error) string {
+	if slsErr := serverErrorOf(err); slsErr != nil {
+		return slsErr.Code
+	}
+	var badRespErr *BadResponseError
+	if errors.As(err, &badRespErr) {
+		return strconv.Itoa(badRespErr.HTTPCode)
+	}
+	if errors.Is(err, ErrCircuitOpen) {
+		return "CircuitOpen"
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return "Timeout"
+	}
+	return "ClientError"
+}
@@ -0,0 +1,71 @@
+package sls
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsCollector(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	shardsURL := fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore)
+	putLogsURL := fmt.Sprintf("http://%s.%s/logstores/%s", project, endpoint, logstore)
+	checkpointURL := fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore)
+	serverBusy := httpmock.NewStringResponder(503, `{"errorCode":"ServerBusy","errorMessage":"server busy"}`)
+	shards := httpmock.NewStringResponse(200, `[{"shardID":0}]`)
+	shards.ContentLength = int64(len(`[{"shardID":0}]`))
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("GET", shardsURL, serverBusy.Then(httpmock.ResponderFromResponse(shards)))
+	transport.RegisterResponder("POST", putLogsURL, httpmock.NewStringResponder(200, ""))
+	transport.RegisterResponder("GET", checkpointURL,
+		httpmock.NewStringResponder(404, `{"errorCode":"ConsumerGroupNotExist","errorMessage":"consumer group not exist"}`))
+
+	collector := NewMetricsCollector()
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	client.SetRetryPolicy(&RetryPolicy{InitialInterval: time.Millisecond, MaxInterval: time.Millisecond, RetryableHTTPCodes: []int{503}})
+	client.SetMetricsCollector(collector)
+
+	// retries are counted, but not as calls
+	_, err := client.ListShards(project, logstore)
+	require.NoError(t, err)
+	require.Equal(t, 1.0, testutil.ToFloat64(collector.requests.WithLabelValues("ListShards", project, logstore)))
+	require.Equal(t, 1.0, testutil.ToFloat64(collector.retries.WithLabelValues("ListShards", project, logstore)))
+	require.Equal(t, 1, testutil.CollectAndCount(collector.latency))
+	require.Equal(t, float64(len(`[{"shardID":0}]`)), testutil.ToFloat64(collector.receivedBytes.WithLabelValues("ListShards", project, logstore)))
+
+	// both the compressed and raw size of logs are counted
+	logGroup := &LogGroup{Logs: []*Log{{Time: proto.Uint32(uint32(time.Now().Unix())), Contents: []*LogContent{{Key: proto.String("k"), Value: proto.String("v")}}}}}
+	raw, err := proto.Marshal(logGroup)
+	require.NoError(t, err)
+	require.NoError(t, client.PutLogs(project, logstore, logGroup))
+	require.Equal(t, 1.0, testutil.ToFloat64(collector.requests.WithLabelValues("PutLogs", project, logstore)))
+	require.Equal(t, float64(len(raw)), testutil.ToFloat64(collector.rawBytes.WithLabelValues("PutLogs", project, logstore)))
+	require.Greater(t, testutil.ToFloat64(collector.sentBytes.WithLabelValues("PutLogs", project, logstore)), 0.0)
+
+	// errors are labeled by error code
+	_, err = client.GetCheckpoint(project, logstore, "cg")
+	require.Error(t, err)
+	require.Equal(t, 1.0, testutil.ToFloat64(collector.errors.WithLabelValues("GetCheckpoint", project, logstore, "ConsumerGroupNotExist")))
+	require.Equal(t, 1, testutil.CollectAndCount(collector.errors))
+
+	problems, err := testutil.CollectAndLint(collector)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+}
+
+func TestMetricErrorCode(t *testing.T) {
+	require.Equal(t, "Unauthorized", metricErrorCode(&Error{HTTPCode: 401, Code: "Unauthorized"}))
+	require.Equal(t, "502", metricErrorCode(&BadResponseError{HTTPCode: 502}))
+	require.Equal(t, "CircuitOpen", metricErrorCode(&CircuitOpenError{}))
+	require.Equal(t, "ClientError", metricErrorCode(fmt.Errorf("unknown")))
+}
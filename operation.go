@@ -0,0 +1,174 @@
+package sls
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// apiOperation is the logical SLS api of a request, resolved from its method and uri.
+type apiOperation struct {
+	Name     string // eg. PostLogStoreLogs, PullLogs
+	Logstore string // empty if the request is not bound to a logstore
+	Shard    string // empty if the request is not bound to a shard
+}
+
+// singular resource names of uri path segments, used to name the crud apis
+var apiResourceNames = map[string]string{
+	"logstores":       "LogStore",
+	"consumergroups":  "ConsumerGroup",
+	"shards":          "Shard",
+	"machinegroups":   "MachineGroup",
+	"machines":        "Machines",
+	"configs":         "Config",
+	"dashboards":      "Dashboard",
+	"charts":          "Chart",
+	"savedsearches":   "SavedSearch",
+	"jobs":            "Job",
+	"jobinstances":    "JobInstance",
+	"metricsconfigs":  "MetricConfig",
+	"storeviews":      "StoreView",
+	"substores":       "SubStore",
+	"resources":       "Resource",
+	"records":         "ResourceRecord",
+	"index":           "Index",
+	"meteringmode":    "MeteringMode",
+	"policy":          "ProjectPolicy",
+	"tags":            "Tags",
+	"systemtags":      "SystemTags",
+	"shipper":         "Shipper",
+	"tasks":           "ShipperTask",
+	"etlmetas":        "EtlMeta",
+	"etlmetanames":    "EtlMetaName",
+	"logging":         "Logging",
+	"machinegroupsv2": "MachineGroup",
+}
+
+// resolveOperation resolves the logical SLS api of a request.
+func resolveOperation(method, uri string) apiOperation {
+	path, rawQuery := uri, ""
+	if i := strings.IndexByte(uri, '?'); i >= 0 {
+		path, rawQuery = uri[:i], uri[i+1:]
+	}
+	query, _ := url.ParseQuery(rawQuery)
+	segs := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segs) == 1 && segs[0] == "" {
+		segs = nil
+	}
+
+	var op apiOperation
+	if len(segs) >= 2 && segs[0] == "logstores" {
+		op.Logstore = segs[1]
+	}
+	if len(segs) >= 4 && segs[0] == "logstores" && segs[2] == "shards" && segs[3] != "route" {
+		op.Shard = segs[3]
+	}
+
+	switch {
+	case len(segs) == 0:
+		op.Name = crudOperationName(method, "Project", method == http.MethodGet)
+		return op
+	case len(segs) >= 4 && segs[0] == "prometheus":
+		op.Name = "PutLogsWithMetricStoreURL"
+		op.Logstore = segs[2]
+		return op
+	case segs[0] == "logstores" && len(segs) == 2:
+		switch {
+		case method == http.MethodPost:
+			op.Name = "PutLogs"
+		case method == http.MethodGet && query.Get("type") == "histogram":
+			op.Name = "GetHistograms"
+		case method == http.MethodGet && query.Get("type") == "log":
+			op.Name = "GetLogs"
+		case method == http.MethodGet && query.Get("type") == "context_log":
+			op.Name = "GetContextLogs"
+		default:
+			op.Name = crudOperationName(method, "LogStore", false)
+		}
+		return op
+	case segs[0] == "logstores" && len(segs) == 3 && segs[2] == "logs":
+		op.Name = "GetLogsV3"
+		return op
+	case segs[0] == "logstores" && len(segs) == 3 && segs[2] == "shards" && method == http.MethodGet:
+		op.Name = "ListShards"
+		return op
+	case segs[0] == "logstores" && len(segs) == 4 && segs[2] == "shards" && segs[3] == "route":
+		op.Name = "PostLogStoreLogs"
+		return op
+	case segs[0] == "logstores" && len(segs) == 4 && segs[2] == "shards":
+		switch {
+		case method == http.MethodGet && query.Get("type") == "cursor":
+			op.Name = "GetCursor"
+		case method == http.MethodGet && query.Get("type") == "cursor_time":
+			op.Name = "GetCursorTime"
+		case method == http.MethodGet:
+			op.Name = "PullLogs"
+		case query.Get("action") == "split":
+			op.Name = "SplitShard"
+		case query.Get("action") == "merge":
+			op.Name = "MergeShards"
+		default:
+			op.Name = crudOperationName(method, "Shard", false)
+		}
+		return op
+	case segs[0] == "logstores" && len(segs) == 4 && segs[2] == "consumergroups":
+		switch {
+		case query.Get("type") == "heartbeat":
+			op.Name = "HeartBeat"
+		case query.Get("type") == "checkpoint":
+			op.Name = "UpdateCheckpoint"
+		case method == http.MethodGet:
+			op.Name = "GetCheckpoint"
+		default:
+			op.Name = crudOperationName(method, "ConsumerGroup", false)
+		}
+		return op
+	case segs[0] == "jobs" && len(segs) == 2 && query.Get("action") != "":
+		op.Name = strings.Title(strings.ToLower(query.Get("action"))) + "Job"
+		return op
+	}
+
+	// generic restful apis, named by the last resource segment
+	last := len(segs) - 1
+	isCollection := len(segs)%2 == 1
+	resource := segs[last]
+	if !isCollection {
+		resource = segs[last-1]
+	}
+	name, ok := apiResourceNames[resource]
+	if !ok {
+		name = strings.Title(resource)
+	}
+	op.Name = crudOperationName(method, name, isCollection)
+	return op
+}
+
+func crudOperationName(method, resource string, isCollection bool) string {
+	switch method {
+	case http.MethodGet:
+		if isCollection {
+			return "List" + resource
+		}
+		return "Get" + resource
+	case http.MethodPost:
+		return "Create" + resource
+	case http.MethodPut:
+		return "Update" + resource
+	case http.MethodDelete:
+		return "Delete" + resource
+	}
+	return strings.Title(strings.ToLower(method)) + resource
+}
+
+// class returns the operation class of op for rate limiting.
+func (op apiOperation) class() OperationClass {
+	switch op.Name {
+	case "PutLogs", "PostLogStoreLogs", "PutLogsWithMetricStoreURL":
+		return OperationClassWrite
+	case "PullLogs", "GetCursor", "GetCursorTime":
+		return OperationClassRead
+	case "GetLogs", "GetLogsV3", "GetHistograms", "GetContextLogs":
+		return OperationClassQuery
+	}
+	return OperationClassAdmin
+}
@@ -0,0 +1,273 @@
+package sls
+
+import "context"
+
+// DefaultPageSize is the number of items fetched per request by the pagers returned by the ListAll* functions.
+const DefaultPageSize = 100
+
+// OffsetPageFunc fetches at most size items starting at offset, and returns the total number of items,
+// or -1 if the list api does not return it.
+type OffsetPageFunc[T any] func(ctx context.Context, offset, size int) (items []T, total int, err error)
+
+// TokenPageFunc fetches the page of items at token, which is empty for the first page,
+// and returns the token of the next page, which is empty for the last page.
+type TokenPageFunc[T any] func(ctx context.Context, token string) (items []T, nextToken string, err error)
+
+// Pager walks all items of a list api lazily, fetching a page only when the items fetched are consumed.
+// It stops at the first error, including the error of ctx.
+//
+//	pager := ListAllLogStores(client, project, "")
+//	for pager.Next(ctx) {
+//		fmt.Println(pager.Item())
+//	}
+//	if err := pager.Err(); err != nil {
+//		return err
+//	}
+type Pager[T any] struct {
+	fetch func(ctx context.Context) (items []T, last bool, err error)
+	page  []T
+	item  T
+	last  bool // the page fetched is the last one
+	err   error
+}
+
+// NewOffsetPager returns a Pager of an offset based list api, fetching pageSize items per request.
+// It stops after a page of less than pageSize items, or after total items if total is known.
+func NewOffsetPager[T any](pageSize int, fetch OffsetPageFunc[T]) *Pager[T] {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+	offset := 0
+	return &Pager[T]{
+		fetch: func(ctx context.Context) ([]T, bool, error) {
+			items, total, err := fetch(ctx, offset, pageSize)
+			if err != nil {
+				return nil, false, err
+			}
+			offset += len(items)
+			if total >= 0 {
+				return items, len(items) == 0 || offset >= total, nil
+			}
+			return items, len(items) < pageSize, nil
+		},
+	}
+}
+
+// NewTokenPager returns a Pager of a token based list api, it stops once the next token is empty.
+func NewTokenPager[T any](fetch TokenPageFunc[T]) *Pager[T] {
+	token := ""
+	return &Pager[T]{
+		fetch: func(ctx context.Context) ([]T, bool, error) {
+			items, nextToken, err := fetch(ctx, token)
+			if err != nil {
+				return nil, false, err
+			}
+			token = nextToken
+			return items, nextToken == "", nil
+		},
+	}
+}
+
+// Next advances to the next item, which is then available through Item.
+// It returns false when all items are walked or on error, which is returned by Err.
+func (p *Pager[T]) Next(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+	for len(p.page) == 0 {
+		if p.last {
+			return false
+		}
+		p.page, p.last, p.err = p.fetch(ctx)
+		if p.err != nil {
+			return false
+		}
+	}
+	p.item, p.page = p.page[0], p.page[1:]
+	return true
+}
+
+// Item returns the current item.
+func (p *Pager[T]) Item() T {
+	return p.item
+}
+
+// Err returns the error which stopped the pager, or nil if all items are walked.
+func (p *Pager[T]) Err() error {
+	return p.err
+}
+
+// All walks the remaining items and returns them, along with the error which stopped the pager if any.
+func (p *Pager[T]) All(ctx context.Context) ([]T, error) {
+	items := []T{}
+	for p.Next(ctx) {
+		items = append(items, p.Item())
+	}
+	return items, p.Err()
+}
+
+// ListAllProjects returns a Pager of all projects in the region of the client.
+func ListAllProjects(client ClientInterface) *Pager[LogProject] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]LogProject, int, error) {
+		projects, _, total, err := client.ListProjectV2(offset, size)
+		return projects, total, err
+	})
+}
+
+// ListAllLogStores returns a Pager of the names of logstores of telemetryType in project, see ListLogStoreV2.
+func ListAllLogStores(client ClientInterface, project, telemetryType string) *Pager[string] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]string, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			logstores, err := c.ListLogStoreV2Ctx(ctx, project, offset, size, telemetryType)
+			return logstores, -1, err
+		}
+		logstores, err := client.ListLogStoreV2(project, offset, size, telemetryType)
+		return logstores, -1, err
+	})
+}
+
+// ListAllMachineGroups returns a Pager of the names of machine groups in project.
+func ListAllMachineGroups(client ClientInterface, project string) *Pager[string] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]string, int, error) {
+		return client.ListMachineGroup(project, offset, size)
+	})
+}
+
+// ListAllConfigs returns a Pager of the names of logtail configs in project.
+func ListAllConfigs(client ClientInterface, project string) *Pager[string] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]string, int, error) {
+		return client.ListConfig(project, offset, size)
+	})
+}
+
+// ListAllDashboards returns a Pager of the dashboards in project whose name contains dashboardName.
+func ListAllDashboards(client ClientInterface, project, dashboardName string) *Pager[ResponseDashboardItem] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]ResponseDashboardItem, int, error) {
+		_, items, _, total, err := client.ListDashboardV2(project, dashboardName, offset, size)
+		return items, total, err
+	})
+}
+
+// ListAllSavedSearches returns a Pager of the saved searches in project whose name contains savedSearchName.
+func ListAllSavedSearches(client ClientInterface, project, savedSearchName string) *Pager[ResponseSavedSearchItem] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]ResponseSavedSearchItem, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			_, items, total, _, err := c.ListSavedSearchV2Ctx(ctx, project, savedSearchName, offset, size)
+			return items, total, err
+		}
+		_, items, total, _, err := client.ListSavedSearchV2(project, savedSearchName, offset, size)
+		return items, total, err
+	})
+}
+
+// ListAllAlerts returns a Pager of the alerts in project, filtered by alertName and dashboard as ListAlert does.
+func ListAllAlerts(client ClientInterface, project, alertName, dashboard string) *Pager[*Alert] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*Alert, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			alerts, total, _, err := c.ListAlertCtx(ctx, project, alertName, dashboard, offset, size)
+			return alerts, total, err
+		}
+		alerts, total, _, err := client.ListAlert(project, alertName, dashboard, offset, size)
+		return alerts, total, err
+	})
+}
+
+// ListAllETLs returns a Pager of the ETL jobs in project.
+func ListAllETLs(client ClientInterface, project string) *Pager[*ETL] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*ETL, int, error) {
+		var resp *ListETLResponse
+		var err error
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			resp, err = c.ListETLCtx(ctx, project, offset, size)
+		} else {
+			resp, err = client.ListETL(project, offset, size)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		return resp.Results, resp.Total, nil
+	})
+}
+
+// ListAllEtlMetas returns a Pager of the etl metas named etlMetaName in project.
+func ListAllEtlMetas(client ClientInterface, project, etlMetaName string) *Pager[*EtlMeta] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*EtlMeta, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			total, _, etlMetas, err := c.ListEtlMetaCtx(ctx, project, etlMetaName, offset, size)
+			return etlMetas, total, err
+		}
+		total, _, etlMetas, err := client.ListEtlMeta(project, etlMetaName, offset, size)
+		return etlMetas, total, err
+	})
+}
+
+// ListAllScheduledSQLs returns a Pager of the scheduled SQL jobs in project, filtered by name and displayName.
+func ListAllScheduledSQLs(client ClientInterface, project, name, displayName string) *Pager[*ScheduledSQL] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*ScheduledSQL, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			scheduledSQLs, total, _, err := c.ListScheduledSQLCtx(ctx, project, name, displayName, offset, size)
+			return scheduledSQLs, total, err
+		}
+		scheduledSQLs, total, _, err := client.ListScheduledSQL(project, name, displayName, offset, size)
+		return scheduledSQLs, total, err
+	})
+}
+
+// ListAllIngestions returns a Pager of the ingestion jobs in project, filtered by logstore, name and displayName.
+func ListAllIngestions(client ClientInterface, project, logstore, name, displayName string) *Pager[*Ingestion] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*Ingestion, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			ingestions, total, _, err := c.ListIngestionCtx(ctx, project, logstore, name, displayName, offset, size)
+			return ingestions, total, err
+		}
+		ingestions, total, _, err := client.ListIngestion(project, logstore, name, displayName, offset, size)
+		return ingestions, total, err
+	})
+}
+
+// ListAllExports returns a Pager of the export jobs in project, filtered by logstore, name and displayName.
+func ListAllExports(client ClientInterface, project, logstore, name, displayName string) *Pager[*Export] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*Export, int, error) {
+		if c, ok := client.(ClientInterfaceWithContext); ok {
+			exports, total, _, err := c.ListExportCtx(ctx, project, logstore, name, displayName, offset, size)
+			return exports, total, err
+		}
+		exports, total, _, err := client.ListExport(project, logstore, name, displayName, offset, size)
+		return exports, total, err
+	})
+}
+
+// ListAllResources returns a Pager of the resources of resourceType, filtered by resourceName.
+func ListAllResources(client ClientInterface, resourceType, resourceName string) *Pager[*Resource] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*Resource, int, error) {
+		resources, _, total, err := client.ListResource(resourceType, resourceName, offset, size)
+		return resources, total, err
+	})
+}
+
+// ListAllResourceRecords returns a Pager of the records of resource resourceName.
+func ListAllResourceRecords(client ClientInterface, resourceName string) *Pager[*ResourceRecord] {
+	return NewOffsetPager(DefaultPageSize, func(ctx context.Context, offset, size int) ([]*ResourceRecord, int, error) {
+		records, _, total, err := client.ListResourceRecord(resourceName, offset, size)
+		return records, total, err
+	})
+}
+
+// ListAllTagResources returns a Pager of the tags of resources, see ListTagResources.
+func ListAllTagResources(client ClientInterface, project, resourceType string, resourceIDs []string, tags []ResourceFilterTag) *Pager[*ResourceTagResponse] {
+	return NewTokenPager(func(ctx context.Context, token string) ([]*ResourceTagResponse, string, error) {
+		return client.ListTagResources(project, resourceType, resourceIDs, tags, token)
+	})
+}
+
+// ListAllSystemTagResources returns a Pager of the system tags of resources, see ListSystemTagResources.
+func ListAllSystemTagResources(client ClientInterface, project, resourceType string, resourceIDs []string, tags []ResourceFilterTag,
+	tagOwnerUid, category, scope string) *Pager[*ResourceTagResponse] {
+	return NewTokenPager(func(ctx context.Context, token string) ([]*ResourceTagResponse, string, error) {
+		return client.ListSystemTagResources(project, resourceType, resourceIDs, tags, tagOwnerUid, category, scope, token)
+	})
+}
@@ -0,0 +1,92 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOffsetPager(t *testing.T) {
+	client := NewFakeClient()
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	var names []string
+	for i := 0; i < 250; i++ {
+		name := fmt.Sprintf("logstore-%03d", i)
+		names = append(names, name)
+		require.NoError(t, client.CreateLogStore("test-project", name, 1, 1, false, 0))
+	}
+	logstores, err := ListAllLogStores(client, "test-project", "").All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, names, logstores)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, client.CreateAlert("test-project", &Alert{Name: fmt.Sprintf("alert-%d", i)}))
+	}
+	alerts, err := ListAllAlerts(client, "test-project", "", "").All(context.Background())
+	require.NoError(t, err)
+	require.Len(t, alerts, 3)
+
+	// the total stops the pager without fetching an empty page
+	var requests int
+	pager := NewOffsetPager(2, func(ctx context.Context, offset, size int) ([]int, int, error) {
+		requests++
+		items := []int{}
+		for i := offset; i < offset+size && i < 4; i++ {
+			items = append(items, i)
+		}
+		return items, 4, nil
+	})
+	items, err := pager.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3}, items)
+	require.Equal(t, 2, requests)
+
+	_, err = ListAllETLs(client, "test-project").All(context.Background())
+	require.Equal(t, NOT_SUPPORTED, err.(*Error).Code)
+}
+
+func TestTokenPager(t *testing.T) {
+	var tokens []string
+	pager := NewTokenPager(func(ctx context.Context, token string) ([]string, string, error) {
+		tokens = append(tokens, token)
+		i, _ := strconv.Atoi(token)
+		if i == 2 {
+			return []string{"c"}, "", nil
+		}
+		return []string{string(rune('a' + i))}, strconv.Itoa(i + 1), nil
+	})
+	items, err := pager.All(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "b", "c"}, items)
+	require.Equal(t, []string{"", "1", "2"}, tokens)
+	require.False(t, pager.Next(context.Background()))
+}
+
+func TestPagerStop(t *testing.T) {
+	errFetch := errors.New("fetch error")
+	pager := NewTokenPager(func(ctx context.Context, token string) ([]int, string, error) {
+		if token != "" {
+			return nil, "", errFetch
+		}
+		return []int{1, 2}, "next", nil
+	})
+	items, err := pager.All(context.Background())
+	require.ErrorIs(t, err, errFetch)
+	require.Equal(t, []int{1, 2}, items)
+	require.False(t, pager.Next(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	pager = NewTokenPager(func(ctx context.Context, token string) ([]int, string, error) {
+		return []int{1, 2}, "next", nil
+	})
+	require.True(t, pager.Next(ctx))
+	require.Equal(t, 1, pager.Item())
+	cancel()
+	require.False(t, pager.Next(ctx))
+	require.ErrorIs(t, pager.Err(), context.Canceled)
+}
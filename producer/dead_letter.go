@@ -0,0 +1,303 @@
+package producer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log/level"
+	"github.com/gogo/protobuf/proto"
+)
+
+// DeadLetter is a batch failed to send after retries, with the attempts of it.
+type DeadLetter struct {
+	Project           string
+	Logstore          string
+	ShardHash         *string `json:",omitempty"`
+	UseMetricStoreURL bool    `json:",omitempty"`
+	LogGroup          *sls.LogGroup
+	Attempts          []*Attempt
+	FailTimeMs        int64
+}
+
+// DeadLetterSink receives the batches failed to send after retries, once CallBack.Fail of them is called.
+// The batches kept in the spool, whose Result.IsSpooled is true, are not written to it.
+// Write is called concurrently by the io workers. The sink is not closed by the producer, close it
+// after the producer is closed if needed.
+type DeadLetterSink interface {
+	Write(letter *DeadLetter) error
+}
+
+// DeadLetterFileFormat is the format of the file written by FileDeadLetterSink.
+type DeadLetterFileFormat string
+
+const (
+	// DeadLetterNDJSON writes each dead letter as a line of json.
+	DeadLetterNDJSON DeadLetterFileFormat = "ndjson"
+	// DeadLetterProtobuf writes each dead letter as a log group with the dead letter tags,
+	// prefixed with its size in uvarint.
+	DeadLetterProtobuf DeadLetterFileFormat = "protobuf"
+)
+
+// Tags of the log groups of dead letters written by DeadLetterProtobuf and LogstoreDeadLetterSink.
+const (
+	DeadLetterTagProject           = "__dead_letter_project__"
+	DeadLetterTagLogstore          = "__dead_letter_logstore__"
+	DeadLetterTagShardHash         = "__dead_letter_shard_hash__"
+	DeadLetterTagUseMetricStoreURL = "__dead_letter_use_metric_store_url__"
+	DeadLetterTagAttempts          = "__dead_letter_attempts__" // attempts in json
+	DeadLetterTagFailTimeMs        = "__dead_letter_fail_time_ms__"
+)
+
+// FileDeadLetterSink appends dead letters to a local file.
+type FileDeadLetterSink struct {
+	mu     sync.Mutex
+	file   *os.File
+	format DeadLetterFileFormat
+	fsync  bool
+}
+
+// NewFileDeadLetterSink opens the file at path to append dead letters in format, each dead letter is fsynced if fsync.
+// The dead letters can be sent again by ReplayDeadLetterFile.
+func NewFileDeadLetterSink(path string, format DeadLetterFileFormat, fsync bool) (*FileDeadLetterSink, error) {
+	if format != DeadLetterNDJSON && format != DeadLetterProtobuf {
+		return nil, fmt.Errorf("unknown dead letter file format %s", format)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	return &FileDeadLetterSink{file: file, format: format, fsync: fsync}, nil
+}
+
+func (s *FileDeadLetterSink) Write(letter *DeadLetter) error {
+	var data []byte
+	if s.format == DeadLetterNDJSON {
+		line, err := json.Marshal(letter)
+		if err != nil {
+			return err
+		}
+		data = append(line, '\n')
+	} else {
+		logGroup, err := letter.toLogGroup().Marshal()
+		if err != nil {
+			return err
+		}
+		data = append(binary.AppendUvarint(nil, uint64(len(logGroup))), logGroup...)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.file.Write(data); err != nil {
+		return err
+	}
+	if s.fsync {
+		return s.file.Sync()
+	}
+	return nil
+}
+
+func (s *FileDeadLetterSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// LogstoreDeadLetterSink sends dead letters to a secondary logstore, as log groups with the dead letter tags,
+// which can be converted back by DeadLetterFromLogGroup.
+type LogstoreDeadLetterSink struct {
+	client   sls.ClientInterface
+	project  string
+	logstore string
+}
+
+// NewLogstoreDeadLetterSink returns a sink sending dead letters to logstore of project with client,
+// which should not share the failure of the producer, eg. in another region.
+func NewLogstoreDeadLetterSink(client sls.ClientInterface, project, logstore string) *LogstoreDeadLetterSink {
+	return &LogstoreDeadLetterSink{
+		client:   client,
+		project:  project,
+		logstore: logstore,
+	}
+}
+
+func (s *LogstoreDeadLetterSink) Write(letter *DeadLetter) error {
+	return s.client.PostLogStoreLogsV2(s.project, s.logstore, &sls.PostLogStoreLogsRequest{
+		LogGroup:     letter.toLogGroup(),
+		CompressType: sls.Compress_LZ4,
+	})
+}
+
+// toLogGroup returns a copy of the log group of letter, with the dead letter tags.
+func (letter *DeadLetter) toLogGroup() *sls.LogGroup {
+	attempts, _ := json.Marshal(letter.Attempts)
+	logGroup := *letter.LogGroup
+	logGroup.LogTags = append(make([]*sls.LogTag, 0, len(letter.LogGroup.LogTags)+6), letter.LogGroup.LogTags...)
+	addTag := func(key, value string) {
+		logGroup.LogTags = append(logGroup.LogTags, &sls.LogTag{Key: proto.String(key), Value: proto.String(value)})
+	}
+	addTag(DeadLetterTagProject, letter.Project)
+	addTag(DeadLetterTagLogstore, letter.Logstore)
+	if letter.ShardHash != nil {
+		addTag(DeadLetterTagShardHash, *letter.ShardHash)
+	}
+	if letter.UseMetricStoreURL {
+		addTag(DeadLetterTagUseMetricStoreURL, "true")
+	}
+	addTag(DeadLetterTagAttempts, string(attempts))
+	addTag(DeadLetterTagFailTimeMs, strconv.FormatInt(letter.FailTimeMs, 10))
+	return &logGroup
+}
+
+// DeadLetterFromLogGroup converts a log group written by DeadLetterProtobuf or LogstoreDeadLetterSink,
+// eg. pulled from the secondary logstore, back to the dead letter.
+func DeadLetterFromLogGroup(logGroup *sls.LogGroup) (*DeadLetter, error) {
+	letter := &DeadLetter{}
+	stripped := *logGroup
+	stripped.LogTags = make([]*sls.LogTag, 0, len(logGroup.LogTags))
+	for _, tag := range logGroup.LogTags {
+		switch tag.GetKey() {
+		case DeadLetterTagProject:
+			letter.Project = tag.GetValue()
+		case DeadLetterTagLogstore:
+			letter.Logstore = tag.GetValue()
+		case DeadLetterTagShardHash:
+			letter.ShardHash = proto.String(tag.GetValue())
+		case DeadLetterTagUseMetricStoreURL:
+			letter.UseMetricStoreURL = tag.GetValue() == "true"
+		case DeadLetterTagAttempts:
+			if err := json.Unmarshal([]byte(tag.GetValue()), &letter.Attempts); err != nil {
+				return nil, fmt.Errorf("invalid tag %s: %w", DeadLetterTagAttempts, err)
+			}
+		case DeadLetterTagFailTimeMs:
+			letter.FailTimeMs, _ = strconv.ParseInt(tag.GetValue(), 10, 64)
+		default:
+			stripped.LogTags = append(stripped.LogTags, tag)
+		}
+	}
+	if letter.Project == "" || letter.Logstore == "" {
+		return nil, errors.New("log group is not a dead letter")
+	}
+	letter.LogGroup = &stripped
+	return letter, nil
+}
+
+// ReadDeadLetterFile calls fn with each dead letter in the file at path written by FileDeadLetterSink,
+// until fn returns an error.
+func ReadDeadLetterFile(path string, format DeadLetterFileFormat, fn func(letter *DeadLetter) error) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	reader := bufio.NewReader(file)
+	switch format {
+	case DeadLetterNDJSON:
+		decoder := json.NewDecoder(reader)
+		for {
+			letter := &DeadLetter{}
+			if err := decoder.Decode(letter); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			if err := fn(letter); err != nil {
+				return err
+			}
+		}
+	case DeadLetterProtobuf:
+		for {
+			size, err := binary.ReadUvarint(reader)
+			if err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(reader, data); err != nil {
+				return err
+			}
+			logGroup := &sls.LogGroup{}
+			if err := logGroup.Unmarshal(data); err != nil {
+				return err
+			}
+			letter, err := DeadLetterFromLogGroup(logGroup)
+			if err != nil {
+				return err
+			}
+			if err := fn(letter); err != nil {
+				return err
+			}
+		}
+	}
+	return fmt.Errorf("unknown dead letter file format %s", format)
+}
+
+// ReplayDeadLetterFile sends the dead letters in the file at path again with producer, see Producer.ReplayDeadLetter,
+// and returns the number of dead letters replayed.
+//
+// The file is not modified, remove it once the replayed batches are sent.
+func ReplayDeadLetterFile(producer *Producer, path string, format DeadLetterFileFormat, callback CallBack) (int, error) {
+	count := 0
+	err := ReadDeadLetterFile(path, format, func(letter *DeadLetter) error {
+		if err := producer.ReplayDeadLetter(letter, callback); err != nil {
+			return err
+		}
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// ReplayDeadLetter sends the log group of letter again as a whole batch, callback is called once it is sent
+// successfully or failed, and may be nil. The batch goes to the DeadLetterSink again if it fails.
+func (producer *Producer) ReplayDeadLetter(letter *DeadLetter, callback CallBack) error {
+	if err := producer.waitTime(); err != nil {
+		return err
+	}
+	if producer.logAccumulator.shutDownFlag.Load() {
+		return errors.New("Producer has started and shut down and cannot write to new logs")
+	}
+	batch := newSealedProducerBatch(letter.Project, letter.Logstore, letter.ShardHash, letter.UseMetricStoreURL,
+		letter.LogGroup, producer.producerConfig)
+	batch.seq = producer.pendingBatches.add()
+	if callback != nil {
+		batch.callBackList = append(batch.callBackList, callback)
+	}
+	atomic.AddInt64(&producer.producerLogGroupSize, batch.totalDataSize)
+	producer.threadPool.addTask(batch)
+	return nil
+}
+
+// sendToDeadLetterSink writes the failed batch to the DeadLetterSink, and returns false if it fails.
+func (producer *Producer) sendToDeadLetterSink(batch *ProducerBatch) bool {
+	sink := producer.producerConfig.DeadLetterSink
+	if sink == nil {
+		return true
+	}
+	err := sink.Write(&DeadLetter{
+		Project:           batch.project,
+		Logstore:          batch.logstore,
+		ShardHash:         batch.shardHash,
+		UseMetricStoreURL: batch.useMetricStoreUrl,
+		LogGroup:          batch.logGroup,
+		Attempts:          batch.result.attemptList,
+		FailTimeMs:        time.Now().UnixMilli(),
+	})
+	if err != nil {
+		level.Error(producer.logger).Log("msg", "fail to write batch to dead letter sink",
+			"project", batch.project, "logstore", batch.logstore, "logs", len(batch.logGroup.Logs), "error", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,169 @@
+package producer
+
+import (
+	"context"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+type deadLetterRecorder struct {
+	letters chan *DeadLetter
+}
+
+func (r *deadLetterRecorder) Write(letter *DeadLetter) error {
+	r.letters <- letter
+	return nil
+}
+
+func TestFileDeadLetterSink(t *testing.T) {
+	config := GetDefaultProducerConfig()
+	batch := newSpoolTestBatch(config, "hash", 3)
+	batch.OnFail(&sls.Error{HTTPCode: 404, Code: sls.LOGSTORE_NOT_EXIST, RequestID: "request-id"}, time.Now())
+	for _, format := range []DeadLetterFileFormat{DeadLetterNDJSON, DeadLetterProtobuf} {
+		t.Run(string(format), func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "dead_letters")
+			sink, err := NewFileDeadLetterSink(path, format, true)
+			require.NoError(t, err)
+			config.DeadLetterSink = sink
+			producer := &Producer{producerConfig: config, logger: log.NewNopLogger()}
+			require.True(t, producer.sendToDeadLetterSink(batch))
+			require.True(t, producer.sendToDeadLetterSink(batch))
+			require.NoError(t, sink.Close())
+
+			var letters []*DeadLetter
+			require.NoError(t, ReadDeadLetterFile(path, format, func(letter *DeadLetter) error {
+				letters = append(letters, letter)
+				return nil
+			}))
+			require.Len(t, letters, 2)
+			for _, letter := range letters {
+				require.Equal(t, "test-project", letter.Project)
+				require.Equal(t, "test-logstore", letter.Logstore)
+				require.Equal(t, "hash", *letter.ShardHash)
+				require.Equal(t, "topic", letter.LogGroup.GetTopic())
+				require.Empty(t, letter.LogGroup.LogTags)
+				require.Len(t, letter.LogGroup.Logs, 3)
+				require.Equal(t, batch.logGroup.Logs[2].Contents[0].GetValue(), letter.LogGroup.Logs[2].Contents[0].GetValue())
+				require.Len(t, letter.Attempts, 1)
+				require.Equal(t, sls.LOGSTORE_NOT_EXIST, letter.Attempts[0].ErrorCode)
+				require.Equal(t, "request-id", letter.Attempts[0].RequestId)
+			}
+		})
+	}
+}
+
+func TestProducerDeadLetter(t *testing.T) {
+	config := GetDefaultProducerConfig()
+	config.LingerMs = 100
+	recorder := &deadLetterRecorder{letters: make(chan *DeadLetter, 10)}
+	config.DeadLetterSink = recorder
+	client := sls.NewFakeClient()
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+
+	// the logstore does not exist, and 404 is not retried
+	producer := createProducerInternal(client, config, log.NewNopLogger())
+	producer.Start()
+	require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "source",
+		GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "dead"})))
+	var letter *DeadLetter
+	select {
+	case letter = <-recorder.letters:
+	case <-time.After(10 * time.Second):
+		t.Fatal("no dead letter")
+	}
+	require.Equal(t, "test-logstore", letter.Logstore)
+	require.Equal(t, sls.LOGSTORE_NOT_EXIST, letter.Attempts[len(letter.Attempts)-1].ErrorCode)
+
+	// the dead letter is sent once the logstore is created
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 0))
+	callback := &deadLetterCallback{done: make(chan *Result, 1)}
+	require.NoError(t, producer.ReplayDeadLetter(letter, callback))
+	require.True(t, (<-callback.done).IsSuccessful())
+	producer.SafeClose()
+	require.Empty(t, recorder.letters)
+
+	begin, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	logGroups, _, err := client.PullLogs("test-project", "test-logstore", 0, begin, "", 100)
+	require.NoError(t, err)
+	require.Len(t, logGroups.LogGroups, 1)
+	require.Equal(t, "dead", logGroups.LogGroups[0].Logs[0].Contents[0].GetValue())
+}
+
+type deadLetterCallback struct {
+	done chan *Result
+}
+
+func (c *deadLetterCallback) Success(result *Result) { c.done <- result }
+func (c *deadLetterCallback) Fail(result *Result)    { c.done <- result }
+
+func TestLogstoreDeadLetterSink(t *testing.T) {
+	client := sls.NewFakeClient()
+	_, err := client.CreateProject("dead-letter-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("dead-letter-project", "dead-letters", 1, 1, false, 0))
+	config := GetDefaultProducerConfig()
+	batch := newSpoolTestBatch(config, "", 2)
+	batch.OnFail(&sls.Error{HTTPCode: 400, Code: sls.PARAMETER_INVALID}, time.Now())
+	config.DeadLetterSink = NewLogstoreDeadLetterSink(client, "dead-letter-project", "dead-letters")
+	producer := &Producer{producerConfig: config, logger: log.NewNopLogger()}
+	require.True(t, producer.sendToDeadLetterSink(batch))
+
+	begin, err := client.GetCursor("dead-letter-project", "dead-letters", 0, "begin")
+	require.NoError(t, err)
+	logGroups, _, err := client.PullLogs("dead-letter-project", "dead-letters", 0, begin, "", 100)
+	require.NoError(t, err)
+	require.Len(t, logGroups.LogGroups, 1)
+	letter, err := DeadLetterFromLogGroup(logGroups.LogGroups[0])
+	require.NoError(t, err)
+	require.Equal(t, "test-project", letter.Project)
+	require.Equal(t, "test-logstore", letter.Logstore)
+	require.Nil(t, letter.ShardHash)
+	require.Len(t, letter.LogGroup.Logs, 2)
+	require.Equal(t, sls.PARAMETER_INVALID, letter.Attempts[0].ErrorCode)
+}
+
+// failingClient fails the sends of batches with a retryable error.
+type failingClient struct {
+	sls.ClientInterface
+	sends atomic.Int32
+}
+
+func (c *failingClient) PostLogStoreLogsV2(project, logstore string, req *sls.PostLogStoreLogsRequest) error {
+	c.sends.Add(1)
+	return &sls.Error{HTTPCode: 500, Code: "InternalServerError", Message: "internal error"}
+}
+
+func TestProducerDeadLetterSpooled(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	config.LingerMs = 100
+	recorder := &deadLetterRecorder{letters: make(chan *DeadLetter, 10)}
+	config.DeadLetterSink = recorder
+	client := &failingClient{ClientInterface: sls.NewFakeClient()}
+
+	producer := createProducerInternal(client, config, log.NewNopLogger())
+	require.NoError(t, producer.openSpool())
+	producer.Start()
+	ch := producer.SendAsync(context.Background(), "test-project", "test-logstore", "topic", "source",
+		GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "spooled"}))
+	require.Eventually(t, func() bool { return client.sends.Load() >= 1 }, 5*time.Second, 10*time.Millisecond)
+	producer.SafeClose()
+
+	// the batch failed only because the producer is closing is kept in the spool
+	result := <-ch
+	require.False(t, result.IsSuccessful())
+	require.True(t, result.IsSpooled())
+	require.Empty(t, recorder.letters)
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	defer s.close()
+	require.Len(t, recovered, 1)
+}
@@ -0,0 +1,91 @@
+package producer
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// pendingBatches tracks the batches created but not sent successfully or failed yet, by the seq of them.
+type pendingBatches struct {
+	mu      sync.Mutex
+	lastSeq uint64
+	seqs    map[uint64]struct{}
+	done    chan struct{} // closed and replaced once a batch is done
+}
+
+func newPendingBatches() *pendingBatches {
+	return &pendingBatches{
+		seqs: make(map[uint64]struct{}),
+		done: make(chan struct{}),
+	}
+}
+
+func (p *pendingBatches) add() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastSeq++
+	p.seqs[p.lastSeq] = struct{}{}
+	return p.lastSeq
+}
+
+func (p *pendingBatches) remove(seq uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.seqs[seq]; !ok {
+		return
+	}
+	delete(p.seqs, seq)
+	close(p.done)
+	p.done = make(chan struct{})
+}
+
+func (p *pendingBatches) last() uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.lastSeq
+}
+
+// wait blocks until all batches created before or at seq are done, or ctx is done.
+func (p *pendingBatches) wait(ctx context.Context, seq uint64) error {
+	for {
+		p.mu.Lock()
+		pending := false
+		for s := range p.seqs {
+			if s <= seq {
+				pending = true
+				break
+			}
+		}
+		done := p.done
+		p.mu.Unlock()
+		if !pending {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-done:
+		}
+	}
+}
+
+// Flush sends all logs added before it at once without waiting for LingerMs, and the batches waiting to retry
+// without waiting for the backoff, then waits until the batches of the logs are sent successfully or failed,
+// or ctx is done. It returns ctx.Err() if ctx is done first, the logs are still sent after that.
+//
+// Batches failed after Flush are reported by CallBack.Fail and the DeadLetterSink as usual, Flush does not
+// tell whether the logs are sent successfully.
+func (producer *Producer) Flush(ctx context.Context) error {
+	if producer.logAccumulator.shutDownFlag.Load() {
+		return errors.New("Producer has started and shut down and cannot flush logs")
+	}
+	batches, lastSeq := producer.logAccumulator.sealAll()
+	for _, batch := range batches {
+		producer.threadPool.addTask(batch)
+	}
+	for _, batch := range producer.mover.retryQueue.getRetryBatch(true) {
+		producer.threadPool.addTask(batch)
+	}
+	return producer.pendingBatches.wait(ctx, lastSeq)
+}
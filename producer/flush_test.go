@@ -0,0 +1,42 @@
+package producer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlush(t *testing.T) {
+	config := GetDefaultProducerConfig()
+	producer, client := newFakeClientProducer(t, config)
+	producer.producerConfig.LingerMs = 2000
+	for i := 0; i < 10; i++ {
+		require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "source",
+			GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "test"})))
+	}
+
+	// the producer is not started
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, producer.Flush(ctx), context.DeadlineExceeded)
+
+	producer.Start()
+	ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "other-source",
+		GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "test"})))
+	require.NoError(t, producer.Flush(ctx))
+	begin, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	logGroups, _, err := client.PullLogs("test-project", "test-logstore", 0, begin, "", 100)
+	require.NoError(t, err)
+	require.Len(t, logGroups.LogGroups, 2)
+	require.Equal(t, 11, len(logGroups.LogGroups[0].Logs)+len(logGroups.LogGroups[1].Logs))
+
+	// nothing to flush
+	require.NoError(t, producer.Flush(ctx))
+	producer.SafeClose()
+	require.Error(t, producer.Flush(ctx))
+}
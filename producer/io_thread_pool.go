@@ -27,16 +27,16 @@ func initIoThreadPool(ioworker *IoWorker, logger log.Logger) *IoThreadPool {
 }
 
 func (threadPool *IoThreadPool) addTask(batch *ProducerBatch) {
+	threadPool.ioworker.producer.spoolBatch(batch)
 	threadPool.taskCh <- batch
 }
 
 func (threadPool *IoThreadPool) start(ioWorkerWaitGroup *sync.WaitGroup, ioThreadPoolwait *sync.WaitGroup) {
 	defer ioThreadPoolwait.Done()
+	// the pool stops once taskCh is closed by ShutDown
 	for task := range threadPool.taskCh {
 		if task == nil {
-			level.Info(threadPool.logger).Log("msg", "All cache tasks in the thread pool have been successfully sent")
-			threadPool.stopped.Store(true)
-			return
+			break
 		}
 
 		threadPool.ioworker.startSendTask(ioWorkerWaitGroup)
@@ -45,6 +45,8 @@ func (threadPool *IoThreadPool) start(ioWorkerWaitGroup *sync.WaitGroup, ioThrea
 			threadPool.ioworker.sendToServer(producerBatch)
 		}(task)
 	}
+	level.Info(threadPool.logger).Log("msg", "All cache tasks in the thread pool have been successfully sent")
+	threadPool.stopped.Store(true)
 }
 
 func (threadPool *IoThreadPool) ShutDown() {
@@ -1,6 +1,8 @@
 package producer
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -8,9 +10,14 @@ import (
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 	uberatomic "go.uber.org/atomic"
 )
 
+const tracerName = "github.com/aliyun/aliyun-log-go-sdk/producer"
+
 type CallBack interface {
 	Success(result *Result)
 	Fail(result *Result)
@@ -25,9 +32,14 @@ type IoWorker struct {
 	maxIoWorker            chan int64
 	noRetryStatusCodeMap   map[int]*string
 	producer               *Producer
+	tracer                 trace.Tracer
 }
 
 func initIoWorker(client sls.ClientInterface, retryQueue *RetryQueue, logger log.Logger, maxIoWorkerCount int64, errorStatusMap map[int]*string, producer *Producer) *IoWorker {
+	tracerProvider := producer.producerConfig.TracerProvider
+	if tracerProvider == nil {
+		tracerProvider = trace.NewNoopTracerProvider()
+	}
 	return &IoWorker{
 		client:                 client,
 		retryQueue:             retryQueue,
@@ -37,16 +49,30 @@ func initIoWorker(client sls.ClientInterface, retryQueue *RetryQueue, logger log
 		maxIoWorker:            make(chan int64, maxIoWorkerCount),
 		noRetryStatusCodeMap:   errorStatusMap,
 		producer:               producer,
+		tracer:                 tracerProvider.Tracer(tracerName),
 	}
 }
 
 func (ioWorker *IoWorker) sendToServer(producerBatch *ProducerBatch) {
 	level.Debug(ioWorker.logger).Log("msg", "ioworker send data to server")
 	sendBegin := time.Now()
+	parent := trace.ContextWithSpanContext(context.Background(), producerBatch.spanContext)
+	ctx, span := ioWorker.tracer.Start(parent, "sls.producer.sendToServer", trace.WithLinks(producerBatch.spanLinks...), trace.WithAttributes(
+		attribute.String("sls.project", producerBatch.getProject()),
+		attribute.String("sls.logstore", producerBatch.getLogstore()),
+		attribute.Int("sls.producer.attempt", producerBatch.attemptCount+1),
+		attribute.Int("sls.producer.log_count", len(producerBatch.logGroup.Logs)),
+	))
+	defer span.End()
+	ctxClient, withCtx := ioWorker.client.(sls.ClientInterfaceWithContext)
 	var err error
 	if producerBatch.isUseMetricStoreUrl() {
 		// not use compress type now
-		err = ioWorker.client.PutLogsWithMetricStoreURL(producerBatch.getProject(), producerBatch.getLogstore(), producerBatch.logGroup)
+		if withCtx {
+			err = ctxClient.PutLogsWithMetricStoreURLCtx(ctx, producerBatch.getProject(), producerBatch.getLogstore(), producerBatch.logGroup)
+		} else {
+			err = ioWorker.client.PutLogsWithMetricStoreURL(producerBatch.getProject(), producerBatch.getLogstore(), producerBatch.logGroup)
+		}
 	} else {
 		req := &sls.PostLogStoreLogsRequest{
 			LogGroup:     producerBatch.logGroup,
@@ -54,17 +80,28 @@ func (ioWorker *IoWorker) sendToServer(producerBatch *ProducerBatch) {
 			CompressType: ioWorker.producer.producerConfig.CompressType,
 			Processor:    ioWorker.producer.producerConfig.Processor,
 		}
-		err = ioWorker.client.PostLogStoreLogsV2(producerBatch.getProject(), producerBatch.getLogstore(), req)
+		if withCtx {
+			err = ctxClient.PostLogStoreLogsV2Ctx(ctx, producerBatch.getProject(), producerBatch.getLogstore(), req)
+		} else {
+			err = ioWorker.client.PostLogStoreLogsV2(producerBatch.getProject(), producerBatch.getLogstore(), req)
+		}
 	}
 	sendEnd := time.Now()
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
 
 	// send ok
 	if err == nil {
 		level.Debug(ioWorker.logger).Log("msg", "sendToServer success")
-		defer ioWorker.producer.monitor.recordSuccess(sendBegin, sendEnd)
+		// the batch is done after it is recorded
+		defer ioWorker.producer.pendingBatches.remove(producerBatch.seq)
+		defer ioWorker.producer.monitor.recordSuccess(producerBatch, sendBegin, sendEnd)
 		producerBatch.OnSuccess(sendBegin)
+		ioWorker.producer.ackSpooledBatch(producerBatch)
 		// After successful delivery, producer removes the batch size sent out
-		atomic.AddInt64(&ioWorker.producer.producerLogGroupSize, -producerBatch.totalDataSize)
+		ioWorker.producer.releaseMemory(producerBatch.totalDataSize)
 		return
 	}
 
@@ -78,14 +115,24 @@ func (ioWorker *IoWorker) sendToServer(producerBatch *ProducerBatch) {
 		"logs", len(producerBatch.logGroup.Logs),
 		"canRetry", canRetry)
 	if !canRetry {
-		defer ioWorker.producer.monitor.recordFailure(sendBegin, sendEnd)
+		defer ioWorker.producer.pendingBatches.remove(producerBatch.seq)
+		defer ioWorker.producer.monitor.recordFailure(producerBatch, sendBegin, sendEnd)
+		// batches failed only because the producer is closing are kept in the spool, and sent by the next producer
+		producerBatch.result.spooled = producerBatch.spoolID != 0 && ioWorker.retryQueueShutDownFlag.Load() &&
+			ioWorker.retryable(producerBatch, slsError)
 		producerBatch.OnFail(slsError, sendBegin)
-		atomic.AddInt64(&ioWorker.producer.producerLogGroupSize, -producerBatch.totalDataSize)
+		if !producerBatch.result.spooled {
+			// batches failed to write to the dead letter sink are kept in the spool
+			if ioWorker.producer.sendToDeadLetterSink(producerBatch) {
+				ioWorker.producer.ackSpooledBatch(producerBatch)
+			}
+		}
+		ioWorker.producer.releaseMemory(producerBatch.totalDataSize)
 		return
 	}
 
 	// do retry
-	ioWorker.producer.monitor.recordRetry(sendEnd.Sub(sendBegin))
+	ioWorker.producer.monitor.recordRetry(producerBatch, sendEnd.Sub(sendBegin))
 	producerBatch.addAttempt(slsError, sendBegin)
 	producerBatch.nextRetryMs = producerBatch.getRetryBackoffIntervalMs() + time.Now().UnixMilli()
 	level.Debug(ioWorker.logger).Log("msg", "Submit to the retry queue after meeting the retry criteria。")
@@ -93,7 +140,8 @@ func (ioWorker *IoWorker) sendToServer(producerBatch *ProducerBatch) {
 }
 
 func parseSlsError(err error) *sls.Error {
-	if slsError, ok := err.(*sls.Error); ok {
+	var slsError *sls.Error
+	if errors.As(err, &slsError) {
 		return slsError
 	}
 	return &sls.Error{
@@ -105,6 +153,10 @@ func (ioWorker *IoWorker) canRetry(producerBatch *ProducerBatch, err *sls.Error)
 	if ioWorker.retryQueueShutDownFlag.Load() {
 		return false
 	}
+	return ioWorker.retryable(producerBatch, err)
+}
+
+func (ioWorker *IoWorker) retryable(producerBatch *ProducerBatch, err *sls.Error) bool {
 	if _, ok := ioWorker.noRetryStatusCodeMap[int(err.HTTPCode)]; ok {
 		return false
 	}
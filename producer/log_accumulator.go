@@ -1,6 +1,7 @@
 package producer
 
 import (
+	"context"
 	"errors"
 	"strings"
 	"sync"
@@ -9,6 +10,7 @@ import (
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/trace"
 	uberatomic "go.uber.org/atomic"
 )
 
@@ -37,25 +39,26 @@ func initLogAccumulator(config *ProducerConfig, ioWorker *IoWorker, logger log.L
 	}
 }
 
-func (logAccumulator *LogAccumulator) addLogToProducerBatch(project, logstore, shardHash, logTopic, logSource string,
+// addLogToProducerBatch adds logData to the batch of its key, the span of ctx is linked to the send of the batch.
+func (logAccumulator *LogAccumulator) addLogToProducerBatch(ctx context.Context, project, logstore, shardHash, logTopic, logSource string,
 	logData interface{}, callback CallBack) error {
 	if logAccumulator.shutDownFlag.Load() {
 		level.Warn(logAccumulator.logger).Log("msg", "Producer has started and shut down and cannot write to new logs")
 		return errors.New("Producer has started and shut down and cannot write to new logs")
 	}
 	if log, ok := logData.(*sls.Log); ok {
-		logAccumulator.addLog(project, logstore, shardHash, logTopic, logSource, log, callback)
+		logAccumulator.addLog(ctx, project, logstore, shardHash, logTopic, logSource, log, callback)
 		return nil
 	}
 	if logList, ok := logData.([]*sls.Log); ok {
-		logAccumulator.addLogList(project, logstore, shardHash, logTopic, logSource, logList, callback)
+		logAccumulator.addLogList(ctx, project, logstore, shardHash, logTopic, logSource, logList, callback)
 		return nil
 	}
 	level.Error(logAccumulator.logger).Log("msg", "Invalid logType")
 	return errors.New("invalid logType")
 }
 
-func (logAccumulator *LogAccumulator) addLog(project, logstore, shardHash, logTopic, logSource string,
+func (logAccumulator *LogAccumulator) addLog(ctx context.Context, project, logstore, shardHash, logTopic, logSource string,
 	log *sls.Log, callback CallBack) {
 	key := logAccumulator.getKeyString(project, logstore, logTopic, shardHash, logSource)
 	logSize := int64(GetLogSizeCalculate(log))
@@ -64,6 +67,7 @@ func (logAccumulator *LogAccumulator) addLog(project, logstore, shardHash, logTo
 	logAccumulator.lock.Lock()
 	producerBatch := logAccumulator.getOrCreateProducerBatch(key, project, logstore, logTopic, logSource, shardHash)
 	producerBatch.addLog(log, logSize, callback)
+	producerBatch.addSpanContext(trace.SpanContextFromContext(ctx))
 
 	if !producerBatch.meetSendCondition(logAccumulator.producerConfig) {
 		logAccumulator.lock.Unlock()
@@ -76,7 +80,7 @@ func (logAccumulator *LogAccumulator) addLog(project, logstore, shardHash, logTo
 	logAccumulator.threadPool.addTask(producerBatch)
 }
 
-func (logAccumulator *LogAccumulator) addLogList(project, logstore, shardHash, logTopic, logSource string,
+func (logAccumulator *LogAccumulator) addLogList(ctx context.Context, project, logstore, shardHash, logTopic, logSource string,
 	logList []*sls.Log, callback CallBack) {
 	key := logAccumulator.getKeyString(project, logstore, logTopic, shardHash, logSource)
 	logListSize := int64(GetLogListSize(logList))
@@ -85,6 +89,7 @@ func (logAccumulator *LogAccumulator) addLogList(project, logstore, shardHash, l
 	logAccumulator.lock.Lock()
 	producerBatch := logAccumulator.getOrCreateProducerBatch(key, project, logstore, logTopic, logSource, shardHash)
 	producerBatch.addLogList(logList, logListSize, callback)
+	producerBatch.addSpanContext(trace.SpanContextFromContext(ctx))
 
 	if !producerBatch.meetSendCondition(logAccumulator.producerConfig) {
 		logAccumulator.lock.Unlock()
@@ -104,10 +109,25 @@ func (logAccumulator *LogAccumulator) getOrCreateProducerBatch(key, project, log
 
 	logAccumulator.producer.monitor.incCreateBatch()
 	batch := newProducerBatch(logAccumulator.packIdGenrator, project, logstore, logTopic, logSource, shardHash, logAccumulator.producerConfig)
+	batch.seq = logAccumulator.producer.pendingBatches.add()
 	logAccumulator.logGroupData[key] = batch
 	return batch
 }
 
+// sealAll seals all open batches to send, and returns them with the seq of the last batch created.
+func (logAccumulator *LogAccumulator) sealAll() ([]*ProducerBatch, uint64) {
+	logAccumulator.lock.Lock()
+	defer logAccumulator.lock.Unlock()
+	var batches []*ProducerBatch
+	for key, batch := range logAccumulator.logGroupData {
+		if batch != nil {
+			batches = append(batches, batch)
+			logAccumulator.logGroupData[key] = nil
+		}
+	}
+	return batches, logAccumulator.producer.pendingBatches.last()
+}
+
 func (logAccumulator *LogAccumulator) getKeyString(project, logstore, logTopic, shardHash, logSource string) string {
 	var key strings.Builder
 	key.Grow(len(project) + len(logstore) + len(logTopic) + len(shardHash) + len(logSource) + len(Delimiter)*4)
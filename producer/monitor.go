@@ -1,6 +1,7 @@
 package producer
 
 import (
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -23,6 +24,11 @@ type ProducerMetrics struct {
 
 type ProducerMonitor struct {
 	metrics atomic.Value // *ProducerMetrics
+
+	// cumulative since the producer is created, not reset by reportThread
+	waitMemoryFailCount atomic.Int64
+	sendLatency         latencyHistogram
+	logstores           sync.Map // logstoreKey -> *logstoreMetrics
 }
 
 func newProducerMonitor() *ProducerMonitor {
@@ -31,22 +37,41 @@ func newProducerMonitor() *ProducerMonitor {
 	return m
 }
 
-func (m *ProducerMonitor) recordSuccess(sendBegin time.Time, sendEnd time.Time) {
+func (m *ProducerMonitor) recordSuccess(batch *ProducerBatch, sendBegin time.Time, sendEnd time.Time) {
 	metrics := m.metrics.Load().(*ProducerMetrics)
 	metrics.sendBatch.AddSample(float64(sendEnd.Sub(sendBegin).Microseconds()))
 	metrics.onSuccess.AddSample(float64(time.Since(sendEnd).Microseconds()))
+	logstore := m.recordSend(batch, sendEnd.Sub(sendBegin))
+	logstore.successBatches.Add(1)
+	logstore.successLogs.Add(int64(len(batch.logGroup.Logs)))
 }
 
-func (m *ProducerMonitor) recordFailure(sendBegin time.Time, sendEnd time.Time) {
+func (m *ProducerMonitor) recordFailure(batch *ProducerBatch, sendBegin time.Time, sendEnd time.Time) {
 	metrics := m.metrics.Load().(*ProducerMetrics)
 	metrics.sendBatch.AddSample(float64(sendEnd.Sub(sendBegin).Microseconds()))
 	metrics.onFail.AddSample(float64(time.Since(sendEnd).Microseconds()))
+	logstore := m.recordSend(batch, sendEnd.Sub(sendBegin))
+	logstore.failedBatches.Add(1)
+	logstore.failedLogs.Add(int64(len(batch.logGroup.Logs)))
 }
 
-func (m *ProducerMonitor) recordRetry(sendCost time.Duration) {
+func (m *ProducerMonitor) recordRetry(batch *ProducerBatch, sendCost time.Duration) {
 	metrics := m.metrics.Load().(*ProducerMetrics)
 	metrics.sendBatch.AddSample(float64(sendCost.Microseconds()))
 	metrics.retryCount.Add(1)
+	m.recordSend(batch, sendCost).retries.Add(1)
+}
+
+// recordSend records the latency of a send of batch, and returns the metrics of the logstore of it.
+func (m *ProducerMonitor) recordSend(batch *ProducerBatch, sendCost time.Duration) *logstoreMetrics {
+	m.sendLatency.observe(sendCost)
+	key := logstoreKey{project: batch.project, logstore: batch.logstore}
+	logstore, ok := m.logstores.Load(key)
+	if !ok {
+		logstore, _ = m.logstores.LoadOrStore(key, &logstoreMetrics{})
+	}
+	logstore.(*logstoreMetrics).sendLatency.observe(sendCost)
+	return logstore.(*logstoreMetrics)
 }
 
 func (m *ProducerMonitor) recordWaitMemory(start time.Time) {
@@ -57,6 +82,7 @@ func (m *ProducerMonitor) recordWaitMemory(start time.Time) {
 func (m *ProducerMonitor) incWaitMemoryFail() {
 	metrics := m.metrics.Load().(*ProducerMetrics)
 	metrics.waitMemoryFailCount.Add(1)
+	m.waitMemoryFailCount.Add(1)
 }
 
 func (m *ProducerMonitor) incCreateBatch() {
@@ -1,6 +1,7 @@
 package producer
 
 import (
+	"context"
 	"errors"
 	"sync"
 	"sync/atomic"
@@ -9,6 +10,7 @@ import (
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
 	"github.com/go-kit/kit/log/level"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -28,6 +30,11 @@ type Producer struct {
 	logger                log.Logger
 	producerLogGroupSize  int64
 	monitor               *ProducerMonitor
+	spool                 *spool
+	spooledBatches        []*spooledBatch // recovered from the spool, sent once started
+	memoryLock            sync.Mutex
+	memoryReleased        chan struct{} // closed and replaced once memory is released
+	pendingBatches        *pendingBatches
 }
 
 func NewProducer(producerConfig *ProducerConfig) (*Producer, error) {
@@ -38,7 +45,11 @@ func NewProducer(producerConfig *ProducerConfig) (*Producer, error) {
 	if err != nil {
 		return nil, err
 	}
-	return createProducerInternal(client, finalProducerConfig, logger), nil
+	producer := createProducerInternal(client, finalProducerConfig, logger)
+	if err := producer.openSpool(); err != nil {
+		return nil, err
+	}
+	return producer, nil
 }
 
 // Deprecated: use NewProducer instead.
@@ -47,7 +58,11 @@ func InitProducer(producerConfig *ProducerConfig) *Producer {
 	finalProducerConfig := validateProducerConfig(producerConfig, logger)
 
 	client, _ := createClient(finalProducerConfig, true, logger)
-	return createProducerInternal(client, finalProducerConfig, logger)
+	producer := createProducerInternal(client, finalProducerConfig, logger)
+	if err := producer.openSpool(); err != nil {
+		level.Error(logger).Log("msg", "Failed to open spool, batches are not persisted.", "error", err)
+	}
+	return producer
 }
 
 func createProducerInternal(client sls.ClientInterface, finalProducerConfig *ProducerConfig, logger log.Logger) *Producer {
@@ -63,6 +78,8 @@ func createProducerInternal(client sls.ClientInterface, finalProducerConfig *Pro
 	producer := &Producer{
 		producerConfig: finalProducerConfig,
 		buckets:        finalProducerConfig.Buckets,
+		memoryReleased: make(chan struct{}),
+		pendingBatches: newPendingBatches(),
 	}
 	ioWorker := initIoWorker(client, retryQueue, logger, finalProducerConfig.MaxIoWorkerCount, errorStatusMap, producer)
 	threadPool := initIoThreadPool(ioWorker, logger)
@@ -93,6 +110,23 @@ func configureClient(client sls.ClientInterface, producerConfig *ProducerConfig)
 	if producerConfig.UserAgent != "" {
 		client.SetUserAgent(producerConfig.UserAgent)
 	}
+	if producerConfig.RateLimiter != nil {
+		if c, ok := client.(interface{ SetRateLimiter(*sls.RateLimiter) }); ok {
+			c.SetRateLimiter(producerConfig.RateLimiter)
+		}
+	}
+	if producerConfig.Signer != nil {
+		if c, ok := client.(interface {
+			SetSigner(func(creds sls.Credentials) sls.Signer)
+		}); ok {
+			c.SetSigner(producerConfig.Signer)
+		}
+	}
+	if producerConfig.TracerProvider != nil {
+		if c, ok := client.(interface{ SetTracerProvider(trace.TracerProvider) }); ok {
+			c.SetTracerProvider(producerConfig.TracerProvider)
+		}
+	}
 }
 
 func createClient(producerConfig *ProducerConfig, allowStsFallback bool, logger log.Logger) (sls.ClientInterface, error) {
@@ -143,6 +177,20 @@ func validateProducerConfig(producerConfig *ProducerConfig, logger log.Logger) *
 		level.Warn(logger).Log("msg", "The LingerMs parameter cannot be less than 100 milliseconds and has been reset to the default value of 2000 milliseconds")
 		producerConfig.LingerMs = 2000
 	}
+	if producerConfig.SpoolDir != "" {
+		if producerConfig.SpoolMaxBytes <= 0 {
+			producerConfig.SpoolMaxBytes = 1024 * 1024 * 1024
+		}
+		if producerConfig.SpoolSegmentBytes <= 0 {
+			producerConfig.SpoolSegmentBytes = 64 * 1024 * 1024
+		}
+		if producerConfig.SpoolSyncPolicy == "" {
+			producerConfig.SpoolSyncPolicy = SpoolSyncInterval
+		}
+		if producerConfig.SpoolSyncIntervalMs <= 0 {
+			producerConfig.SpoolSyncIntervalMs = 1000
+		}
+	}
 	return producerConfig
 }
 
@@ -157,7 +205,7 @@ func (producer *Producer) HashSendLogWithCallBack(project, logstore, shardHash,
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, log, callback)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, shardHash, topic, source, log, callback)
 }
 
 func (producer *Producer) HashSendLogListWithCallBack(project, logstore, shardHash, topic, source string, logList []*sls.Log, callback CallBack) (err error) {
@@ -172,7 +220,7 @@ func (producer *Producer) HashSendLogListWithCallBack(project, logstore, shardHa
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, logList, callback)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, shardHash, topic, source, logList, callback)
 }
 
 func (producer *Producer) SendLog(project, logstore, topic, source string, log *sls.Log) error {
@@ -180,7 +228,7 @@ func (producer *Producer) SendLog(project, logstore, topic, source string, log *
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, log, nil)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, "", topic, source, log, nil)
 }
 
 func (producer *Producer) SendLogList(project, logstore, topic, source string, logList []*sls.Log) (err error) {
@@ -189,7 +237,7 @@ func (producer *Producer) SendLogList(project, logstore, topic, source string, l
 		return err
 	}
 
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, logList, nil)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, "", topic, source, logList, nil)
 
 }
 
@@ -204,7 +252,7 @@ func (producer *Producer) HashSendLog(project, logstore, shardHash, topic, sourc
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, log, nil)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, shardHash, topic, source, log, nil)
 }
 
 func (producer *Producer) HashSendLogList(project, logstore, shardHash, topic, source string, logList []*sls.Log) (err error) {
@@ -218,7 +266,7 @@ func (producer *Producer) HashSendLogList(project, logstore, shardHash, topic, s
 			return err
 		}
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, shardHash, topic, source, logList, nil)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, shardHash, topic, source, logList, nil)
 
 }
 
@@ -227,7 +275,7 @@ func (producer *Producer) SendLogWithCallBack(project, logstore, topic, source s
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, log, callback)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, "", topic, source, log, callback)
 }
 
 func (producer *Producer) SendLogListWithCallBack(project, logstore, topic, source string, logList []*sls.Log, callback CallBack) (err error) {
@@ -235,11 +283,105 @@ func (producer *Producer) SendLogListWithCallBack(project, logstore, topic, sour
 	if err != nil {
 		return err
 	}
-	return producer.logAccumulator.addLogToProducerBatch(project, logstore, "", topic, source, logList, callback)
+	return producer.logAccumulator.addLogToProducerBatch(context.Background(), project, logstore, "", topic, source, logList, callback)
+
+}
+
+// SendLogCtx is SendLog waiting for memory until ctx is done instead of MaxBlockSec, and returns ctx.Err()
+// if ctx is done before the log is added. ctx does not apply to the send of the batch, except that the span
+// of the send is a child of the span of ctx of the first log of the batch, and linked to the others.
+func (producer *Producer) SendLogCtx(ctx context.Context, project, logstore, topic, source string, log *sls.Log) error {
+	return producer.sendCtx(ctx, project, logstore, "", topic, source, log, nil)
+}
+
+// SendLogListCtx is SendLogList waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) SendLogListCtx(ctx context.Context, project, logstore, topic, source string, logList []*sls.Log) error {
+	return producer.sendCtx(ctx, project, logstore, "", topic, source, logList, nil)
+}
+
+// HashSendLogCtx is HashSendLog waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) HashSendLogCtx(ctx context.Context, project, logstore, shardHash, topic, source string, log *sls.Log) error {
+	return producer.sendCtx(ctx, project, logstore, shardHash, topic, source, log, nil)
+}
+
+// HashSendLogListCtx is HashSendLogList waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) HashSendLogListCtx(ctx context.Context, project, logstore, shardHash, topic, source string, logList []*sls.Log) error {
+	return producer.sendCtx(ctx, project, logstore, shardHash, topic, source, logList, nil)
+}
+
+// SendLogWithCallBackCtx is SendLogWithCallBack waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) SendLogWithCallBackCtx(ctx context.Context, project, logstore, topic, source string, log *sls.Log, callback CallBack) error {
+	return producer.sendCtx(ctx, project, logstore, "", topic, source, log, callback)
+}
+
+// SendLogListWithCallBackCtx is SendLogListWithCallBack waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) SendLogListWithCallBackCtx(ctx context.Context, project, logstore, topic, source string, logList []*sls.Log, callback CallBack) error {
+	return producer.sendCtx(ctx, project, logstore, "", topic, source, logList, callback)
+}
+
+// HashSendLogWithCallBackCtx is HashSendLogWithCallBack waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) HashSendLogWithCallBackCtx(ctx context.Context, project, logstore, shardHash, topic, source string, log *sls.Log, callback CallBack) error {
+	return producer.sendCtx(ctx, project, logstore, shardHash, topic, source, log, callback)
+}
+
+// HashSendLogListWithCallBackCtx is HashSendLogListWithCallBack waiting for memory until ctx is done, see SendLogCtx.
+func (producer *Producer) HashSendLogListWithCallBackCtx(ctx context.Context, project, logstore, shardHash, topic, source string, logList []*sls.Log, callback CallBack) error {
+	return producer.sendCtx(ctx, project, logstore, shardHash, topic, source, logList, callback)
+}
+
+// SendAsync sends logs like SendLogListCtx, and returns a channel receiving the result once the batch of logs
+// is sent successfully or failed. If the logs are not added, eg. ctx is done or the producer is closed,
+// the result is failed with the error code TimeoutExecption or IllegalStateException at once.
+//
+//	result := <-producer.SendAsync(ctx, project, logstore, topic, source, log)
+//	if !result.IsSuccessful() {
+//		fmt.Println(result.GetErrorCode(), result.GetErrorMessage())
+//	}
+func (producer *Producer) SendAsync(ctx context.Context, project, logstore, topic, source string, logs ...*sls.Log) <-chan *Result {
+	return producer.HashSendAsync(ctx, project, logstore, "", topic, source, logs...)
+}
+
+// HashSendAsync is SendAsync with shardHash, see HashSendLogList.
+func (producer *Producer) HashSendAsync(ctx context.Context, project, logstore, shardHash, topic, source string, logs ...*sls.Log) <-chan *Result {
+	ch := make(resultChan, 1)
+	if err := producer.sendCtx(ctx, project, logstore, shardHash, topic, source, logs, ch); err != nil {
+		errorCode := IllegalStateException
+		if ctx.Err() != nil || err.Error() == TimeoutExecption {
+			errorCode = TimeoutExecption
+		}
+		result := initResult()
+		result.attemptList = append(result.attemptList, createAttempt(false, "", errorCode, err.Error(), time.Now().UnixMilli(), 0))
+		ch <- result
+	}
+	return ch
+}
+
+// resultChan is the CallBack of SendAsync.
+type resultChan chan *Result
 
+func (ch resultChan) Success(result *Result) {
+	ch <- result
 }
 
-// todo: refactor this
+func (ch resultChan) Fail(result *Result) {
+	ch <- result
+}
+
+func (producer *Producer) sendCtx(ctx context.Context, project, logstore, shardHash, topic, source string, logData interface{}, callback CallBack) error {
+	err := producer.waitTimeCtx(ctx)
+	if err != nil {
+		return err
+	}
+	if shardHash != "" && producer.producerConfig.AdjustShargHash {
+		shardHash, err = AdjustHash(shardHash, producer.buckets)
+		if err != nil {
+			return err
+		}
+	}
+	return producer.logAccumulator.addLogToProducerBatch(ctx, project, logstore, shardHash, topic, source, logData, callback)
+}
+
+// waitTime waits until the memory of the producer is available, at most MaxBlockSec.
 func (producer *Producer) waitTime() error {
 	if atomic.LoadInt64(&producer.producerLogGroupSize) <= producer.producerConfig.TotalSizeLnBytes {
 		return nil
@@ -247,39 +389,72 @@ func (producer *Producer) waitTime() error {
 
 	// no wait
 	if producer.producerConfig.MaxBlockSec == 0 {
-		if atomic.LoadInt64(&producer.producerLogGroupSize) > producer.producerConfig.TotalSizeLnBytes {
-			level.Error(producer.logger).Log("msg", "Over producer set maximum blocking time")
-			return errors.New(TimeoutExecption)
-		}
-		return nil
+		level.Error(producer.logger).Log("msg", "Over producer set maximum blocking time")
+		return errors.New(TimeoutExecption)
 	}
 
 	defer producer.monitor.recordWaitMemory(time.Now())
 
-	// infinite wait
-	if producer.producerConfig.MaxBlockSec < 0 {
-		for atomic.LoadInt64(&producer.producerLogGroupSize) > producer.producerConfig.TotalSizeLnBytes {
-			time.Sleep(waitTimeUnit)
-		}
+	// infinite wait if MaxBlockSec < 0
+	ctx := context.Background()
+	if producer.producerConfig.MaxBlockSec > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(producer.producerConfig.MaxBlockSec)*time.Second)
+		defer cancel()
+	}
+	if err := producer.waitMemory(ctx); err != nil {
+		producer.monitor.incWaitMemoryFail()
+		level.Error(producer.logger).Log("msg", "Over producer set maximum blocking time")
+		return errors.New(TimeoutExecption)
+	}
+	return nil
+}
+
+// waitTimeCtx is waitTime until ctx is done instead of MaxBlockSec.
+func (producer *Producer) waitTimeCtx(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if atomic.LoadInt64(&producer.producerLogGroupSize) <= producer.producerConfig.TotalSizeLnBytes {
 		return nil
 	}
+	defer producer.monitor.recordWaitMemory(time.Now())
+	if err := producer.waitMemory(ctx); err != nil {
+		producer.monitor.incWaitMemoryFail()
+		return err
+	}
+	return nil
+}
 
-	// todo: refine this, limited wait
-	for i := 0; i < producer.producerConfig.MaxBlockSec*waitUnitPerSec; i++ {
-		if atomic.LoadInt64(&producer.producerLogGroupSize) > producer.producerConfig.TotalSizeLnBytes {
-			time.Sleep(waitTimeUnit)
-		} else {
+// waitMemory blocks until the memory of the producer is within TotalSizeLnBytes, or ctx is done.
+func (producer *Producer) waitMemory(ctx context.Context) error {
+	for {
+		released := producer.memoryReleasedCh()
+		if atomic.LoadInt64(&producer.producerLogGroupSize) <= producer.producerConfig.TotalSizeLnBytes {
 			return nil
 		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-released:
+		}
 	}
+}
 
-	producer.monitor.incWaitMemoryFail()
-	level.Error(producer.logger).Log("msg", "Over producer set maximum blocking time")
-	return errors.New(TimeoutExecption)
+func (producer *Producer) memoryReleasedCh() <-chan struct{} {
+	producer.memoryLock.Lock()
+	defer producer.memoryLock.Unlock()
+	return producer.memoryReleased
 }
 
-const waitTimeUnit = time.Millisecond * 10
-const waitUnitPerSec = int(time.Second / waitTimeUnit)
+// releaseMemory subtracts size of the batch sent from the memory of the producer, and wakes up the waiters.
+func (producer *Producer) releaseMemory(size int64) {
+	atomic.AddInt64(&producer.producerLogGroupSize, -size)
+	producer.memoryLock.Lock()
+	close(producer.memoryReleased)
+	producer.memoryReleased = make(chan struct{})
+	producer.memoryLock.Unlock()
+}
 
 func (producer *Producer) Start() {
 	producer.moverWaitGroup.Add(1)
@@ -287,6 +462,7 @@ func (producer *Producer) Start() {
 	go producer.mover.run(producer.moverWaitGroup, producer.producerConfig)
 	producer.ioThreadPoolWaitGroup.Add(1)
 	go producer.threadPool.start(producer.ioWorkerWaitGroup, producer.ioThreadPoolWaitGroup)
+	producer.sendSpooledBatches()
 	if !producer.producerConfig.DisableRuntimeMetrics {
 		go producer.monitor.reportThread(time.Minute, producer.logger)
 	}
@@ -294,21 +470,47 @@ func (producer *Producer) Start() {
 
 // Limited closing transfer parameter nil, safe closing transfer timeout time, timeout Ms parameter in milliseconds
 func (producer *Producer) Close(timeoutMs int64) error {
-	startCloseTime := time.Now()
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
 	producer.sendCloseProdcerSignal()
 	producer.moverWaitGroup.Wait()
 	producer.threadPool.ShutDown()
 	for !producer.threadPool.Stopped() {
-		if time.Since(startCloseTime) > time.Duration(timeoutMs)*time.Millisecond {
-			level.Warn(producer.logger).Log("msg", "The producer timeout closes, and some of the cached data may not be sent properly")
-			return errors.New(TimeoutExecption)
+		if time.Now().After(deadline) {
+			return producer.closeTimeout()
 		}
 		time.Sleep(100 * time.Millisecond)
 	}
+	// the batches in flight are acked in the spool once sent
+	if !waitGroupTimeout(producer.ioWorkerWaitGroup, time.Until(deadline)) {
+		return producer.closeTimeout()
+	}
+	producer.closeSpool()
 	level.Info(producer.logger).Log("msg", "All groutines of producer have been shutdown")
 	return nil
 }
 
+func (producer *Producer) closeTimeout() error {
+	level.Warn(producer.logger).Log("msg", "The producer timeout closes, and some of the cached data may not be sent properly")
+	// the batches not sent are kept in the spool, and sent again by the next producer
+	producer.closeSpool()
+	return errors.New(TimeoutExecption)
+}
+
+// waitGroupTimeout waits for wg at most timeout, and returns whether wg is done.
+func waitGroupTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
 func (producer *Producer) SafeClose() {
 	producer.sendCloseProdcerSignal()
 	producer.moverWaitGroup.Wait()
@@ -317,6 +519,7 @@ func (producer *Producer) SafeClose() {
 	producer.ioThreadPoolWaitGroup.Wait()
 	level.Info(producer.logger).Log("msg", "IoThreadPool close finish")
 	producer.ioWorkerWaitGroup.Wait()
+	producer.closeSpool()
 	level.Info(producer.logger).Log("msg", "Producer close finish")
 }
 
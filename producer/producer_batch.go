@@ -6,10 +6,14 @@ import (
 
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/gogo/protobuf/proto"
+	"go.opentelemetry.io/otel/trace"
 )
 
 var PACK_ID_KEY = "__pack_id__"
 
+// maxSpanLinks is the max number of spans of the senders linked to the span of the send of a batch.
+const maxSpanLinks = 128
+
 type ProducerBatch struct {
 	// read only fields
 	maxRetryIntervalInMs int64
@@ -26,6 +30,10 @@ type ProducerBatch struct {
 	totalDataSize int64
 	logGroup      *sls.LogGroup
 	callBackList  []CallBack
+	spoolID       uint64            // id of the batch in the spool, 0 if not persisted
+	seq           uint64            // seq of the batch in the pending batches of the producer
+	spanContext   trace.SpanContext // span of the first sender, the parent of the span of the send
+	spanLinks     []trace.Link      // spans of the other senders
 
 	// transient fields, but rw by at most one thread
 	attemptCount int
@@ -69,6 +77,25 @@ func newProducerBatch(packIdGenerator *PackIdGenerator, project, logstore, logTo
 	return producerBatch
 }
 
+// newSealedProducerBatch returns a batch of logGroup to send as a whole, eg. recovered from the spool.
+func newSealedProducerBatch(project, logstore string, shardHash *string, useMetricStoreUrl bool, logGroup *sls.LogGroup, config *ProducerConfig) *ProducerBatch {
+	return &ProducerBatch{
+		logGroup:             logGroup,
+		maxRetryIntervalInMs: config.MaxRetryBackoffMs,
+		callBackList:         []CallBack{},
+		createTimeMs:         time.Now().UnixMilli(),
+		maxRetryTimes:        config.Retries,
+		baseRetryBackoffMs:   config.BaseRetryBackoffMs,
+		project:              project,
+		logstore:             logstore,
+		shardHash:            shardHash,
+		result:               initResult(),
+		maxReservedAttempts:  config.MaxReservedAttempts,
+		useMetricStoreUrl:    useMetricStoreUrl,
+		totalDataSize:        int64(GetLogListSize(logGroup.Logs)),
+	}
+}
+
 func (producerBatch *ProducerBatch) getProject() string {
 	return producerBatch.project
 }
@@ -105,6 +132,18 @@ func (producerBatch *ProducerBatch) addLogList(logList []*sls.Log, size int64, c
 	}
 }
 
+// addSpanContext records the span of a sender of the batch, which is ignored if invalid.
+func (producerBatch *ProducerBatch) addSpanContext(spanContext trace.SpanContext) {
+	if !spanContext.IsValid() {
+		return
+	}
+	if !producerBatch.spanContext.IsValid() {
+		producerBatch.spanContext = spanContext
+	} else if len(producerBatch.spanLinks) < maxSpanLinks && !spanContext.Equal(producerBatch.spanContext) {
+		producerBatch.spanLinks = append(producerBatch.spanLinks, trace.Link{SpanContext: spanContext})
+	}
+}
+
 func (producerBatch *ProducerBatch) OnSuccess(begin time.Time) {
 	producerBatch.addAttempt(nil, begin)
 	if len(producerBatch.callBackList) > 0 {
@@ -6,6 +6,7 @@ import (
 
 	sls "github.com/aliyun/aliyun-log-go-sdk"
 	"github.com/go-kit/kit/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const Delimiter = "|"
@@ -61,6 +62,41 @@ type ProducerConfig struct {
 	UseMetricStoreURL     bool
 	DisableRuntimeMetrics bool // disable runtime metrics, runtime metrics prints to local log.
 
+	// Optional, defaults to nil, which disables tracing.
+	// TracerProvider is used to trace each send of a batch, and the api calls of the client.
+	TracerProvider trace.TracerProvider
+	// Optional, defaults to nil, which means no limit.
+	// RateLimiter limits the requests sent by the io workers, and slows them down
+	// once the write quota of the project or shard is exceeded. It is ignored by clients without SetRateLimiter.
+	RateLimiter *sls.RateLimiter
+	// Optional, defaults to nil, which means the signer of AuthVersion.
+	// Signer creates the signer of each request from the credentials, see sls.Client.SetSigner.
+	// It is ignored by clients without SetSigner.
+	Signer func(creds sls.Credentials) sls.Signer
+	// Optional, defaults to "", which disables the spool.
+	// SpoolDir is the dir of the write-ahead spool, each batch is persisted to it once sealed, and deleted
+	// once sent successfully or failed. The batches left by the last producer in the dir, eg. crashed or
+	// closed with timeout, are sent again by the next producer once started, so a batch may be sent twice.
+	// The logs not sealed into a batch yet, at most LingerMs of logs of each batch, are not persisted,
+	// and lost on a crash. The dir must not be shared by producers.
+	SpoolDir string
+	// Optional, defaults to 1G, in bytes.
+	// SpoolMaxBytes is the max size of the spool, batches are sent without persisted once exceeded.
+	SpoolMaxBytes int64
+	// Optional, defaults to 64M, in bytes.
+	// SpoolSegmentBytes is the size of a segment file of the spool before it gets rotated.
+	SpoolSegmentBytes int64
+	// Optional, defaults to SpoolSyncInterval.
+	// SpoolSyncPolicy decides when the spool is fsynced, can be SpoolSyncAlways/SpoolSyncInterval/SpoolSyncNone.
+	SpoolSyncPolicy SpoolSyncPolicy
+	// Optional, defaults to 1000.
+	// SpoolSyncIntervalMs is the interval to fsync the spool with SpoolSyncInterval.
+	SpoolSyncIntervalMs int64
+	// Optional, defaults to nil, which drops the batches failed after retries once CallBack.Fail is called.
+	// DeadLetterSink receives each batch failed after retries with its attempts, eg. NewFileDeadLetterSink
+	// and NewLogstoreDeadLetterSink, the batches can be sent again by Producer.ReplayDeadLetter.
+	DeadLetterSink DeadLetterSink
+
 	// Deprecated: use CredentialsProvider and UpdateFuncProviderAdapter instead.
 	//
 	// Example:
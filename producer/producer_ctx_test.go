@@ -0,0 +1,123 @@
+package producer
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newFakeClientProducer(t *testing.T, config *ProducerConfig) (*Producer, *sls.FakeClient) {
+	client := sls.NewFakeClient()
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 0))
+	config.LingerMs = 100
+	return createProducerInternal(client, validateProducerConfig(config, log.NewNopLogger()), log.NewNopLogger()), client
+}
+
+func TestSendLogCtx(t *testing.T) {
+	config := GetDefaultProducerConfig()
+	config.TotalSizeLnBytes = 1
+	producer, _ := newFakeClientProducer(t, config)
+	newLog := func() *sls.Log {
+		return GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "test"})
+	}
+	// the producer is not started, so the memory is not released
+	require.NoError(t, producer.SendLogCtx(context.Background(), "test-project", "test-logstore", "", "", newLog()))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, producer.SendLogCtx(ctx, "test-project", "test-logstore", "", "", newLog()), context.DeadlineExceeded)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	result := <-producer.SendAsync(ctx, "test-project", "test-logstore", "", "", newLog())
+	require.False(t, result.IsSuccessful())
+	require.Equal(t, TimeoutExecption, result.GetErrorCode())
+	require.Equal(t, context.Canceled.Error(), result.GetErrorMessage())
+
+	// the waiter wakes up once the memory is released
+	done := make(chan error)
+	go func() {
+		done <- producer.SendLogCtx(context.Background(), "test-project", "test-logstore", "", "", newLog())
+	}()
+	time.Sleep(50 * time.Millisecond)
+	producer.releaseMemory(atomic.LoadInt64(&producer.producerLogGroupSize))
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("SendLogCtx is not woken up")
+	}
+}
+
+func TestSendAsync(t *testing.T) {
+	producer, client := newFakeClientProducer(t, GetDefaultProducerConfig())
+	producer.Start()
+	log := GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "test"})
+	results := []<-chan *Result{
+		producer.SendAsync(context.Background(), "test-project", "test-logstore", "topic", "source", log),
+		producer.SendAsync(context.Background(), "test-project", "test-logstore", "topic", "source", log, log),
+		producer.HashSendAsync(context.Background(), "test-project", "test-logstore", "0", "topic", "source", log),
+		producer.SendAsync(context.Background(), "test-project", "missing-logstore", "topic", "source", log),
+	}
+	for i, ch := range results {
+		select {
+		case result := <-ch:
+			if i == len(results)-1 {
+				require.False(t, result.IsSuccessful())
+				require.Equal(t, sls.LOGSTORE_NOT_EXIST, result.GetErrorCode())
+			} else {
+				require.True(t, result.IsSuccessful())
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("no result")
+		}
+	}
+	producer.SafeClose()
+	begin, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	logGroups, _, err := client.PullLogs("test-project", "test-logstore", 0, begin, "", 100)
+	require.NoError(t, err)
+	logs := 0
+	for _, logGroup := range logGroups.LogGroups {
+		logs += len(logGroup.Logs)
+	}
+	require.Equal(t, 4, logs)
+}
+
+func TestSendLogCtxTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	config := GetDefaultProducerConfig()
+	config.TracerProvider = tp
+	producer, _ := newFakeClientProducer(t, config)
+	producer.Start()
+	defer producer.SafeClose()
+
+	ctx1, span1 := tp.Tracer("test").Start(context.Background(), "sender1")
+	ctx2, span2 := tp.Tracer("test").Start(context.Background(), "sender2")
+	require.NoError(t, producer.SendLogCtx(ctx1, "test-project", "test-logstore", "", "", GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "1"})))
+	result := <-producer.SendAsync(ctx2, "test-project", "test-logstore", "", "", GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "2"}))
+	require.True(t, result.IsSuccessful())
+	span1.End()
+	span2.End()
+
+	var send sdktrace.ReadOnlySpan
+	for _, span := range recorder.Ended() {
+		if span.Name() == "sls.producer.sendToServer" {
+			send = span
+		}
+	}
+	require.NotNil(t, send)
+	require.Equal(t, span1.SpanContext().TraceID(), send.SpanContext().TraceID())
+	require.Equal(t, span1.SpanContext().SpanID(), send.Parent().SpanID())
+	require.Len(t, send.Links(), 1)
+	require.Equal(t, span2.SpanContext().SpanID(), send.Links()[0].SpanContext.SpanID())
+}
@@ -26,12 +26,19 @@ func createAttempt(success bool,
 type Result struct {
 	attemptList []*Attempt
 	successful  bool
+	spooled     bool
 }
 
 func (result *Result) IsSuccessful() bool {
 	return result.successful
 }
 
+// IsSpooled returns whether the failed batch is kept in the spool, because it failed only for the producer
+// is closing. Such a batch is not written to the DeadLetterSink, and sent again by the next producer.
+func (result *Result) IsSpooled() bool {
+	return result.spooled
+}
+
 func (result *Result) GetReservedAttempts() []*Attempt {
 	return result.attemptList
 }
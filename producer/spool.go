@@ -0,0 +1,498 @@
+package producer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log"
+	"github.com/go-kit/kit/log/level"
+)
+
+// SpoolSyncPolicy decides when the spool fsyncs the batches written to disk.
+type SpoolSyncPolicy string
+
+const (
+	// SpoolSyncAlways fsyncs every batch before it is sent, which is the most durable and the slowest.
+	SpoolSyncAlways SpoolSyncPolicy = "always"
+	// SpoolSyncInterval fsyncs every SpoolSyncIntervalMs, batches written since the last fsync may be lost
+	// if the machine crashes, but not if only the process crashes.
+	SpoolSyncInterval SpoolSyncPolicy = "interval"
+	// SpoolSyncNone never fsyncs, and leaves it to the os.
+	SpoolSyncNone SpoolSyncPolicy = "none"
+)
+
+const (
+	spoolFileSuffix     = ".spool"
+	spoolRecordHeadSize = 4 + 4 // length and crc of the record body
+	spoolRecordPut      = byte(1)
+	spoolRecordAck      = byte(2)
+)
+
+var errSpoolFull = errors.New("spool is full")
+
+var spoolCrcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// spool is a write-ahead log of the batches of the producer, batches are appended to the active segment
+// before sent, and acked once sent successfully or failed. The leading segments whose batches are all acked
+// are deleted, so an ack is never deleted before the batch it acks.
+//
+// A record is the length and crc32 of the body, followed by the body, which is the record type,
+// the batch id, and the batch for put records.
+type spool struct {
+	dir          string
+	maxBytes     int64
+	segmentBytes int64
+	syncPolicy   SpoolSyncPolicy
+	logger       log.Logger
+
+	mu         sync.Mutex
+	segments   []*spoolSegment // ordered by seq, the last one is active
+	batchSeg   map[uint64]*spoolSegment
+	nextID     uint64
+	totalBytes int64
+	dirty      bool // the active segment is written since the last fsync
+	closed     bool
+	stopSync   chan struct{}
+}
+
+type spoolSegment struct {
+	seq     uint64
+	path    string
+	size    int64
+	pending int // batches put to the segment but not acked
+	file    *os.File
+}
+
+// spooledBatch is a batch recovered from the spool.
+type spooledBatch struct {
+	id                uint64
+	project           string
+	logstore          string
+	shardHash         *string
+	useMetricStoreUrl bool
+	logGroup          *sls.LogGroup
+}
+
+// openSpool opens the spool in config.SpoolDir, and returns the batches not acked by the last producer.
+func openSpool(config *ProducerConfig, logger log.Logger) (*spool, []*spooledBatch, error) {
+	if err := os.MkdirAll(config.SpoolDir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("fail to create spool dir: %w", err)
+	}
+	s := &spool{
+		dir:          config.SpoolDir,
+		maxBytes:     config.SpoolMaxBytes,
+		segmentBytes: config.SpoolSegmentBytes,
+		syncPolicy:   config.SpoolSyncPolicy,
+		logger:       logger,
+		batchSeg:     make(map[uint64]*spoolSegment),
+		nextID:       1,
+	}
+	recovered, err := s.recover()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := s.rotate(); err != nil {
+		return nil, nil, err
+	}
+	if s.syncPolicy == SpoolSyncInterval {
+		s.stopSync = make(chan struct{})
+		go s.syncThread(time.Duration(config.SpoolSyncIntervalMs) * time.Millisecond)
+	}
+	return s, recovered, nil
+}
+
+// recover reads all segments in the spool dir, and deletes the leading ones without pending batches.
+func (s *spool) recover() ([]*spooledBatch, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*"+spoolFileSuffix))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(filepath.Base(path), spoolFileSuffix), 10, 64)
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "ignore unknown file in spool dir", "file", path)
+			continue
+		}
+		stat, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		s.segments = append(s.segments, &spoolSegment{seq: seq, path: path, size: stat.Size()})
+		s.totalBytes += stat.Size()
+	}
+	sort.Slice(s.segments, func(i, j int) bool { return s.segments[i].seq < s.segments[j].seq })
+
+	pending := make(map[uint64]*spooledBatch)
+	for _, segment := range s.segments {
+		err := readSpoolSegment(segment.path, func(recordType byte, id uint64, body []byte) error {
+			if id >= s.nextID {
+				s.nextID = id + 1
+			}
+			if recordType == spoolRecordAck {
+				if seg, ok := s.batchSeg[id]; ok {
+					seg.pending--
+					delete(s.batchSeg, id)
+					delete(pending, id)
+				}
+				return nil
+			}
+			batch, err := decodeSpooledBatch(body)
+			if err != nil {
+				return err
+			}
+			batch.id = id
+			pending[id] = batch
+			s.batchSeg[id] = segment
+			segment.pending++
+			return nil
+		})
+		if err != nil {
+			level.Warn(s.logger).Log("msg", "stop reading corrupted spool segment", "file", segment.path, "error", err)
+		}
+	}
+	s.deleteAckedSegments()
+
+	recovered := make([]*spooledBatch, 0, len(pending))
+	for _, batch := range pending {
+		recovered = append(recovered, batch)
+	}
+	sort.Slice(recovered, func(i, j int) bool { return recovered[i].id < recovered[j].id })
+	if len(recovered) > 0 {
+		level.Info(s.logger).Log("msg", "recover batches from spool", "batches", len(recovered))
+	}
+	return recovered, nil
+}
+
+// readSpoolSegment calls fn with each record of the segment at path, until the end of the segment,
+// or a torn or corrupted record, which is returned as an error.
+func readSpoolSegment(path string, fn func(recordType byte, id uint64, body []byte) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	reader := bufio.NewReader(f)
+	head := make([]byte, spoolRecordHeadSize)
+	for {
+		if _, err := io.ReadFull(reader, head); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		length := binary.LittleEndian.Uint32(head)
+		if length < 9 {
+			return fmt.Errorf("invalid record length %d", length)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			return err
+		}
+		if crc32.Checksum(body, spoolCrcTable) != binary.LittleEndian.Uint32(head[4:]) {
+			return errors.New("record crc mismatch")
+		}
+		if err := fn(body[0], binary.LittleEndian.Uint64(body[1:9]), body[9:]); err != nil {
+			return err
+		}
+	}
+}
+
+// put appends batch to the spool and returns its id, it returns errSpoolFull if the spool exceeds maxBytes.
+func (s *spool) put(batch *ProducerBatch) (uint64, error) {
+	data, err := encodeSpooledBatch(batch)
+	if err != nil {
+		return 0, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, errors.New("spool is closed")
+	}
+	if s.maxBytes > 0 && s.totalBytes+int64(len(data))+spoolRecordHeadSize+9 > s.maxBytes {
+		return 0, errSpoolFull
+	}
+	id := s.nextID
+	if err := s.writeLocked(spoolRecordPut, id, data); err != nil {
+		return 0, err
+	}
+	s.nextID++
+	active := s.segments[len(s.segments)-1]
+	active.pending++
+	s.batchSeg[id] = active
+	if s.syncPolicy == SpoolSyncAlways {
+		if err := active.file.Sync(); err != nil {
+			return id, err
+		}
+		s.dirty = false
+	}
+	return id, nil
+}
+
+// ack marks the batch of id as done, it is not sent again once the spool is reopened.
+func (s *spool) ack(id uint64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segment, ok := s.batchSeg[id]
+	if !ok {
+		return nil
+	}
+	if s.closed {
+		return errors.New("spool is closed")
+	}
+	// a lost ack only makes the batch sent again, so it is not synced
+	if err := s.writeLocked(spoolRecordAck, id, nil); err != nil {
+		return err
+	}
+	delete(s.batchSeg, id)
+	segment.pending--
+	s.deleteAckedSegments()
+	return nil
+}
+
+func (s *spool) writeLocked(recordType byte, id uint64, data []byte) error {
+	record := make([]byte, spoolRecordHeadSize+9+len(data))
+	body := record[spoolRecordHeadSize:]
+	body[0] = recordType
+	binary.LittleEndian.PutUint64(body[1:9], id)
+	copy(body[9:], data)
+	binary.LittleEndian.PutUint32(record, uint32(len(body)))
+	binary.LittleEndian.PutUint32(record[4:], crc32.Checksum(body, spoolCrcTable))
+
+	active := s.segments[len(s.segments)-1]
+	if active.size > 0 && active.size+int64(len(record)) > s.segmentBytes {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+		active = s.segments[len(s.segments)-1]
+	}
+	n, err := active.file.Write(record)
+	active.size += int64(n)
+	s.totalBytes += int64(n)
+	s.dirty = true
+	return err
+}
+
+// rotate closes the active segment, and creates a new one.
+func (s *spool) rotate() error {
+	seq := uint64(1)
+	if len(s.segments) > 0 {
+		last := s.segments[len(s.segments)-1]
+		seq = last.seq + 1
+		if last.file != nil {
+			if err := last.file.Sync(); err != nil {
+				return err
+			}
+			if err := last.file.Close(); err != nil {
+				return err
+			}
+			last.file = nil
+		}
+	}
+	path := filepath.Join(s.dir, fmt.Sprintf("%020d%s", seq, spoolFileSuffix))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("fail to create spool segment: %w", err)
+	}
+	s.segments = append(s.segments, &spoolSegment{seq: seq, path: path, file: f})
+	s.dirty = false
+	s.deleteAckedSegments()
+	return nil
+}
+
+// deleteAckedSegments deletes the leading segments without pending batches, except the active one.
+func (s *spool) deleteAckedSegments() {
+	for len(s.segments) > 1 && s.segments[0].pending <= 0 {
+		segment := s.segments[0]
+		if err := os.Remove(segment.path); err != nil && !os.IsNotExist(err) {
+			level.Warn(s.logger).Log("msg", "fail to delete spool segment", "file", segment.path, "error", err)
+			return
+		}
+		s.totalBytes -= segment.size
+		s.segments = s.segments[1:]
+	}
+}
+
+func (s *spool) syncThread(interval time.Duration) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopSync:
+			return
+		case <-ticker.C:
+			s.mu.Lock()
+			if s.dirty && !s.closed {
+				if err := s.segments[len(s.segments)-1].file.Sync(); err != nil {
+					level.Warn(s.logger).Log("msg", "fail to sync spool", "error", err)
+				}
+				s.dirty = false
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+// pending returns the number of batches put but not acked.
+func (s *spool) pending() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.batchSeg)
+}
+
+func (s *spool) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	if s.stopSync != nil {
+		close(s.stopSync)
+	}
+	active := s.segments[len(s.segments)-1]
+	if err := active.file.Sync(); err != nil {
+		active.file.Close()
+		return err
+	}
+	return active.file.Close()
+}
+
+func encodeSpooledBatch(batch *ProducerBatch) ([]byte, error) {
+	logGroup, err := batch.logGroup.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 0, len(batch.project)+len(batch.logstore)+len(logGroup)+32)
+	buf = appendSpoolString(buf, batch.project)
+	buf = appendSpoolString(buf, batch.logstore)
+	if batch.shardHash != nil {
+		buf = append(buf, 1)
+		buf = appendSpoolString(buf, *batch.shardHash)
+	} else {
+		buf = append(buf, 0)
+	}
+	if batch.useMetricStoreUrl {
+		buf = append(buf, 1)
+	} else {
+		buf = append(buf, 0)
+	}
+	return append(buf, logGroup...), nil
+}
+
+func appendSpoolString(buf []byte, s string) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(s)))
+	return append(buf, s...)
+}
+
+func decodeSpooledBatch(data []byte) (*spooledBatch, error) {
+	batch := &spooledBatch{}
+	var err error
+	if batch.project, data, err = readSpoolString(data); err != nil {
+		return nil, err
+	}
+	if batch.logstore, data, err = readSpoolString(data); err != nil {
+		return nil, err
+	}
+	if len(data) < 1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if data[0] == 1 {
+		var shardHash string
+		if shardHash, data, err = readSpoolString(data[1:]); err != nil {
+			return nil, err
+		}
+		batch.shardHash = &shardHash
+	} else {
+		data = data[1:]
+	}
+	if len(data) < 1 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	batch.useMetricStoreUrl = data[0] == 1
+	batch.logGroup = &sls.LogGroup{}
+	if err := batch.logGroup.Unmarshal(data[1:]); err != nil {
+		return nil, err
+	}
+	return batch, nil
+}
+
+func readSpoolString(data []byte) (string, []byte, error) {
+	length, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < length {
+		return "", nil, io.ErrUnexpectedEOF
+	}
+	return string(data[n : n+int(length)]), data[n+int(length):], nil
+}
+
+func (producer *Producer) openSpool() error {
+	if producer.producerConfig.SpoolDir == "" {
+		return nil
+	}
+	s, recovered, err := openSpool(producer.producerConfig, producer.logger)
+	if err != nil {
+		return err
+	}
+	producer.spool = s
+	producer.spooledBatches = recovered
+	return nil
+}
+
+// spoolBatch persists batch to the spool before it is sent, batches sent again on retry are persisted once.
+func (producer *Producer) spoolBatch(batch *ProducerBatch) {
+	if producer.spool == nil || batch == nil || batch.spoolID != 0 {
+		return
+	}
+	id, err := producer.spool.put(batch)
+	if err != nil {
+		level.Warn(producer.logger).Log("msg", "fail to persist batch to spool, send it without persisted", "error", err)
+		return
+	}
+	batch.spoolID = id
+}
+
+func (producer *Producer) ackSpooledBatch(batch *ProducerBatch) {
+	if producer.spool == nil || batch.spoolID == 0 {
+		return
+	}
+	if err := producer.spool.ack(batch.spoolID); err != nil {
+		level.Warn(producer.logger).Log("msg", "fail to ack batch in spool", "error", err)
+	}
+}
+
+// sendSpooledBatches sends the batches recovered from the spool.
+func (producer *Producer) sendSpooledBatches() {
+	for _, spooled := range producer.spooledBatches {
+		batch := newSealedProducerBatch(spooled.project, spooled.logstore, spooled.shardHash, spooled.useMetricStoreUrl,
+			spooled.logGroup, producer.producerConfig)
+		batch.spoolID = spooled.id
+		batch.seq = producer.pendingBatches.add()
+		atomic.AddInt64(&producer.producerLogGroupSize, batch.totalDataSize)
+		producer.threadPool.addTask(batch)
+	}
+	producer.spooledBatches = nil
+}
+
+func (producer *Producer) closeSpool() {
+	if producer.spool == nil {
+		return
+	}
+	if err := producer.spool.close(); err != nil {
+		level.Warn(producer.logger).Log("msg", "fail to close spool", "error", err)
+	}
+}
@@ -0,0 +1,250 @@
+package producer
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/go-kit/kit/log"
+	"github.com/stretchr/testify/require"
+)
+
+func newSpoolTestConfig(dir string) *ProducerConfig {
+	config := GetDefaultProducerConfig()
+	config.SpoolDir = dir
+	config.SpoolSegmentBytes = 1024
+	config.SpoolSyncPolicy = SpoolSyncAlways
+	return validateProducerConfig(config, log.NewNopLogger())
+}
+
+func newSpoolTestBatch(config *ProducerConfig, shardHash string, logs int) *ProducerBatch {
+	batch := newProducerBatch(nil, "test-project", "test-logstore", "topic", "source", shardHash, config)
+	for i := 0; i < logs; i++ {
+		batch.addLog(GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": strconv.Itoa(i)}), 0, nil)
+	}
+	return batch
+}
+
+func spoolSegmentCount(t *testing.T, dir string) int {
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+spoolFileSuffix))
+	require.NoError(t, err)
+	return len(paths)
+}
+
+func TestSpool(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Empty(t, recovered)
+
+	var ids []uint64
+	for i := 0; i < 20; i++ {
+		id, err := s.put(newSpoolTestBatch(config, strconv.Itoa(i), 5))
+		require.NoError(t, err)
+		ids = append(ids, id)
+	}
+	require.Greater(t, spoolSegmentCount(t, dir), 2) // rotated
+	for _, id := range ids[:15] {
+		require.NoError(t, s.ack(id))
+	}
+	require.Equal(t, 5, s.pending())
+	require.NoError(t, s.close())
+
+	// the torn tail of a crashed process is ignored
+	f, err := os.OpenFile(s.segments[len(s.segments)-1].path, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.Write([]byte{100, 0, 0, 0, 1, 2})
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	s, recovered, err = openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Len(t, recovered, 5)
+	for i, batch := range recovered {
+		require.Equal(t, ids[15+i], batch.id)
+		require.Equal(t, "test-project", batch.project)
+		require.Equal(t, "test-logstore", batch.logstore)
+		require.Equal(t, strconv.Itoa(15+i), *batch.shardHash)
+		require.Equal(t, "topic", batch.logGroup.GetTopic())
+		require.Len(t, batch.logGroup.Logs, 5)
+	}
+	id, err := s.put(newSpoolTestBatch(config, "", 1))
+	require.NoError(t, err)
+	require.Greater(t, id, ids[len(ids)-1])
+
+	// segments are deleted once all batches in them are acked
+	for _, batch := range recovered {
+		require.NoError(t, s.ack(batch.id))
+	}
+	require.NoError(t, s.ack(id))
+	require.Equal(t, 1, spoolSegmentCount(t, dir))
+	require.NoError(t, s.close())
+	s, recovered, err = openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Empty(t, recovered)
+	require.NoError(t, s.close())
+}
+
+func TestSpoolMaxBytes(t *testing.T) {
+	config := newSpoolTestConfig(t.TempDir())
+	config.SpoolMaxBytes = 2048
+	s, _, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	defer s.close()
+	var ids []uint64
+	for {
+		id, err := s.put(newSpoolTestBatch(config, "", 5))
+		if err != nil {
+			require.ErrorIs(t, err, errSpoolFull)
+			break
+		}
+		ids = append(ids, id)
+	}
+	require.NotEmpty(t, ids)
+	for _, id := range ids {
+		require.NoError(t, s.ack(id))
+	}
+	_, err = s.put(newSpoolTestBatch(config, "", 5))
+	require.NoError(t, err)
+}
+
+func TestProducerSpool(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	config.LingerMs = 100
+	client := sls.NewFakeClient()
+	_, err := client.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore("test-project", "test-logstore", 1, 1, false, 0))
+
+	// batches left by a crashed producer
+	s, _, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	for i := 0; i < 3; i++ {
+		_, err := s.put(newSpoolTestBatch(config, "", 2))
+		require.NoError(t, err)
+	}
+	require.NoError(t, s.close())
+
+	producer := createProducerInternal(client, config, log.NewNopLogger())
+	require.NoError(t, producer.openSpool())
+	producer.Start()
+	require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "source",
+		GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "new"})))
+	producer.SafeClose()
+
+	begin, err := client.GetCursor("test-project", "test-logstore", 0, "begin")
+	require.NoError(t, err)
+	logGroups, _, err := client.PullLogs("test-project", "test-logstore", 0, begin, "", 100)
+	require.NoError(t, err)
+	require.Len(t, logGroups.LogGroups, 4)
+
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	require.Empty(t, recovered)
+	require.NoError(t, s.close())
+}
+
+// blockingClient blocks the sends of batches until release is closed.
+type blockingClient struct {
+	sls.ClientInterface
+	sends   atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockingClient) PostLogStoreLogsV2(project, logstore string, req *sls.PostLogStoreLogsRequest) error {
+	c.sends.Add(1)
+	<-c.release
+	return c.ClientInterface.PostLogStoreLogsV2(project, logstore, req)
+}
+
+func TestProducerSpoolCloseTimeout(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	config.SpoolSyncPolicy = SpoolSyncNone
+	config.LingerMs = 100
+	client := &blockingClient{ClientInterface: sls.NewFakeClient(), release: make(chan struct{})}
+	defer close(client.release)
+
+	producer := createProducerInternal(client, config, log.NewNopLogger())
+	require.NoError(t, producer.openSpool())
+	producer.Start()
+	for _, topic := range []string{"a", "b", "c"} {
+		require.NoError(t, producer.SendLog("test-project", "test-logstore", topic, "source",
+			GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": topic})))
+	}
+	require.Eventually(t, func() bool { return client.sends.Load() == 3 }, 5*time.Second, 10*time.Millisecond)
+	require.EqualError(t, producer.Close(1), TimeoutExecption)
+
+	producer.spool.mu.Lock()
+	closed := producer.spool.closed
+	producer.spool.mu.Unlock()
+	require.True(t, closed)
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	defer s.close()
+	require.Len(t, recovered, 3)
+	var topics []string
+	for _, batch := range recovered {
+		topics = append(topics, batch.logGroup.GetTopic())
+	}
+	require.ElementsMatch(t, []string{"a", "b", "c"}, topics)
+}
+
+func TestProducerSpoolCloseWaitsInFlight(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	config.LingerMs = 100
+	fake := sls.NewFakeClient()
+	_, err := fake.CreateProject("test-project", "")
+	require.NoError(t, err)
+	require.NoError(t, fake.CreateLogStore("test-project", "test-logstore", 1, 1, false, 0))
+	client := &blockingClient{ClientInterface: fake, release: make(chan struct{})}
+
+	producer := createProducerInternal(client, config, log.NewNopLogger())
+	require.NoError(t, producer.openSpool())
+	producer.Start()
+	require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "source",
+		GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "in flight"})))
+	require.Eventually(t, func() bool { return client.sends.Load() == 1 }, 5*time.Second, 10*time.Millisecond)
+	time.AfterFunc(300*time.Millisecond, func() { close(client.release) })
+	require.NoError(t, producer.Close(5000))
+
+	// the batch in flight is acked before the spool is closed, so it is not sent again
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	defer s.close()
+	require.Empty(t, recovered)
+}
+
+func TestProducerSpoolOpenBatches(t *testing.T) {
+	dir := t.TempDir()
+	config := newSpoolTestConfig(dir)
+	config.MaxBatchCount = 10
+	config.LingerMs = 60000
+	producer := createProducerInternal(sls.NewFakeClient(), config, log.NewNopLogger())
+	require.NoError(t, producer.openSpool())
+	// the producer is not started, so the sealed batches stay in the spool
+	for i := 0; i < 25; i++ {
+		require.NoError(t, producer.SendLog("test-project", "test-logstore", "topic", "source",
+			GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": strconv.Itoa(i)})))
+	}
+	// crash
+	producer.closeSpool()
+
+	// only the sealed batches are recovered, the logs of the open batch are lost
+	s, recovered, err := openSpool(config, log.NewNopLogger())
+	require.NoError(t, err)
+	defer s.close()
+	logs := 0
+	for _, batch := range recovered {
+		logs += len(batch.logGroup.Logs)
+	}
+	require.Len(t, recovered, 2)
+	require.Equal(t, 20, logs)
+}
@@ -0,0 +1,215 @@
+package producer
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	uberatomic "go.uber.org/atomic"
+)
+
+// ProducerStats is a snapshot of the status of a producer, see Producer.Stats.
+type ProducerStats struct {
+	BufferedBytes      int64 // size of logs not sent successfully or failed yet, sends block once it exceeds TotalSizeLnBytes
+	MaxBufferedBytes   int64 // TotalSizeLnBytes
+	AccumulatorBatches int   // batches waiting for LingerMs, MaxBatchSize or MaxBatchCount in the accumulator
+	RetryQueueBatches  int   // batches waiting for the backoff to retry
+	InFlightIoWorkers  int64 // batches being sent by io workers, or waiting for a free io worker
+
+	// counted since the producer is created
+	SuccessBatches      int64
+	FailedBatches       int64
+	RetryCount          int64 // sends failed and retried
+	WaitMemoryFailCount int64 // sends failed waiting for memory, with TimeoutExecption or ctx.Err()
+
+	// latency of each send of batches, including retries, estimated by the bounds of prometheus.DefBuckets
+	SendLatencyP50 time.Duration
+	SendLatencyP90 time.Duration
+	SendLatencyP99 time.Duration
+}
+
+// Stats returns a snapshot of the status of the producer.
+func (producer *Producer) Stats() ProducerStats {
+	stats := ProducerStats{
+		BufferedBytes:       atomic.LoadInt64(&producer.producerLogGroupSize),
+		MaxBufferedBytes:    producer.producerConfig.TotalSizeLnBytes,
+		InFlightIoWorkers:   atomic.LoadInt64(&producer.threadPool.ioworker.taskCount),
+		WaitMemoryFailCount: producer.monitor.waitMemoryFailCount.Load(),
+		SendLatencyP50:      producer.monitor.sendLatency.percentile(0.5),
+		SendLatencyP90:      producer.monitor.sendLatency.percentile(0.9),
+		SendLatencyP99:      producer.monitor.sendLatency.percentile(0.99),
+	}
+
+	producer.logAccumulator.lock.Lock()
+	for _, batch := range producer.logAccumulator.logGroupData {
+		if batch != nil {
+			stats.AccumulatorBatches++
+		}
+	}
+	producer.logAccumulator.lock.Unlock()
+
+	retryQueue := producer.mover.retryQueue
+	retryQueue.mutex.Lock()
+	stats.RetryQueueBatches = retryQueue.Len()
+	retryQueue.mutex.Unlock()
+
+	producer.monitor.logstores.Range(func(_, value interface{}) bool {
+		logstore := value.(*logstoreMetrics)
+		stats.SuccessBatches += logstore.successBatches.Load()
+		stats.FailedBatches += logstore.failedBatches.Load()
+		stats.RetryCount += logstore.retries.Load()
+		return true
+	})
+	return stats
+}
+
+type logstoreKey struct {
+	project  string
+	logstore string
+}
+
+// logstoreMetrics is the cumulative metrics of the batches of a logstore.
+type logstoreMetrics struct {
+	successBatches atomic.Int64
+	failedBatches  atomic.Int64
+	retries        atomic.Int64
+	successLogs    atomic.Int64
+	failedLogs     atomic.Int64
+	sendLatency    latencyHistogram
+}
+
+// latencyBuckets is prometheus.DefBuckets, in seconds.
+var latencyBuckets = [...]float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// latencyHistogram counts latencies by latencyBuckets.
+type latencyHistogram struct {
+	counts [len(latencyBuckets) + 1]atomic.Uint64 // by latencyBuckets, followed by +Inf
+	count  atomic.Uint64
+	sum    uberatomic.Float64 // in seconds
+}
+
+func (h *latencyHistogram) observe(d time.Duration) {
+	seconds := d.Seconds()
+	i := 0
+	for i < len(latencyBuckets) && seconds > latencyBuckets[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	h.sum.Add(seconds)
+}
+
+// percentile estimates the p-th percentile by linear interpolation in the bucket it falls in,
+// like histogram_quantile of prometheus.
+func (h *latencyHistogram) percentile(p float64) time.Duration {
+	total := h.count.Load()
+	if total == 0 {
+		return 0
+	}
+	rank := p * float64(total)
+	cumulative := uint64(0)
+	for i, bound := range latencyBuckets {
+		count := h.counts[i].Load()
+		if count > 0 && float64(cumulative+count) >= rank {
+			lower := 0.0
+			if i > 0 {
+				lower = latencyBuckets[i-1]
+			}
+			seconds := lower + (bound-lower)*(rank-float64(cumulative))/float64(count)
+			return time.Duration(seconds * float64(time.Second))
+		}
+		cumulative += count
+	}
+	return time.Duration(latencyBuckets[len(latencyBuckets)-1] * float64(time.Second))
+}
+
+func (h *latencyHistogram) metric(desc *prometheus.Desc, labelValues ...string) (prometheus.Metric, error) {
+	buckets := make(map[float64]uint64, len(latencyBuckets))
+	cumulative := uint64(0)
+	for i, bound := range latencyBuckets {
+		cumulative += h.counts[i].Load()
+		buckets[bound] = cumulative
+	}
+	return prometheus.NewConstHistogram(desc, cumulative+h.counts[len(latencyBuckets)].Load(), h.sum.Load(), buckets, labelValues...)
+}
+
+// ProducerCollector is a prometheus.Collector of the status of a producer, the batches sent are labeled
+// by project and logstore.
+//
+//	prometheus.MustRegister(producer.NewProducerCollector(p, prometheus.Labels{"producer": "access-log"}))
+type ProducerCollector struct {
+	producer       *Producer
+	bufferedBytes  *prometheus.Desc
+	maxBuffered    *prometheus.Desc
+	accumulator    *prometheus.Desc
+	retryQueue     *prometheus.Desc
+	ioWorkers      *prometheus.Desc
+	waitMemoryFail *prometheus.Desc
+	batches        *prometheus.Desc
+	logs           *prometheus.Desc
+	retries        *prometheus.Desc
+	sendLatency    *prometheus.Desc
+}
+
+// NewProducerCollector creates a ProducerCollector of producer, constLabels tell producers apart if more than
+// one producer is registered.
+func NewProducerCollector(producer *Producer, constLabels prometheus.Labels) *ProducerCollector {
+	labels := []string{"project", "logstore"}
+	return &ProducerCollector{
+		producer: producer,
+		bufferedBytes: prometheus.NewDesc("sls_producer_buffered_bytes",
+			"Size of logs in the producer not sent successfully or failed yet.", nil, constLabels),
+		maxBuffered: prometheus.NewDesc("sls_producer_max_buffered_bytes",
+			"TotalSizeLnBytes of the producer, sends block once the buffered bytes exceed it.", nil, constLabels),
+		accumulator: prometheus.NewDesc("sls_producer_accumulator_batches",
+			"Number of batches open in the accumulator.", nil, constLabels),
+		retryQueue: prometheus.NewDesc("sls_producer_retry_queue_batches",
+			"Number of batches waiting for the backoff to retry.", nil, constLabels),
+		ioWorkers: prometheus.NewDesc("sls_producer_inflight_io_workers",
+			"Number of batches being sent, or waiting for a free io worker.", nil, constLabels),
+		waitMemoryFail: prometheus.NewDesc("sls_producer_wait_memory_failures_total",
+			"Number of sends failed waiting for memory.", nil, constLabels),
+		batches: prometheus.NewDesc("sls_producer_batches_total",
+			"Number of batches sent successfully or failed after retries, by result.", append(labels, "result"), constLabels),
+		logs: prometheus.NewDesc("sls_producer_logs_total",
+			"Number of logs sent successfully or failed after retries, by result.", append(labels, "result"), constLabels),
+		retries: prometheus.NewDesc("sls_producer_retries_total",
+			"Number of sends of batches failed and retried.", labels, constLabels),
+		sendLatency: prometheus.NewDesc("sls_producer_send_duration_seconds",
+			"Latency of each send of batches, including retries.", labels, constLabels),
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (c *ProducerCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, desc := range []*prometheus.Desc{c.bufferedBytes, c.maxBuffered, c.accumulator, c.retryQueue, c.ioWorkers,
+		c.waitMemoryFail, c.batches, c.logs, c.retries, c.sendLatency} {
+		ch <- desc
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *ProducerCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.producer.Stats()
+	ch <- prometheus.MustNewConstMetric(c.bufferedBytes, prometheus.GaugeValue, float64(stats.BufferedBytes))
+	ch <- prometheus.MustNewConstMetric(c.maxBuffered, prometheus.GaugeValue, float64(stats.MaxBufferedBytes))
+	ch <- prometheus.MustNewConstMetric(c.accumulator, prometheus.GaugeValue, float64(stats.AccumulatorBatches))
+	ch <- prometheus.MustNewConstMetric(c.retryQueue, prometheus.GaugeValue, float64(stats.RetryQueueBatches))
+	ch <- prometheus.MustNewConstMetric(c.ioWorkers, prometheus.GaugeValue, float64(stats.InFlightIoWorkers))
+	ch <- prometheus.MustNewConstMetric(c.waitMemoryFail, prometheus.CounterValue, float64(stats.WaitMemoryFailCount))
+
+	c.producer.monitor.logstores.Range(func(key, value interface{}) bool {
+		k, logstore := key.(logstoreKey), value.(*logstoreMetrics)
+		ch <- prometheus.MustNewConstMetric(c.batches, prometheus.CounterValue, float64(logstore.successBatches.Load()), k.project, k.logstore, "success")
+		ch <- prometheus.MustNewConstMetric(c.batches, prometheus.CounterValue, float64(logstore.failedBatches.Load()), k.project, k.logstore, "fail")
+		ch <- prometheus.MustNewConstMetric(c.logs, prometheus.CounterValue, float64(logstore.successLogs.Load()), k.project, k.logstore, "success")
+		ch <- prometheus.MustNewConstMetric(c.logs, prometheus.CounterValue, float64(logstore.failedLogs.Load()), k.project, k.logstore, "fail")
+		ch <- prometheus.MustNewConstMetric(c.retries, prometheus.CounterValue, float64(logstore.retries.Load()), k.project, k.logstore)
+		if metric, err := logstore.sendLatency.metric(c.sendLatency, k.project, k.logstore); err == nil {
+			ch <- metric
+		} else {
+			ch <- prometheus.NewInvalidMetric(c.sendLatency, err)
+		}
+		return true
+	})
+}
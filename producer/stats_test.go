@@ -0,0 +1,67 @@
+package producer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyHistogram(t *testing.T) {
+	h := &latencyHistogram{}
+	require.Equal(t, time.Duration(0), h.percentile(0.5))
+	for i := 0; i < 90; i++ {
+		h.observe(2 * time.Millisecond) // (0, 5ms]
+	}
+	for i := 0; i < 10; i++ {
+		h.observe(300 * time.Millisecond) // (250ms, 500ms]
+	}
+	require.Equal(t, 2500*time.Microsecond, h.percentile(0.45))
+	require.Equal(t, 5*time.Millisecond, h.percentile(0.9))
+	require.Equal(t, 475*time.Millisecond, h.percentile(0.99))
+	h.observe(time.Minute)
+	require.Equal(t, 10*time.Second, h.percentile(1))
+}
+
+func TestProducerStats(t *testing.T) {
+	producer, _ := newFakeClientProducer(t, GetDefaultProducerConfig())
+	log := GenerateLog(uint32(time.Now().Unix()), map[string]string{"content": "test"})
+	require.NoError(t, producer.SendLog("test-project", "test-logstore", "", "", log))
+	require.NoError(t, producer.SendLog("test-project", "missing-logstore", "", "", log))
+	stats := producer.Stats()
+	require.Equal(t, 2, stats.AccumulatorBatches)
+	require.Equal(t, 2*int64(GetLogSizeCalculate(log)), stats.BufferedBytes)
+	require.Equal(t, producer.producerConfig.TotalSizeLnBytes, stats.MaxBufferedBytes)
+
+	producer.Start()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	require.NoError(t, producer.Flush(ctx))
+	stats = producer.Stats()
+	require.Equal(t, 0, stats.AccumulatorBatches)
+	require.Equal(t, int64(0), stats.BufferedBytes)
+	require.Equal(t, int64(1), stats.SuccessBatches)
+	require.Equal(t, int64(1), stats.FailedBatches)
+	require.Greater(t, stats.SendLatencyP99, time.Duration(0))
+
+	collector := NewProducerCollector(producer, prometheus.Labels{"producer": "test"})
+	registry := prometheus.NewPedanticRegistry()
+	require.NoError(t, registry.Register(collector))
+	require.NoError(t, testutil.GatherAndCompare(registry, strings.NewReader(`
+# HELP sls_producer_batches_total Number of batches sent successfully or failed after retries, by result.
+# TYPE sls_producer_batches_total counter
+sls_producer_batches_total{logstore="missing-logstore",producer="test",project="test-project",result="fail"} 1
+sls_producer_batches_total{logstore="missing-logstore",producer="test",project="test-project",result="success"} 0
+sls_producer_batches_total{logstore="test-logstore",producer="test",project="test-project",result="fail"} 0
+sls_producer_batches_total{logstore="test-logstore",producer="test",project="test-project",result="success"} 1
+# HELP sls_producer_buffered_bytes Size of logs in the producer not sent successfully or failed yet.
+# TYPE sls_producer_buffered_bytes gauge
+sls_producer_buffered_bytes{producer="test"} 0
+`), "sls_producer_batches_total", "sls_producer_buffered_bytes"))
+	require.Equal(t, 2, testutil.CollectAndCount(collector, "sls_producer_send_duration_seconds"))
+	producer.SafeClose()
+}
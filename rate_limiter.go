@@ -0,0 +1,270 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// OperationClass classifies SLS apis for rate limiting.
+type OperationClass string
+
+const (
+	OperationClassWrite OperationClass = "write" // PutLogs, PostLogStoreLogs
+	OperationClassRead  OperationClass = "read"  // PullLogs, GetCursor
+	OperationClassQuery OperationClass = "query" // GetLogs, GetHistograms
+	OperationClassAdmin OperationClass = "admin" // all other apis, eg. CreateLogStore, ListShards
+)
+
+// RateLimit is the limit of a token bucket.
+type RateLimit struct {
+	Rate  float64 // requests per second
+	Burst int     // max requests sent at once, defaults to ceil(Rate)
+}
+
+type rateLimitKey struct {
+	project  string
+	logstore string
+	class    OperationClass
+}
+
+// RateLimiter limits the requests sent by clients with token buckets.
+//
+// Limits are set per project, logstore and operation class, where an empty value matches any.
+// A request is limited by the most specific limit it matches, and requests matching the same
+// limit share one bucket. Requests matching no limit are not limited.
+//
+// The rate of a bucket adapts to the quota of SLS with AIMD: it is multiplied by the decrease
+// factor once a quota error, eg. WriteQuotaExceed, is returned, and is increased additively by
+// each successful request until the rate of the limit is reached again.
+//
+// A RateLimiter is safe for concurrent use and can be shared by clients.
+type RateLimiter struct {
+	mu       sync.Mutex
+	limits   map[rateLimitKey]RateLimit
+	buckets  map[rateLimitKey]*tokenBucket
+	increase float64
+	decrease float64
+}
+
+// NewRateLimiter creates a RateLimiter without any limit,
+// the rate of buckets is increased by 1% of the limit per successful request,
+// and halved on quota errors.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{
+		limits:   map[rateLimitKey]RateLimit{},
+		buckets:  map[rateLimitKey]*tokenBucket{},
+		increase: 0.01,
+		decrease: 0.5,
+	}
+}
+
+// SetLimit sets the limit of requests of class to logstore of project, empty values match any.
+// eg. SetLimit("my-project", "", sls.OperationClassWrite, limit) limits all writes to my-project.
+// A limit with a non-positive Rate means no limit.
+func (l *RateLimiter) SetLimit(project, logstore string, class OperationClass, limit RateLimit) *RateLimiter {
+	key := rateLimitKey{project, logstore, class}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits[key] = limit
+	delete(l.buckets, key)
+	return l
+}
+
+// SetAIMD sets the adaptation of bucket rates on quota errors.
+// increase is the ratio of the limit added to the rate per successful request,
+// decrease is the factor the rate is multiplied by on a quota error, it should be in range (0, 1).
+func (l *RateLimiter) SetAIMD(increase, decrease float64) *RateLimiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.increase = increase
+	l.decrease = decrease
+	for _, b := range l.buckets {
+		b.setAIMD(increase, decrease)
+	}
+	return l
+}
+
+// Wait blocks until a request of class to logstore of project is allowed or ctx is done.
+func (l *RateLimiter) Wait(ctx context.Context, project, logstore string, class OperationClass) error {
+	b := l.bucket(project, logstore, class)
+	if b == nil {
+		return nil
+	}
+	return b.wait(ctx)
+}
+
+// Feedback adapts the rate of the bucket of a request to the error returned by SLS, err is nil on success.
+func (l *RateLimiter) Feedback(project, logstore string, class OperationClass, err error) {
+	b := l.bucket(project, logstore, class)
+	if b == nil {
+		return
+	}
+	if isQuotaExceedError(err) {
+		b.onQuotaExceed()
+	} else if err == nil {
+		b.onSuccess()
+	}
+}
+
+// Rate returns the current rate of the bucket of requests of class to logstore of project,
+// it returns +Inf if the requests are not limited.
+func (l *RateLimiter) Rate(project, logstore string, class OperationClass) float64 {
+	b := l.bucket(project, logstore, class)
+	if b == nil {
+		return math.Inf(1)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.rate
+}
+
+// bucket returns the bucket of the most specific limit a request matches, or nil.
+func (l *RateLimiter) bucket(project, logstore string, class OperationClass) *tokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	candidates := [...]rateLimitKey{
+		{project, logstore, class},
+		{project, logstore, ""},
+		{project, "", class},
+		{project, "", ""},
+		{"", "", class},
+		{"", "", ""},
+	}
+	for _, key := range candidates {
+		limit, ok := l.limits[key]
+		if !ok {
+			continue
+		}
+		if limit.Rate <= 0 {
+			return nil
+		}
+		b, ok := l.buckets[key]
+		if !ok {
+			b = newTokenBucket(limit, l.increase, l.decrease)
+			l.buckets[key] = b
+		}
+		return b
+	}
+	return nil
+}
+
+// interceptor returns an Interceptor that waits for the bucket of each request
+// and adapts the bucket to the response.
+func (l *RateLimiter) interceptor() Interceptor {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+			op := resolveOperation(req.Method, req.URI)
+			class := op.class()
+			if err := l.Wait(ctx, req.Project, op.Logstore, class); err != nil {
+				return nil, err
+			}
+			resp, err := next(ctx, req)
+			l.Feedback(req.Project, op.Logstore, class, err)
+			return resp, err
+		}
+	}
+}
+
+func isQuotaExceedError(err error) bool {
+	return errors.Is(err, ErrQuotaExceeded)
+}
+
+// the rate is never decreased below 1% of the limit
+const minRateRatio = 0.01
+
+type tokenBucket struct {
+	mu       sync.Mutex
+	maxRate  float64
+	rate     float64
+	burst    float64
+	tokens   float64
+	last     time.Time
+	increase float64 // rate added per successful request
+	decrease float64
+	// the rate is decreased at most once per window, since the requests
+	// in flight when the quota is exceeded usually fail together
+	lastDecrease time.Time
+}
+
+func newTokenBucket(limit RateLimit, increase, decrease float64) *tokenBucket {
+	burst := float64(limit.Burst)
+	if burst <= 0 {
+		burst = math.Max(1, math.Ceil(limit.Rate))
+	}
+	b := &tokenBucket{
+		maxRate: limit.Rate,
+		rate:    limit.Rate,
+		burst:   burst,
+		tokens:  burst,
+		last:    time.Now(),
+	}
+	b.setAIMD(increase, decrease)
+	return b
+}
+
+func (b *tokenBucket) setAIMD(increase, decrease float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.increase = b.maxRate * increase
+	b.decrease = decrease
+}
+
+// refill adds the tokens generated since last refill, b.mu must be held.
+func (b *tokenBucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.burst, b.tokens+elapsed*b.rate)
+	}
+	b.last = now
+}
+
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refill(time.Now())
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / b.rate * float64(time.Second))
+	}
+	b.mu.Unlock()
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// give back the token reserved
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+func (b *tokenBucket) onSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.rate < b.maxRate {
+		b.refill(time.Now())
+		b.rate = math.Min(b.maxRate, b.rate+b.increase)
+	}
+}
+
+func (b *tokenBucket) onQuotaExceed() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if now.Sub(b.lastDecrease) < time.Second {
+		return
+	}
+	b.refill(now)
+	b.rate = math.Max(b.maxRate*minRateRatio, b.rate*b.decrease)
+	b.lastDecrease = now
+}
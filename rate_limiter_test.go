@@ -0,0 +1,104 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiterWait(t *testing.T) {
+	limiter := NewRateLimiter().SetLimit("p", "", OperationClassWrite, RateLimit{Rate: 20, Burst: 1})
+
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		require.NoError(t, limiter.Wait(context.Background(), "p", "ls", OperationClassWrite))
+	}
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+
+	// not limited
+	start = time.Now()
+	for i := 0; i < 100; i++ {
+		require.NoError(t, limiter.Wait(context.Background(), "p", "ls", OperationClassRead))
+		require.NoError(t, limiter.Wait(context.Background(), "other", "ls", OperationClassWrite))
+	}
+	require.Less(t, time.Since(start), 100*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	limiter.SetLimit("p", "", OperationClassWrite, RateLimit{Rate: 0.1, Burst: 1})
+	require.NoError(t, limiter.Wait(ctx, "p", "ls", OperationClassWrite))
+	require.ErrorIs(t, limiter.Wait(ctx, "p", "ls", OperationClassWrite), context.DeadlineExceeded)
+}
+
+func TestRateLimiterMatch(t *testing.T) {
+	limiter := NewRateLimiter().
+		SetLimit("", "", "", RateLimit{Rate: 1}).
+		SetLimit("", "", OperationClassQuery, RateLimit{Rate: 2}).
+		SetLimit("p", "", "", RateLimit{Rate: 3}).
+		SetLimit("p", "ls", OperationClassWrite, RateLimit{Rate: 4}).
+		SetLimit("p", "unlimited", "", RateLimit{})
+
+	require.Equal(t, 4.0, limiter.Rate("p", "ls", OperationClassWrite))
+	require.Equal(t, 3.0, limiter.Rate("p", "ls", OperationClassQuery))
+	require.Equal(t, 3.0, limiter.Rate("p", "other", OperationClassWrite))
+	require.Equal(t, 2.0, limiter.Rate("other", "ls", OperationClassQuery))
+	require.Equal(t, 1.0, limiter.Rate("other", "ls", OperationClassAdmin))
+	require.True(t, math.IsInf(limiter.Rate("p", "unlimited", OperationClassWrite), 1))
+}
+
+func TestRateLimiterAIMD(t *testing.T) {
+	limiter := NewRateLimiter().SetLimit("p", "", OperationClassWrite, RateLimit{Rate: 100}).SetAIMD(0.1, 0.5)
+	quotaErr := &Error{HTTPCode: 403, Code: SHARD_WRITE_QUOTA_EXCEED}
+
+	limiter.Feedback("p", "ls", OperationClassWrite, quotaErr)
+	require.Equal(t, 50.0, limiter.Rate("p", "ls", OperationClassWrite))
+	// requests in flight failing together only decrease the rate once
+	limiter.Feedback("p", "ls", OperationClassWrite, quotaErr)
+	require.Equal(t, 50.0, limiter.Rate("p", "ls", OperationClassWrite))
+	// other errors are ignored
+	limiter.Feedback("p", "ls", OperationClassWrite, &Error{HTTPCode: 500, Code: INTERNAL_SERVER_ERROR})
+	require.Equal(t, 50.0, limiter.Rate("p", "ls", OperationClassWrite))
+
+	limiter.Feedback("p", "ls", OperationClassWrite, nil)
+	require.InDelta(t, 60.0, limiter.Rate("p", "ls", OperationClassWrite), 1e-9)
+	for i := 0; i < 10; i++ {
+		limiter.Feedback("p", "ls", OperationClassWrite, nil)
+	}
+	require.Equal(t, 100.0, limiter.Rate("p", "ls", OperationClassWrite))
+}
+
+func TestClientRateLimiter(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("POST", fmt.Sprintf("http://%s.%s/logstores/%s", project, endpoint, logstore),
+		httpmock.NewStringResponder(403, `{"errorCode":"WriteQuotaExceed","errorMessage":"write quota exceed"}`))
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore),
+		httpmock.NewStringResponder(200, `[]`))
+
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	limiter := NewRateLimiter().
+		SetLimit(project, "", OperationClassWrite, RateLimit{Rate: 100}).
+		SetLimit(project, "", OperationClassAdmin, RateLimit{Rate: 10, Burst: 1})
+	client.SetRateLimiter(limiter)
+
+	// the project path
+	logGroup := &LogGroup{Logs: []*Log{{Time: proto.Uint32(uint32(time.Now().Unix()))}}}
+	require.Error(t, client.PutLogs(project, logstore, logGroup))
+	require.Equal(t, 50.0, limiter.Rate(project, logstore, OperationClassWrite))
+
+	// the client path
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		_, err := client.GetCheckpoint(project, logstore, "cg")
+		require.NoError(t, err)
+	}
+	require.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+}
@@ -3,6 +3,7 @@ package sls
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -41,25 +42,15 @@ func retryReadErrorCheck(ctx context.Context, err error) (bool, error) {
 	if err == nil {
 		return false, nil
 	}
-	switch e := err.(type) {
-	case *url.Error:
-		return true, e
-	case *Error:
-		if RetryOnServerErrorEnabled {
-			if e.HTTPCode >= 500 && e.HTTPCode <= 599 {
-				return true, e
-			}
-		}
-	case *BadResponseError:
-		if RetryOnServerErrorEnabled {
-			if e.HTTPCode >= 500 && e.HTTPCode <= 599 {
-				return true, e
-			}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return true, err
+	}
+	if RetryOnServerErrorEnabled {
+		if httpCode := httpCodeOf(err); httpCode >= 500 && httpCode <= 599 {
+			return true, err
 		}
-	default:
-		return false, e
 	}
-
 	return false, err
 }
 
@@ -68,23 +59,12 @@ func retryWriteErrorCheck(ctx context.Context, err error) (bool, error) {
 		return false, nil
 	}
 
-	switch e := err.(type) {
-	case *Error:
-		if RetryOnServerErrorEnabled {
-			if e.HTTPCode == 500 || e.HTTPCode == 502 || e.HTTPCode == 503 {
-				return true, e
-			}
+	if RetryOnServerErrorEnabled {
+		switch httpCodeOf(err) {
+		case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable:
+			return true, err
 		}
-	case *BadResponseError:
-		if RetryOnServerErrorEnabled {
-			if e.HTTPCode == 500 || e.HTTPCode == 502 || e.HTTPCode == 503 {
-				return true, e
-			}
-		}
-	default:
-		return false, e
 	}
-
 	return false, err
 }
 
@@ -99,16 +79,31 @@ func request(project *LogProject, method, uri string, headers map[string]string,
 	var mockErr *mockErrorRetry
 
 	project.init()
-	ctx, cancel := context.WithTimeout(context.Background(), project.retryTimeout)
+	start := time.Now()
+	op := resolveOperation(method, uri)
+	ctx, span := startOperationSpan(project.requestContext(), project.tracer, project.Name, op)
+	// retryCtx only limits the retries, the response body is read after request returns
+	retryCtx, cancel := context.WithTimeout(ctx, project.retryTimeout)
 	defer cancel()
+	attempt := 0
+
+	b, readErrorCheck, writeErrorCheck := backoff.BackOff(backoff.NewExponentialBackOff()), retryReadErrorCheck, retryWriteErrorCheck
+	if project.retryPolicy != nil {
+		b = project.retryPolicy.newBackOff()
+		readErrorCheck = project.retryPolicy.errorCheck(method)
+		writeErrorCheck = readErrorCheck
+	}
 
 	//fmt.Println("request ", project, method, uri, headers, body)
 	// all GET method is read function
 	if method == http.MethodGet {
-		err = RetryWithCondition(ctx, backoff.NewExponentialBackOff(), func() (bool, error) {
+		err = RetryWithCondition(retryCtx, b, func() (bool, error) {
 			if len(mock) == 0 {
 				//fmt.Println("real request", project, method, uri, headers, body)
-				r, slsErr = realRequest(ctx, project, method, uri, headers, body)
+				attempt++
+				attemptCtx, attemptSpan := startAttemptSpan(ctx, project.tracer, method, op, attempt)
+				r, slsErr = realRequest(attemptCtx, project, method, uri, headers, body)
+				endSpan(attemptSpan, r, slsErr)
 				//fmt.Println("real request done")
 			} else {
 				r, mockErr = nil, mock[0].(*mockErrorRetry)
@@ -120,12 +115,15 @@ func request(project *LogProject, method, uri string, headers map[string]string,
 				}
 				slsErr = &mockErr.Err
 			}
-			return retryReadErrorCheck(ctx, slsErr)
+			return readErrorCheck(retryCtx, slsErr)
 		})
 	} else {
-		err = RetryWithCondition(ctx, backoff.NewExponentialBackOff(), func() (bool, error) {
+		err = RetryWithCondition(retryCtx, b, func() (bool, error) {
 			if len(mock) == 0 {
-				r, slsErr = realRequest(ctx, project, method, uri, headers, body)
+				attempt++
+				attemptCtx, attemptSpan := startAttemptSpan(ctx, project.tracer, method, op, attempt)
+				r, slsErr = realRequest(attemptCtx, project, method, uri, headers, body)
+				endSpan(attemptSpan, r, slsErr)
 			} else {
 				r, mockErr = nil, mock[0].(*mockErrorRetry)
 				mockErr.RetryCnt--
@@ -136,20 +134,36 @@ func request(project *LogProject, method, uri string, headers map[string]string,
 				}
 				slsErr = &mockErr.Err
 			}
-			return retryWriteErrorCheck(ctx, slsErr)
+			return writeErrorCheck(retryCtx, slsErr)
 		})
 	}
 
-	if err != nil {
-		return r, err
+	if err == nil {
+		err = slsErr
+	}
+	endSpan(span, r, err)
+	if project.metrics != nil {
+		project.metrics.observe(project.Name, op, start, attempt, err)
 	}
-	return r, slsErr
+	return r, err
 }
 
 // request sends a request to alibaba cloud Log Service.
 // @note if error is nil, you must call http.Response.Body.Close() to finalize reader
 func realRequest(ctx context.Context, project *LogProject, method, uri string, headers map[string]string,
 	body []byte) (*http.Response, error) {
+	if project.endpoints == nil {
+		return realRequestTo(ctx, project, project.Endpoint, project.getBaseURL(), method, uri, headers, body)
+	}
+	// fail over between endpoints on connection errors
+	return project.endpoints.do(func(endpoint string) (*http.Response, error) {
+		return realRequestTo(ctx, project, endpoint, project.baseURLOf(endpoint), method, uri, headers, body)
+	})
+}
+
+// realRequestTo signs a request and sends it to baseURL of endpoint.
+func realRequestTo(ctx context.Context, project *LogProject, endpoint, baseURL, method, uri string, headers map[string]string,
+	body []byte) (*http.Response, error) {
 
 	// The caller should provide 'x-log-bodyrawsize' header
 	if _, ok := headers[HTTPHeaderBodyRawSize]; !ok {
@@ -157,7 +171,6 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 	}
 
 	// SLS public request headers
-	baseURL := project.getBaseURL()
 	headers[HTTPHeaderHost] = baseURL
 	headers[HTTPHeaderAPIVersion] = version
 	if len(project.UserAgent) > 0 {
@@ -166,19 +179,13 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 		headers[HTTPHeaderUserAgent] = DefaultLogUserAgent
 	}
 
-	stsToken := project.SecurityToken
-	accessKeyID := project.AccessKeyID
-	accessKeySecret := project.AccessKeySecret
-
-	if project.credentialProvider != nil {
-		c, err := project.credentialProvider.GetCredentials()
-		if err != nil {
-			return nil, NewClientError(fmt.Errorf("fail to get credentials: %w", err))
-		}
-		stsToken = c.SecurityToken
-		accessKeyID = c.AccessKeyID
-		accessKeySecret = c.AccessKeySecret
+	cred, err := project.getCredentials()
+	if err != nil {
+		return nil, err
 	}
+	stsToken := cred.SecurityToken
+	accessKeyID := cred.AccessKeyID
+	accessKeySecret := cred.AccessKeySecret
 
 	// Access with token
 	if stsToken != "" {
@@ -204,22 +211,76 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 		headers[HTTPHeaderDate] = nowRFC1123()
 		signer = NewSignerV1(accessKeyID, accessKeySecret)
 	}
+	if project.newSigner != nil {
+		signer = project.newSigner(cred)
+	}
 	if err := signer.Sign(method, uri, headers, body); err != nil {
 		return nil, err
 	}
 
 	addHeadersAfterSign(project.commonHeaders, headers)
 
+	var rt RoundTrip = func(ctx context.Context, req *RoundTripRequest) (*http.Response, error) {
+		return doRequest(ctx, project, baseURL, req)
+	}
+	rt = chainInterceptors(rt, project.interceptors)
+	rt = statusErrorInterceptor(func(body []byte, resp *http.Response) error {
+		err := &Error{}
+		if jErr := json.Unmarshal(body, err); jErr != nil {
+			return NewBadResponseError(string(body), resp.Header, resp.StatusCode)
+		}
+		err.HTTPCode = int32(resp.StatusCode)
+		err.RequestID = resp.Header.Get(RequestIDHeader)
+		return err
+	})(rt)
+	if project.metrics != nil {
+		rt = project.metrics.interceptor()(rt)
+	}
+	if project.rateLimiter != nil {
+		rt = project.rateLimiter.interceptor()(rt)
+	}
+	if project.breaker != nil {
+		rt = project.breaker.interceptor(endpoint)(rt)
+	}
+	return rt(ctx, &RoundTripRequest{
+		Project: project.Name,
+		Method:  method,
+		URI:     uri,
+		Headers: headers,
+		Body:    body,
+	})
+}
+
+// getCredentials returns the credentials of the credentials provider of p if set,
+// otherwise the static credentials of p.
+func (p *LogProject) getCredentials() (Credentials, error) {
+	if p.credentialProvider != nil {
+		c, err := p.credentialProvider.GetCredentials()
+		if err != nil {
+			return Credentials{}, NewClientError(fmt.Errorf("fail to get credentials: %w", err))
+		}
+		return c, nil
+	}
+	return Credentials{
+		AccessKeyID:     p.AccessKeyID,
+		AccessKeySecret: p.AccessKeySecret,
+		SecurityToken:   p.SecurityToken,
+	}, nil
+}
+
+// doRequest sends a signed request to baseURL + req.URI, the body of a non-200 response is read
+// into memory to be parsed into an error after the interceptors.
+func doRequest(ctx context.Context, project *LogProject, baseURL string, rtReq *RoundTripRequest) (*http.Response, error) {
 	// Initialize http request
-	reader := bytes.NewReader(body)
+	reader := bytes.NewReader(rtReq.Body)
 
 	// Handle the endpoint
-	urlStr := fmt.Sprintf("%s%s", baseURL, uri)
-	req, err := http.NewRequest(method, urlStr, reader)
+	urlStr := fmt.Sprintf("%s%s", baseURL, rtReq.URI)
+	req, err := http.NewRequestWithContext(ctx, rtReq.Method, urlStr, reader)
 	if err != nil {
 		return nil, NewClientError(err)
 	}
-	for k, v := range headers {
+	for k, v := range rtReq.Headers {
 		req.Header.Add(k, v)
 	}
 	if IsDebugLevelMatched(5) {
@@ -235,20 +296,14 @@ func realRequest(ctx context.Context, project *LogProject, method, uri string, h
 		return nil, err
 	}
 
-	// Parse the sls error from body.
 	if resp.StatusCode != http.StatusOK {
-		err := &Error{}
-		err.HTTPCode = (int32)(resp.StatusCode)
 		defer resp.Body.Close()
 		buf, ioErr := ioutil.ReadAll(resp.Body)
 		if ioErr != nil {
 			return nil, NewBadResponseError(ioErr.Error(), resp.Header, resp.StatusCode)
 		}
-		if jErr := json.Unmarshal(buf, err); jErr != nil {
-			return nil, NewBadResponseError(string(buf), resp.Header, resp.StatusCode)
-		}
-		err.RequestID = resp.Header.Get(RequestIDHeader)
-		return nil, err
+		resp.Body = newBufferedBody(buf)
+		return resp, nil
 	}
 	if IsDebugLevelMatched(5) {
 		dump, e := httputil.DumpResponse(resp, true)
@@ -1,12 +1,43 @@
 package sls
 
 import (
+	"errors"
+	"fmt"
+
 	"golang.org/x/net/context"
 
 	"github.com/cenkalti/backoff"
-	"github.com/pkg/errors"
 )
 
+// retryStoppedError is returned when the retries are stopped by the context,
+// it matches both the context error and the error of the last attempt with errors.Is and errors.As.
+type retryStoppedError struct {
+	ctxErr error
+	err    error // error of the last attempt, nil if no attempt is made
+}
+
+func newRetryStoppedError(ctxErr, err error) error {
+	return &retryStoppedError{ctxErr: ctxErr, err: err}
+}
+
+func (e *retryStoppedError) Error() string {
+	return fmt.Sprintf("stopped retrying err: %v: %v", e.err, e.ctxErr)
+}
+
+func (e *retryStoppedError) Unwrap() error {
+	return e.err
+}
+
+// Cause returns the context error, as the error wrapped by errors.Wrapf before, so that
+// github.com/pkg/errors.Cause keeps working.
+func (e *retryStoppedError) Cause() error {
+	return e.ctxErr
+}
+
+func (e *retryStoppedError) Is(target error) bool {
+	return errors.Is(e.ctxErr, target)
+}
+
 // Retry execute the input operation immediately at first,
 // and do an exponential backoff retry when failed.
 // The default max elapsed time is 15 minutes.
@@ -36,7 +67,7 @@ func RetryWithBackOff(ctx context.Context, b backoff.BackOff, o backoff.Operatio
 	for {
 		select {
 		case <-ctx.Done():
-			return errors.Wrapf(ctx.Err(), "stopped retrying err: %v", err)
+			return newRetryStoppedError(ctx.Err(), err)
 		default:
 			select {
 			case _, ok := <-ticker.C:
@@ -48,7 +79,7 @@ func RetryWithBackOff(ctx context.Context, b backoff.BackOff, o backoff.Operatio
 					return nil
 				}
 			case <-ctx.Done():
-				return errors.Wrapf(ctx.Err(), "stopped retrying err: %v", err)
+				return newRetryStoppedError(ctx.Err(), err)
 			}
 		}
 	}
@@ -66,7 +97,7 @@ func RetryWithCondition(ctx context.Context, b backoff.BackOff, o ConditionOpera
 	for {
 		select {
 		case <-ctx.Done():
-			return errors.Wrapf(ctx.Err(), "stopped retrying err: %v", err)
+			return newRetryStoppedError(ctx.Err(), err)
 		default:
 			select {
 			case _, ok := <-ticker.C:
@@ -78,7 +109,7 @@ func RetryWithCondition(ctx context.Context, b backoff.BackOff, o ConditionOpera
 					return err
 				}
 			case <-ctx.Done():
-				return errors.Wrapf(ctx.Err(), "stopped retrying err: %v", err)
+				return newRetryStoppedError(ctx.Err(), err)
 			}
 		}
 	}
@@ -95,7 +126,7 @@ func RetryWithAttempt(ctx context.Context, maxAttempt int, o ConditionOperation)
 		// make sure we check ctx.Done() first
 		select {
 		case <-ctx.Done():
-			return errors.Wrapf(ctx.Err(), "stopped retrying err: %v", err)
+			return newRetryStoppedError(ctx.Err(), err)
 		default:
 		}
 
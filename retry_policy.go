@@ -0,0 +1,166 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/cenkalti/backoff"
+)
+
+// RetryPolicy controls how a client retries failed requests.
+//
+// Requests are retried with exponential backoff until MaxAttempts is reached,
+// MaxElapsedTime is exceeded, or the retry timeout of the client expires.
+// A request is retried if the http status code of the error is in RetryableHTTPCodes,
+// or the sls error code is in RetryableErrorCodes, or it fails with a network error
+// and it is safe to send again, see RetryIdempotentWrites.
+type RetryPolicy struct {
+	// MaxAttempts is the max number of attempts of a request, including the first one.
+	// Zero means no limit.
+	MaxAttempts int
+	// MaxElapsedTime stops retrying once the time elapsed since the first attempt exceeds it.
+	// Zero means no limit other than the retry timeout of the client.
+	MaxElapsedTime time.Duration
+
+	// InitialInterval is the backoff interval before the first retry,
+	// the interval is multiplied by Multiplier after each retry until it reaches MaxInterval.
+	InitialInterval time.Duration
+	Multiplier      float64
+	MaxInterval     time.Duration
+	// Jitter randomizes each interval within [interval * (1 - Jitter), interval * (1 + Jitter)],
+	// it should be in range [0, 1].
+	Jitter float64
+
+	// RetryableHTTPCodes are the http status codes that are retried, eg. 500, 502, 503.
+	RetryableHTTPCodes []int
+	// RetryableErrorCodes are the sls error codes that are retried, eg. WriteQuotaExceed, ServerBusy.
+	RetryableErrorCodes []string
+	// RetryIdempotentWrites retries PUT and DELETE requests on network errors like GET requests.
+	// POST requests are never retried on network errors since they may have been applied by the server.
+	RetryIdempotentWrites bool
+
+	// MaxCompletedRetryCount and MaxCompletedRetryLatency limit the retries of
+	// the xxxToCompleted methods, eg. GetLogsToCompleted.
+	// Zero means using the package level MaxCompletedRetryCount and MaxCompletedRetryLatency.
+	MaxCompletedRetryCount   int
+	MaxCompletedRetryLatency time.Duration
+}
+
+// NewDefaultRetryPolicy returns a RetryPolicy that retries server errors, throttling
+// and expired requests at most 10 times, with backoff from 200ms to 10s.
+func NewDefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:     10,
+		InitialInterval: 200 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     10 * time.Second,
+		Jitter:          0.5,
+		RetryableHTTPCodes: []int{
+			http.StatusInternalServerError,
+			http.StatusBadGateway,
+			http.StatusServiceUnavailable,
+			http.StatusGatewayTimeout,
+		},
+		RetryableErrorCodes: []string{
+			WRITE_QUOTA_EXCEED,
+			SHARD_WRITE_QUOTA_EXCEED,
+			READ_QUOTA_EXCEED,
+			SHARD_READ_QUOTA_EXCEED,
+			SERVER_BUSY,
+			INTERNAL_SERVER_ERROR,
+			REQUEST_TIME_EXPIRED,
+		},
+	}
+}
+
+// newBackOff returns the backoff of a request, the first attempt is sent immediately.
+func (p *RetryPolicy) newBackOff() backoff.BackOff {
+	b := &backoff.ExponentialBackOff{
+		InitialInterval:     p.InitialInterval,
+		RandomizationFactor: p.Jitter,
+		Multiplier:          p.Multiplier,
+		MaxInterval:         p.MaxInterval,
+		MaxElapsedTime:      p.MaxElapsedTime,
+		Clock:               backoff.SystemClock,
+	}
+	if b.InitialInterval <= 0 {
+		b.InitialInterval = backoff.DefaultInitialInterval
+	}
+	if b.Multiplier < 1 {
+		b.Multiplier = 1
+	}
+	if b.MaxInterval < b.InitialInterval {
+		b.MaxInterval = b.InitialInterval
+	}
+	b.Reset()
+	switch {
+	case p.MaxAttempts == 1:
+		return &backoff.StopBackOff{}
+	case p.MaxAttempts > 1:
+		return backoff.WithMaxRetries(b, uint64(p.MaxAttempts-1))
+	}
+	return b
+}
+
+// shouldRetry reports whether a request with method that failed with err should be retried.
+func (p *RetryPolicy) shouldRetry(method string, err error) bool {
+	if err == nil {
+		return false
+	}
+	var httpCode int
+	var errorCode string
+	slsErr := serverErrorOf(err)
+	var badRespErr *BadResponseError
+	var urlErr *url.Error
+	switch {
+	case slsErr != nil:
+		httpCode, errorCode = int(slsErr.HTTPCode), slsErr.Code
+	case errors.As(err, &badRespErr):
+		httpCode = badRespErr.HTTPCode
+	case errors.As(err, &urlErr):
+		switch method {
+		case http.MethodGet, http.MethodHead:
+			return true
+		case http.MethodPut, http.MethodDelete:
+			return p.RetryIdempotentWrites
+		}
+		return false
+	default:
+		return false
+	}
+	for _, code := range p.RetryableHTTPCodes {
+		if code == httpCode {
+			return true
+		}
+	}
+	for _, code := range p.RetryableErrorCodes {
+		if code == errorCode {
+			return true
+		}
+	}
+	return false
+}
+
+// errorCheck returns the retry condition of requests with method, see retryReadErrorCheck.
+func (p *RetryPolicy) errorCheck(method string) func(ctx context.Context, err error) (bool, error) {
+	return func(ctx context.Context, err error) (bool, error) {
+		return p.shouldRetry(method, err), err
+	}
+}
+
+func (p *RetryPolicy) maxCompletedRetryCount() int {
+	if p == nil || p.MaxCompletedRetryCount <= 0 {
+		return MaxCompletedRetryCount
+	}
+	return p.MaxCompletedRetryCount
+}
+
+func (p *RetryPolicy) maxCompletedRetryLatency() time.Duration {
+	if p == nil || p.MaxCompletedRetryLatency <= 0 {
+		return MaxCompletedRetryLatency
+	}
+	return p.MaxCompletedRetryLatency
+}
@@ -0,0 +1,93 @@
+package sls
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	checkpointURL := fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore)
+	shardsURL := fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore)
+	putLogsURL := fmt.Sprintf("http://%s.%s/logstores/%s", project, endpoint, logstore)
+	serverBusy := httpmock.NewStringResponder(503, `{"errorCode":"ServerBusy","errorMessage":"server busy"}`)
+	quotaExceed := httpmock.NewStringResponder(403, `{"errorCode":"WriteQuotaExceed","errorMessage":"write quota exceed"}`)
+
+	transport := httpmock.NewMockTransport()
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+	calls := func(method, url string) int {
+		return transport.GetCallCountInfo()[method+" "+url]
+	}
+	policy := &RetryPolicy{
+		MaxAttempts:         3,
+		InitialInterval:     time.Millisecond,
+		MaxInterval:         time.Millisecond,
+		RetryableHTTPCodes:  []int{503},
+		RetryableErrorCodes: []string{WRITE_QUOTA_EXCEED},
+	}
+
+	// the client path is not retried without retry policy
+	transport.RegisterResponder("GET", checkpointURL, serverBusy.Then(serverBusy).Then(httpmock.NewStringResponder(200, `[]`)))
+	_, err := client.GetCheckpoint(project, logstore, "cg")
+	require.Error(t, err)
+	require.Equal(t, 1, calls("GET", checkpointURL))
+
+	client.SetRetryPolicy(policy)
+	_, err = client.GetCheckpoint(project, logstore, "cg")
+	require.NoError(t, err)
+	require.Equal(t, 3, calls("GET", checkpointURL))
+
+	// POST requests are not retried on network errors
+	transport.RegisterResponder("POST", checkpointURL, httpmock.NewErrorResponder(errors.New("connection reset")))
+	err = client.UpdateCheckpoint(project, logstore, "cg", "consumer", 0, "cursor", true)
+	require.Error(t, err)
+	require.Equal(t, 1, calls("POST", checkpointURL))
+
+	// the project path, WriteQuotaExceed is only retried with retry policy
+	transport.RegisterResponder("POST", putLogsURL, quotaExceed)
+	logGroup := &LogGroup{Logs: []*Log{{Time: proto.Uint32(uint32(time.Now().Unix()))}}}
+	client.SetRetryPolicy(nil)
+	err = client.PutLogs(project, logstore, logGroup)
+	require.Error(t, err)
+	require.Equal(t, 1, calls("POST", putLogsURL))
+
+	client.SetRetryPolicy(policy)
+	err = client.PutLogs(project, logstore, logGroup)
+	require.Error(t, err)
+	require.Equal(t, 1+3, calls("POST", putLogsURL))
+
+	// the project path stops retrying once MaxAttempts is reached
+	transport.RegisterResponder("GET", shardsURL, serverBusy)
+	_, err = client.ListShards(project, logstore)
+	require.Error(t, err)
+	require.Equal(t, 3, calls("GET", shardsURL))
+}
+
+func TestRetryPolicyShouldRetry(t *testing.T) {
+	networkErr := &url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("connection refused")}
+	policy := NewDefaultRetryPolicy()
+	require.True(t, policy.shouldRetry(http.MethodPost, &Error{HTTPCode: 503}))
+	require.True(t, policy.shouldRetry(http.MethodPost, &BadResponseError{HTTPCode: 502}))
+	require.True(t, policy.shouldRetry(http.MethodPost, &Error{HTTPCode: 403, Code: WRITE_QUOTA_EXCEED}))
+	require.True(t, policy.shouldRetry(http.MethodPost, &Error{HTTPCode: 400, Code: REQUEST_TIME_EXPIRED}))
+	require.False(t, policy.shouldRetry(http.MethodGet, &Error{HTTPCode: 404, Code: LOGSTORE_NOT_EXIST}))
+	require.False(t, policy.shouldRetry(http.MethodGet, errors.New("can't find 'x-log-bodyrawsize' header")))
+	require.False(t, policy.shouldRetry(http.MethodGet, nil))
+
+	require.True(t, policy.shouldRetry(http.MethodGet, networkErr))
+	require.False(t, policy.shouldRetry(http.MethodPost, networkErr))
+	require.False(t, policy.shouldRetry(http.MethodPut, networkErr))
+	policy.RetryIdempotentWrites = true
+	require.True(t, policy.shouldRetry(http.MethodPut, networkErr))
+	require.True(t, policy.shouldRetry(http.MethodDelete, networkErr))
+	require.False(t, policy.shouldRetry(http.MethodPost, networkErr))
+}
@@ -67,7 +67,7 @@ func NewSignerV1(accessKeyID, accessKeySecret string) *SignerV1 {
 }
 
 func (s *SignerV1) Sign(method, uri string, headers map[string]string, body []byte) error {
-	var contentMD5, contentType, date, canoHeaders, canoResource string
+	var contentMD5, contentType, date, canoHeaders string
 	if body != nil {
 		contentMD5 = fmt.Sprintf("%X", md5.Sum(body))
 		headers[HTTPHeaderContentMD5] = contentMD5
@@ -103,12 +103,44 @@ func (s *SignerV1) Sign(method, uri string, headers map[string]string, body []by
 	}
 
 	// Calc CanonicalizedResource
-	u, err := url.Parse(uri)
+	canoResource, err := canonicalizedResourceV1(uri)
+	if err != nil {
+		return err
+	}
+
+	signStr := method + "\n" +
+		contentMD5 + "\n" +
+		contentType + "\n" +
+		date + "\n" +
+		canoHeaders + "\n" +
+		canoResource
+
+	digest, err := s.signString(signStr)
 	if err != nil {
 		return err
 	}
+	auth := fmt.Sprintf("LOG %s:%s", s.accessKeyID, digest)
+	headers[HTTPHeaderAuthorization] = auth
+	return nil
+}
+
+// signString returns base64(hmac-sha1(UTF8-Encoding-Of(SignString)，AccessKeySecret))
+func (s *SignerV1) signString(signStr string) (string, error) {
+	mac := hmac.New(sha1.New, []byte(s.accessKeySecret))
+	if _, err := mac.Write([]byte(signStr)); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
 
-	canoResource += u.EscapedPath()
+// canonicalizedResourceV1 returns the escaped path of uri, followed by the query params sorted by key.
+func canonicalizedResourceV1(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+
+	canoResource := u.EscapedPath()
 	if u.RawQuery != "" {
 		var keys sort.StringSlice
 
@@ -129,24 +161,7 @@ func (s *SignerV1) Sign(method, uri string, headers map[string]string, body []by
 			}
 		}
 	}
-
-	signStr := method + "\n" +
-		contentMD5 + "\n" +
-		contentType + "\n" +
-		date + "\n" +
-		canoHeaders + "\n" +
-		canoResource
-
-	// Signature = base64(hmac-sha1(UTF8-Encoding-Of(SignString)，AccessKeySecret))
-	mac := hmac.New(sha1.New, []byte(s.accessKeySecret))
-	_, err = mac.Write([]byte(signStr))
-	if err != nil {
-		return err
-	}
-	digest := base64.StdEncoding.EncodeToString(mac.Sum(nil))
-	auth := fmt.Sprintf("LOG %s:%s", s.accessKeyID, digest)
-	headers[HTTPHeaderAuthorization] = auth
-	return nil
+	return canoResource, nil
 }
 
 // add commonHeaders to headers after signature if not conflict
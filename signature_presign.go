@@ -0,0 +1,210 @@
+package sls
+
+import (
+	"crypto/hmac"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query params of presigned urls.
+const (
+	PresignParamAccessKeyID   = "x-log-accesskeyid"
+	PresignParamExpires       = "x-log-expires" // unix timestamp in seconds after which the url expires
+	PresignParamSignature     = "x-log-signature"
+	PresignParamDate          = "x-log-date"       // v4 only
+	PresignParamCredential    = "x-log-credential" // v4 only, access key id followed by the scope
+	PresignParamSecurityToken = HTTPHeaderAcsSecurityToken
+)
+
+var (
+	ErrPresignedURLExpired = errors.New("sls: presigned url expired")
+	ErrSignatureMismatch   = errors.New("sls: signature mismatch")
+)
+
+// PresignURL returns uri with the signature of the request in query params, which authorizes anyone holding it
+// to send the request to project until expires elapse. Only requests without body are supported, eg. GetLogs
+// and PullLogs. Add the query param x-acs-security-token to uri when signing with sts credentials.
+//
+// The presigned url can be verified by VerifyPresignedURL.
+func (s *SignerV1) PresignURL(method, project, uri string, expires time.Duration) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	query := u.Query()
+	query.Del(PresignParamSignature)
+	query.Set(PresignParamAccessKeyID, s.accessKeyID)
+	query.Set(PresignParamExpires, strconv.FormatInt(time.Now().Add(expires).Unix(), 10))
+	u.RawQuery = query.Encode()
+	signature, err := s.presignSignature(method, project, u)
+	if err != nil {
+		return "", err
+	}
+	query.Set(PresignParamSignature, signature)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// presignSignature signs the method, project and the canonicalized resource of u,
+// the expiration takes the place of the Date header.
+func (s *SignerV1) presignSignature(method, project string, u *url.URL) (string, error) {
+	canoResource, err := canonicalizedResourceV1(u.String())
+	if err != nil {
+		return "", err
+	}
+	signStr := method + "\n" +
+		"\n" +
+		"\n" +
+		u.Query().Get(PresignParamExpires) + "\n" +
+		"x-log-project:" + project + "\n" +
+		canoResource
+	return s.signString(signStr)
+}
+
+// PresignURL is SignerV1.PresignURL signed with signature v4.
+func (s *SignerV4) PresignURL(method, project, uri string, expires time.Duration) (string, error) {
+	if s.region == "" {
+		return "", errSignerV4MissingRegion
+	}
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	dateTime := now.In(gmtLoc).Format(ISO8601)
+	query := u.Query()
+	query.Del(PresignParamSignature)
+	query.Set(PresignParamDate, dateTime)
+	query.Set(PresignParamCredential, s.accessKeyID+"/"+s.buildScope(dateTime[:8], s.region))
+	query.Set(PresignParamExpires, strconv.FormatInt(now.Add(expires).Unix(), 10))
+	u.RawQuery = query.Encode()
+	signature, err := s.presignSignature(method, project, u)
+	if err != nil {
+		return "", err
+	}
+	query.Set(PresignParamSignature, signature)
+	u.RawQuery = query.Encode()
+	return u.String(), nil
+}
+
+// presignSignature builds the canonical request of u, with the project as the only signed header
+// and the sha256 of empty payload.
+func (s *SignerV4) presignSignature(method, project string, u *url.URL) (string, error) {
+	uri, urlParams, err := s.parseUri(u.String())
+	if err != nil {
+		return "", err
+	}
+	dateTime := urlParams[PresignParamDate]
+	if len(dateTime) < 8 {
+		return "", fmt.Errorf("invalid query param %s: %s", PresignParamDate, dateTime)
+	}
+	signedHeadersStr, canonicalHeaderStr := s.buildCanonicalHeaders(map[string]string{"x-log-project": project})
+	canonReq := s.buildCanonicalRequest(method, uri, emptyStringSha256, canonicalHeaderStr, signedHeadersStr, urlParams)
+	scope := s.buildScope(dateTime[:8], s.region)
+	strToSign := s.buildSignMessage(canonReq, dateTime, scope)
+	key, err := s.buildSigningKey(s.accessKeySecret, s.region, dateTime[:8])
+	if err != nil {
+		return "", err
+	}
+	hash, err := s.hmacSha256([]byte(strToSign), key)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash), nil
+}
+
+// VerifyPresignedURL verifies uri presigned by SignerV1.PresignURL or SignerV4.PresignURL for the request
+// of method to project, secretLookup returns the access key secret of the access key id of the signer.
+//
+// It returns ErrPresignedURLExpired if the url expires, or ErrSignatureMismatch if the signature is invalid.
+func VerifyPresignedURL(method, project, uri string, secretLookup func(accessKeyID string) (string, error)) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	query := u.Query()
+	signature := query.Get(PresignParamSignature)
+	if signature == "" {
+		return fmt.Errorf("%w: missing query param %s", ErrSignatureMismatch, PresignParamSignature)
+	}
+	expires, err := strconv.ParseInt(query.Get(PresignParamExpires), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid query param %s: %w", PresignParamExpires, err)
+	}
+	if time.Now().Unix() > expires {
+		return ErrPresignedURLExpired
+	}
+	query.Del(PresignParamSignature)
+	u.RawQuery = query.Encode()
+
+	var expected string
+	if credential := query.Get(PresignParamCredential); credential != "" {
+		// accessKeyID/date/region/sls/aliyun_v4_request
+		parts := strings.Split(credential, "/")
+		if len(parts) != 5 {
+			return fmt.Errorf("invalid query param %s: %s", PresignParamCredential, credential)
+		}
+		secret, err := secretLookup(parts[0])
+		if err != nil {
+			return err
+		}
+		expected, err = NewSignerV4(parts[0], secret, parts[2]).presignSignature(method, project, u)
+		if err != nil {
+			return err
+		}
+	} else {
+		accessKeyID := query.Get(PresignParamAccessKeyID)
+		secret, err := secretLookup(accessKeyID)
+		if err != nil {
+			return err
+		}
+		expected, err = NewSignerV1(accessKeyID, secret).presignSignature(method, project, u)
+		if err != nil {
+			return err
+		}
+	}
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrSignatureMismatch
+	}
+	return nil
+}
+
+// PresignURL returns the url of the request of method to uri of project, with the signature of the credentials
+// and the auth version of the client in query params, see SignerV1.PresignURL.
+//
+//	url, err := client.PresignURL(http.MethodGet, project, "/logstores/"+logstore+"/shards/0?type=log&cursor="+cursor+"&count=100", 10*time.Minute)
+func (c *Client) PresignURL(method, project, uri string, expires time.Duration) (string, error) {
+	p := convert(c, project)
+	cred, err := p.getCredentials()
+	if err != nil {
+		return "", err
+	}
+	if cred.SecurityToken != "" {
+		u, err := url.Parse(uri)
+		if err != nil {
+			return "", err
+		}
+		query := u.Query()
+		query.Set(PresignParamSecurityToken, cred.SecurityToken)
+		u.RawQuery = query.Encode()
+		uri = u.String()
+	}
+	var signed string
+	switch p.AuthVersion {
+	case AuthV4:
+		signed, err = NewSignerV4(cred.AccessKeyID, cred.AccessKeySecret, p.Region).PresignURL(method, project, uri, expires)
+	case AuthV0:
+		return "", errors.New("presigned url is not supported by auth version v0")
+	default:
+		signed, err = NewSignerV1(cred.AccessKeyID, cred.AccessKeySecret).PresignURL(method, project, uri, expires)
+	}
+	if err != nil {
+		return "", err
+	}
+	return p.getBaseURL() + signed, nil
+}
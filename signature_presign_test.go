@@ -0,0 +1,65 @@
+package sls
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPresignURL(t *testing.T) {
+	secrets := map[string]string{"id": "secret"}
+	secretLookup := func(accessKeyID string) (string, error) {
+		if secret, ok := secrets[accessKeyID]; ok {
+			return secret, nil
+		}
+		return "", errors.New("unknown access key id")
+	}
+	uri := "/logstores/test-logstore/shards/0?type=log&cursor=MTY4&count=100"
+	signers := map[string]interface {
+		PresignURL(method, project, uri string, expires time.Duration) (string, error)
+	}{
+		"v1": NewSignerV1("id", "secret"),
+		"v4": NewSignerV4("id", "secret", "cn-hangzhou"),
+	}
+	for name, signer := range signers {
+		t.Run(name, func(t *testing.T) {
+			signed, err := signer.PresignURL(http.MethodGet, "test-project", uri, time.Minute)
+			require.NoError(t, err)
+			require.True(t, strings.HasPrefix(signed, "/logstores/test-logstore/shards/0?"))
+			require.NoError(t, VerifyPresignedURL(http.MethodGet, "test-project", signed, secretLookup))
+
+			require.ErrorIs(t, VerifyPresignedURL(http.MethodPost, "test-project", signed, secretLookup), ErrSignatureMismatch)
+			require.ErrorIs(t, VerifyPresignedURL(http.MethodGet, "other-project", signed, secretLookup), ErrSignatureMismatch)
+			tampered := strings.Replace(signed, "count=100", "count=1000", 1)
+			require.ErrorIs(t, VerifyPresignedURL(http.MethodGet, "test-project", tampered, secretLookup), ErrSignatureMismatch)
+			secrets["id"] = "rotated"
+			require.ErrorIs(t, VerifyPresignedURL(http.MethodGet, "test-project", signed, secretLookup), ErrSignatureMismatch)
+			secrets["id"] = "secret"
+
+			signed, err = signer.PresignURL(http.MethodGet, "test-project", uri, -time.Minute)
+			require.NoError(t, err)
+			require.ErrorIs(t, VerifyPresignedURL(http.MethodGet, "test-project", signed, secretLookup), ErrPresignedURLExpired)
+		})
+	}
+}
+
+func TestClientPresignURL(t *testing.T) {
+	client := CreateNormalInterfaceV2("cn-hangzhou.log.aliyuncs.com", NewStaticCredentialsProvider("id", "secret", "token")).(*Client)
+	client.SetAuthVersion(AuthV4)
+	client.SetRegion("cn-hangzhou")
+	signed, err := client.PresignURL(http.MethodGet, "test-project", "/logstores/test-logstore?type=log&query=level:%20error", time.Minute)
+	require.NoError(t, err)
+	u, err := url.Parse(signed)
+	require.NoError(t, err)
+	require.Equal(t, "test-project.cn-hangzhou.log.aliyuncs.com", u.Host)
+	require.Equal(t, "token", u.Query().Get(PresignParamSecurityToken))
+	require.Equal(t, "level: error", u.Query().Get("query"))
+	require.NoError(t, VerifyPresignedURL(http.MethodGet, "test-project", signed, func(string) (string, error) {
+		return "secret", nil
+	}))
+}
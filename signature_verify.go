@@ -0,0 +1,158 @@
+package sls
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// MaxRequestTimeSkew is the max difference between the Date or x-log-date header of a request
+// and the local time accepted by VerifyRequest.
+var MaxRequestTimeSkew = 15 * time.Minute
+
+// VerifyRequest verifies the Authorization header of r signed by SignerV1 or SignerV4, eg. in a proxy
+// accepting requests of the sls protocol. secretLookup returns the access key secret of the access key id
+// of the signer, the security token of requests signed with sts credentials is not verified.
+//
+// The body of r is read and replaced with an unread copy. The returned error is an *Error with the error code
+// the log service responds, eg. SignatureNotMatch and RequestTimeTooSkewed, which can be checked by errors.Is
+// with ErrUnauthorized if the request is unauthorized.
+//
+// Headers prefixed with x-log- or x-acs- that are added after signing, eg. the CommonHeaders of the client,
+// make the signature mismatch.
+func VerifyRequest(r *http.Request, secretLookup func(akID string) (string, error)) error {
+	auth := r.Header.Get(HTTPHeaderAuthorization)
+	if auth == "" {
+		return newVerifyError(http.StatusUnauthorized, UN_AUTHORIZED, "missing header %s", HTTPHeaderAuthorization)
+	}
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(r.Body); err != nil {
+			return newVerifyError(http.StatusBadRequest, POST_BODY_INVALID, "fail to read body: %v", err)
+		}
+		r.Body.Close()
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	// the client signs a nil body, which has no Content-MD5 header, for requests without body
+	if len(body) == 0 && r.Header.Get(HTTPHeaderContentMD5) == "" {
+		body = nil
+	}
+
+	headers := make(map[string]string, len(r.Header)+1)
+	for k, vals := range r.Header {
+		if len(vals) == 0 || strings.EqualFold(k, HTTPHeaderAuthorization) {
+			continue
+		}
+		l := strings.ToLower(k)
+		if strings.HasPrefix(l, "x-log-") || strings.HasPrefix(l, "x-acs-") {
+			headers[l] = vals[0]
+		} else {
+			headers[k] = vals[0]
+		}
+	}
+	headers[HTTPHeaderHost] = r.Host
+
+	var signer Signer
+	if strings.HasPrefix(auth, authorizationAlgorithmV4+" ") {
+		accessKeyID, region, err := parseAuthorizationV4(auth)
+		if err != nil {
+			return err
+		}
+		dateTime, ok := headers[HTTPHeaderLogDate]
+		if !ok {
+			return newVerifyError(http.StatusBadRequest, MISSING_DATE, "missing header %s", HTTPHeaderLogDate)
+		}
+		t, err := time.Parse(ISO8601, dateTime)
+		if err != nil {
+			return newVerifyError(http.StatusBadRequest, INVALID_DATE_FORMAT, "invalid header %s: %s", HTTPHeaderLogDate, dateTime)
+		}
+		if err := checkRequestTimeSkew(t); err != nil {
+			return err
+		}
+		secret, err := lookupSecret(secretLookup, accessKeyID)
+		if err != nil {
+			return err
+		}
+		signer = NewSignerV4(accessKeyID, secret, region)
+	} else if strings.HasPrefix(auth, "LOG ") {
+		accessKeyID := strings.SplitN(strings.TrimPrefix(auth, "LOG "), ":", 2)[0]
+		date := r.Header.Get(HTTPHeaderDate)
+		if date == "" {
+			return newVerifyError(http.StatusBadRequest, MISSING_DATE, "missing header %s", HTTPHeaderDate)
+		}
+		t, err := http.ParseTime(date)
+		if err != nil {
+			return newVerifyError(http.StatusBadRequest, INVALID_DATE_FORMAT, "invalid header %s: %s", HTTPHeaderDate, date)
+		}
+		if err := checkRequestTimeSkew(t); err != nil {
+			return err
+		}
+		secret, err := lookupSecret(secretLookup, accessKeyID)
+		if err != nil {
+			return err
+		}
+		signer = NewSignerV1(accessKeyID, secret)
+	} else {
+		return newVerifyError(http.StatusUnauthorized, UN_AUTHORIZED, "unknown authorization: %s", auth)
+	}
+
+	if err := signer.Sign(r.Method, r.URL.RequestURI(), headers, body); err != nil {
+		return newVerifyError(http.StatusBadRequest, BAD_REQUEST, "fail to sign request: %v", err)
+	}
+	if !hmac.Equal([]byte(headers[HTTPHeaderAuthorization]), []byte(auth)) {
+		return newVerifyError(http.StatusUnauthorized, SIGNATURE_NOT_MATCH, "signature of request not match")
+	}
+	return nil
+}
+
+func newVerifyError(httpCode int, code, format string, args ...interface{}) *Error {
+	return &Error{
+		HTTPCode: int32(httpCode),
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+// parseAuthorizationV4 parses the access key id and region from
+// "SLS4-HMAC-SHA256 Credential=accessKeyID/date/region/sls/aliyun_v4_request,Signature=signature".
+func parseAuthorizationV4(auth string) (accessKeyID, region string, err error) {
+	for _, field := range strings.Split(strings.TrimPrefix(auth, authorizationAlgorithmV4+" "), ",") {
+		credential := strings.TrimPrefix(strings.TrimSpace(field), "Credential=")
+		if credential == field {
+			continue
+		}
+		parts := strings.Split(credential, "/")
+		if len(parts) == 5 && parts[0] != "" && parts[2] != "" {
+			return parts[0], parts[2], nil
+		}
+	}
+	return "", "", newVerifyError(http.StatusUnauthorized, UN_AUTHORIZED, "invalid authorization: %s", auth)
+}
+
+func checkRequestTimeSkew(t time.Time) error {
+	skew := time.Since(t)
+	if skew < 0 {
+		skew = -skew
+	}
+	if skew > MaxRequestTimeSkew {
+		return newVerifyError(http.StatusBadRequest, REQUEST_TIME_TOO_SKEWED,
+			"request time %s is skewed from server time by %s", t.Format(time.RFC3339), skew)
+	}
+	return nil
+}
+
+func lookupSecret(secretLookup func(akID string) (string, error), accessKeyID string) (string, error) {
+	if accessKeyID == "" {
+		return "", newVerifyError(http.StatusUnauthorized, MISS_ACCESS_KEY_ID, "missing access key id")
+	}
+	secret, err := secretLookup(accessKeyID)
+	if err != nil {
+		return "", newVerifyError(http.StatusUnauthorized, UN_AUTHORIZED, "fail to look up access key %s: %v", accessKeyID, err)
+	}
+	return secret, nil
+}
@@ -0,0 +1,83 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyRequest(t *testing.T) {
+	verifyErrs := make(chan error, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := VerifyRequest(r, func(akID string) (string, error) {
+			if akID != "id" {
+				return "", errors.New("unknown access key id")
+			}
+			return "secret", nil
+		})
+		if err == nil {
+			// the body is still readable after verified
+			_, err = ioutil.ReadAll(r.Body)
+		}
+		verifyErrs <- err
+	}))
+	defer server.Close()
+	httpClient := &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return net.Dial(network, server.Listener.Addr().String())
+		},
+	}}
+
+	newClient := func(accessKeyID, accessKeySecret string, authVersion AuthVersionType) *Client {
+		client := CreateNormalInterfaceV2("http://cn-hangzhou.log.aliyuncs.com",
+			NewStaticCredentialsProvider(accessKeyID, accessKeySecret, "token")).(*Client)
+		client.HTTPClient = httpClient
+		client.SetAuthVersion(authVersion)
+		client.SetRegion("cn-hangzhou")
+		return client
+	}
+	logGroup := &LogGroup{Logs: []*Log{{
+		Time:     proto.Uint32(uint32(time.Now().Unix())),
+		Contents: []*LogContent{{Key: proto.String("key"), Value: proto.String("value")}},
+	}}}
+	for _, authVersion := range []AuthVersionType{AuthV1, AuthV4} {
+		t.Run(string(authVersion), func(t *testing.T) {
+			require.NoError(t, newClient("id", "secret", authVersion).PutLogs("test-project", "test-logstore", logGroup))
+			require.NoError(t, <-verifyErrs)
+			_, err := newClient("id", "secret", authVersion).ListLogStore("test-project")
+			require.Error(t, err) // the response is not a valid list of logstores
+			require.NoError(t, <-verifyErrs)
+
+			newClient("id", "wrong-secret", authVersion).PutLogs("test-project", "test-logstore", logGroup)
+			err = <-verifyErrs
+			require.ErrorIs(t, err, ErrUnauthorized)
+			require.Equal(t, SIGNATURE_NOT_MATCH, err.(*Error).Code)
+			newClient("unknown-id", "secret", authVersion).PutLogs("test-project", "test-logstore", logGroup)
+			require.ErrorIs(t, <-verifyErrs, ErrUnauthorized)
+		})
+	}
+
+	// the request time is checked
+	headers := map[string]string{
+		HTTPHeaderHost:    "test-project.cn-hangzhou.log.aliyuncs.com",
+		HTTPHeaderDate:    time.Now().Add(-time.Hour).In(gmtLoc).Format(time.RFC1123),
+		HTTPHeaderLogDate: time.Now().Add(-time.Hour).In(gmtLoc).Format(ISO8601),
+	}
+	for _, signer := range []Signer{NewSignerV1("id", "secret"), NewSignerV4("id", "secret", "cn-hangzhou")} {
+		require.NoError(t, signer.Sign(http.MethodGet, "/logstores", headers, nil))
+		req := httptest.NewRequest(http.MethodGet, "http://test-project.cn-hangzhou.log.aliyuncs.com/logstores", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		err := VerifyRequest(req, func(string) (string, error) { return "secret", nil })
+		require.Equal(t, REQUEST_TIME_TOO_SKEWED, err.(*Error).Code)
+	}
+}
@@ -0,0 +1,244 @@
+package slstest
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+)
+
+// RecordMode is the mode of a Recorder.
+type RecordMode int
+
+const (
+	// RecordModeReplay replays the interactions in the fixture file, without sending any request.
+	RecordModeReplay RecordMode = iota
+	// RecordModeRecord sends requests to SLS, and records the interactions into the fixture file on Save.
+	RecordModeRecord
+)
+
+const scrubbedValue = "SCRUBBED"
+
+// headers and query parameters that carry credentials, they are scrubbed before recorded
+var scrubbedKeys = []string{
+	sls.HTTPHeaderAuthorization,
+	sls.HTTPHeaderAcsSecurityToken,
+	"AccessKeyId",
+	"Signature",
+	"SecurityToken",
+}
+
+// RecordedRequest is a request in a fixture file, the credentials in it are scrubbed.
+type RecordedRequest struct {
+	Method   string            `json:"method"`
+	Host     string            `json:"host"`
+	Path     string            `json:"path"`
+	Query    string            `json:"query"`
+	BodyHash string            `json:"bodyHash"` // hex encoded sha256 of the body
+	Headers  map[string]string `json:"headers"`
+}
+
+// RecordedResponse is a response in a fixture file.
+type RecordedResponse struct {
+	StatusCode int               `json:"statusCode"`
+	Headers    map[string]string `json:"headers"`
+	Body       []byte            `json:"body"`
+}
+
+// RecordedInteraction is a request and its response in a fixture file.
+type RecordedInteraction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// Recorder is an http.RoundTripper that records SLS interactions into a fixture file,
+// and replays them for offline tests.
+//
+// Requests are matched on method, path, query and hash of body. Interactions with
+// the same request are replayed in the recorded order, and the last one is replayed
+// once all of them are used.
+//
+//	recorder, err := slstest.NewRecorder("testdata/list_shards.json", slstest.RecordModeReplay)
+//	client.SetHTTPClient(&http.Client{Transport: recorder})
+type Recorder struct {
+	// Transport sends requests in RecordModeRecord, http.DefaultTransport is used if nil.
+	Transport http.RoundTripper
+
+	mode RecordMode
+	path string
+
+	mu           sync.Mutex
+	interactions []*RecordedInteraction
+	replayed     []bool
+}
+
+// NewRecorder creates a Recorder of fixture file path, the fixture file is loaded in RecordModeReplay.
+func NewRecorder(path string, mode RecordMode) (*Recorder, error) {
+	r := &Recorder{mode: mode, path: path}
+	if mode != RecordModeReplay {
+		return r, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("invalid fixture file %s: %w", path, err)
+	}
+	r.replayed = make([]bool, len(r.interactions))
+	return r, nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	recorded := newRecordedRequest(req, body)
+	if r.mode == RecordModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	r.interactions = append(r.interactions, &RecordedInteraction{
+		Request: recorded,
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    scrubHeaders(resp.Header),
+			Body:       respBody,
+		},
+	})
+	r.mu.Unlock()
+	return resp, nil
+}
+
+func (r *Recorder) replay(req *http.Request, recorded RecordedRequest) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	matched := -1
+	for i, interaction := range r.interactions {
+		if !interaction.Request.matches(recorded) {
+			continue
+		}
+		matched = i
+		if !r.replayed[i] {
+			break
+		}
+	}
+	if matched < 0 {
+		return nil, fmt.Errorf("no recorded interaction matches %s %s?%s in %s", recorded.Method, recorded.Path, recorded.Query, r.path)
+	}
+	r.replayed[matched] = true
+
+	recordedResp := r.interactions[matched].Response
+	header := http.Header{}
+	for k, v := range recordedResp.Headers {
+		header.Set(k, v)
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recordedResp.StatusCode, http.StatusText(recordedResp.StatusCode)),
+		StatusCode:    recordedResp.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(bytes.NewReader(recordedResp.Body)),
+		ContentLength: int64(len(recordedResp.Body)),
+		Request:       req,
+	}, nil
+}
+
+// Save writes the recorded interactions into the fixture file, it does nothing in RecordModeReplay.
+func (r *Recorder) Save() error {
+	if r.mode == RecordModeReplay {
+		return nil
+	}
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, data, 0644)
+}
+
+func newRecordedRequest(req *http.Request, body []byte) RecordedRequest {
+	hash := sha256.Sum256(body)
+	return RecordedRequest{
+		Method:   req.Method,
+		Host:     req.URL.Host,
+		Path:     req.URL.Path,
+		Query:    scrubQuery(req.URL.Query()).Encode(),
+		BodyHash: hex.EncodeToString(hash[:]),
+		Headers:  scrubHeaders(req.Header),
+	}
+}
+
+func (r RecordedRequest) matches(other RecordedRequest) bool {
+	return r.Method == other.Method && r.Path == other.Path && r.Query == other.Query && r.BodyHash == other.BodyHash
+}
+
+func isScrubbedKey(key string) bool {
+	for _, k := range scrubbedKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	return false
+}
+
+func scrubHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string, len(header))
+	for k := range header {
+		if isScrubbedKey(k) {
+			headers[k] = scrubbedValue
+		} else {
+			headers[k] = header.Get(k)
+		}
+	}
+	return headers
+}
+
+func scrubQuery(query url.Values) url.Values {
+	for k := range query {
+		if isScrubbedKey(k) {
+			query.Set(k, scrubbedValue)
+		}
+	}
+	return query
+}
@@ -0,0 +1,89 @@
+package slstest
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder(t *testing.T) {
+	var checkpoints int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(sls.RequestIDHeader, "testRequestID")
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/shards"):
+			w.Write([]byte(`[{"shardID":0,"status":"readwrite"},{"shardID":1,"status":"readwrite"}]`))
+		case strings.HasSuffix(r.URL.Path, "/consumergroups/cg"):
+			if atomic.AddInt32(&checkpoints, 1) == 1 {
+				w.Write([]byte(`[]`))
+			} else {
+				w.Write([]byte(`[{"shard":0,"checkpoint":"cursor","updateTime":1,"consumer":"c"}]`))
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"errorCode":"LogStoreNotExist","errorMessage":"logstore not exist"}`))
+		}
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+	fixture := filepath.Join(t.TempDir(), "testdata", "fixture.json")
+
+	recorder, err := NewRecorder(fixture, RecordModeRecord)
+	require.NoError(t, err)
+	client := sls.CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "testSecurityToken").(*sls.Client)
+	client.SetHTTPClient(&http.Client{Transport: recorder})
+	shards, err := client.ListShards("", "testLogstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+	_, err = client.GetCheckpoint("", "testLogstore", "cg")
+	require.NoError(t, err)
+	_, err = client.GetCheckpoint("", "testLogstore", "cg")
+	require.NoError(t, err)
+	_, err = client.GetLogStore("", "notExist")
+	require.Error(t, err)
+	require.NoError(t, recorder.Save())
+
+	// credentials are scrubbed
+	data, err := ioutil.ReadFile(fixture)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "testAccessKeyId")
+	require.NotContains(t, string(data), "testSecurityToken")
+	require.Contains(t, string(data), scrubbedValue)
+
+	// replay without the server
+	server.Close()
+	recorder, err = NewRecorder(fixture, RecordModeReplay)
+	require.NoError(t, err)
+	client = sls.CreateNormalInterface(endpoint, "otherAccessKeyId", "otherAccessKeySecret", "").(*sls.Client)
+	client.SetHTTPClient(&http.Client{Transport: recorder})
+	shards, err = client.ListShards("", "testLogstore")
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+	// interactions of the same request are replayed in order
+	checkpoint, err := client.GetCheckpoint("", "testLogstore", "cg")
+	require.NoError(t, err)
+	require.Len(t, checkpoint, 0)
+	for i := 0; i < 2; i++ {
+		checkpoint, err = client.GetCheckpoint("", "testLogstore", "cg")
+		require.NoError(t, err)
+		require.Len(t, checkpoint, 1)
+	}
+	_, err = client.GetLogStore("", "notExist")
+	require.ErrorIs(t, err, sls.ErrLogStoreNotExist)
+	var slsErr *sls.Error
+	require.ErrorAs(t, err, &slsErr)
+	require.Equal(t, "testRequestID", slsErr.RequestID)
+
+	// requests not recorded fail, as network errors
+	client.SetRetryPolicy(&sls.RetryPolicy{MaxAttempts: 1})
+	_, err = client.ListShards("", "otherLogstore")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no recorded interaction")
+}
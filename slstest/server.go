@@ -0,0 +1,666 @@
+// Package slstest provides an in-process SLS emulator for tests without network.
+//
+// The emulator implements the core SLS REST surface: project and logstore CRUD, ListShards,
+// writing logs with lz4 or zstd bodies, GetCursor, PullLogs, consumer groups with checkpoints and
+// heartbeats balancing the shards among the consumers, and GetLogs of both the POST and GET apis with
+// simple keyword queries. Requests are authenticated with SignerV1 or SignerV4 against the access key
+// of the server. Requests not supported fail with a *sls.Error of code NotSupported.
+//
+//	srv := slstest.NewServer("accessKeyID", "accessKeySecret")
+//	defer srv.Close()
+//	client := srv.NewClient()
+//
+// Requests of a project are sent to the host "<project>.<endpoint>", which does not resolve
+// for a local server, so the http.Client returned by HTTPClient must be set to every client,
+// producer and consumer sending requests to the server:
+//
+//	config := producer.GetDefaultProducerConfig()
+//	config.Endpoint = srv.Endpoint()
+//	config.HTTPClient = srv.HTTPClient()
+//
+// Recorder records the interactions with a real SLS into a fixture file and replays them, for
+// offline tests of behaviors the emulator does not implement.
+package slstest
+
+import (
+	"context"
+	"crypto/hmac"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/internal/fakestore"
+	"github.com/gogo/protobuf/proto"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+var zstdCompressor = sls.NewZstdCompressor(zstd.SpeedFastest)
+
+// Server is an in-process SLS emulator listening on a local address.
+type Server struct {
+	accessKeyID     string
+	accessKeySecret string
+	server          *httptest.Server
+	host            string
+	requestID       int64
+
+	mu       sync.Mutex
+	projects map[string]*project
+}
+
+// NewServer starts a Server accepting requests signed with accessKeyID and accessKeySecret.
+func NewServer(accessKeyID, accessKeySecret string) *Server {
+	s := &Server{
+		accessKeyID:     accessKeyID,
+		accessKeySecret: accessKeySecret,
+		projects:        make(map[string]*project),
+	}
+	s.server = httptest.NewServer(s)
+	s.host = strings.TrimPrefix(s.server.URL, "http://")
+	return s
+}
+
+// Close shuts down the server.
+func (s *Server) Close() {
+	s.server.Close()
+}
+
+// Endpoint returns the endpoint of the server, eg 127.0.0.1:8080.
+func (s *Server) Endpoint() string {
+	return s.host
+}
+
+// HTTPClient returns an http.Client which sends requests of all projects to the server.
+func (s *Server) HTTPClient() *http.Client {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+				return dialer.DialContext(ctx, network, s.host)
+			},
+		},
+	}
+}
+
+// NewClient returns a client sending requests to the server with its access key.
+func (s *Server) NewClient() sls.ClientInterface {
+	client := sls.CreateNormalInterfaceV2(s.Endpoint(), sls.NewStaticCredentialsProvider(s.accessKeyID, s.accessKeySecret, ""))
+	client.SetHTTPClient(s.HTTPClient())
+	return client
+}
+
+// ServeHTTP implements http.Handler.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	requestID := fmt.Sprintf("%016X", atomic.AddInt64(&s.requestID, 1))
+	w.Header().Set(sls.RequestIDHeader, requestID)
+
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, newError(http.StatusBadRequest, sls.POST_BODY_INVALID, "fail to read body: %v", err))
+		return
+	}
+	if slsErr := s.authenticate(r, body); slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	projectName := ""
+	if strings.HasSuffix(r.Host, "."+s.host) {
+		projectName = strings.TrimSuffix(r.Host, "."+s.host)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if projectName == "" {
+		if r.URL.Path == "/" && r.Method == http.MethodGet {
+			s.listProject(w, r)
+			return
+		}
+		writeError(w, newError(http.StatusBadRequest, sls.MISSING_HOST, "project is not specified in host %s", r.Host))
+		return
+	}
+	if r.URL.Path == "/" {
+		s.handleProject(w, r, projectName, body)
+		return
+	}
+	p, ok := s.projects[projectName]
+	if !ok {
+		writeError(w, newError(http.StatusNotFound, sls.PROJECT_NOT_EXIST, "project %s does not exist", projectName))
+		return
+	}
+	s.handleLogstore(w, r, p, body)
+}
+
+// authenticate signs the request again with the access key of the server, and compares the signatures.
+func (s *Server) authenticate(r *http.Request, body []byte) *sls.Error {
+	auth := r.Header.Get(sls.HTTPHeaderAuthorization)
+	headers := make(map[string]string)
+	for k := range r.Header {
+		key := strings.ToLower(k)
+		if strings.HasPrefix(key, "x-log-") || strings.HasPrefix(key, "x-acs-") {
+			headers[key] = r.Header.Get(k)
+		}
+	}
+	if contentType := r.Header.Get(sls.HTTPHeaderContentType); contentType != "" {
+		headers[sls.HTTPHeaderContentType] = contentType
+	}
+
+	var signer sls.Signer
+	switch {
+	case strings.HasPrefix(auth, "LOG "):
+		headers[sls.HTTPHeaderDate] = r.Header.Get(sls.HTTPHeaderDate)
+		// the content md5 is only signed with a body
+		if r.Header.Get(sls.HTTPHeaderContentMD5) == "" {
+			body = nil
+		} else if body == nil {
+			body = []byte{}
+		}
+		signer = sls.NewSignerV1(s.accessKeyID, s.accessKeySecret)
+	case strings.HasPrefix(auth, "SLS4-HMAC-SHA256 "):
+		// Credential=accessKeyID/date/region/sls/aliyun_v4_request
+		scope := strings.Split(strings.TrimPrefix(strings.SplitN(auth, ",", 2)[0], "SLS4-HMAC-SHA256 Credential="), "/")
+		if len(scope) != 5 {
+			return newError(http.StatusUnauthorized, sls.UN_AUTHORIZED, "invalid authorization %s", auth)
+		}
+		headers[sls.HTTPHeaderHost] = r.Host
+		signer = sls.NewSignerV4(s.accessKeyID, s.accessKeySecret, scope[2])
+	default:
+		return newError(http.StatusUnauthorized, sls.UN_AUTHORIZED, "missing or unsupported authorization")
+	}
+	if err := signer.Sign(r.Method, r.URL.RequestURI(), headers, body); err != nil {
+		return newError(http.StatusUnauthorized, sls.UN_AUTHORIZED, "fail to sign request: %v", err)
+	}
+	if !hmac.Equal([]byte(headers[sls.HTTPHeaderAuthorization]), []byte(auth)) {
+		return newError(http.StatusUnauthorized, sls.SIGNATURE_NOT_MATCH, "signature of request does not match")
+	}
+	return nil
+}
+
+func (s *Server) listProject(w http.ResponseWriter, r *http.Request) {
+	type projectInfo struct {
+		Name        string `json:"projectName"`
+		Description string `json:"description"`
+		Status      string `json:"status"`
+	}
+	projects := []projectInfo{}
+	for _, p := range s.projects {
+		projects = append(projects, projectInfo{Name: p.name, Description: p.description, Status: "Normal"})
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	offset, size := pageOf(r, len(projects))
+	writeJSON(w, map[string]interface{}{
+		"projects": projects[offset : offset+size],
+		"count":    size,
+		"total":    len(projects),
+	})
+}
+
+func (s *Server) handleProject(w http.ResponseWriter, r *http.Request, name string, body []byte) {
+	p, exist := s.projects[name]
+	if !exist && r.Method != http.MethodPost {
+		writeError(w, newError(http.StatusNotFound, sls.PROJECT_NOT_EXIST, "project %s does not exist", name))
+		return
+	}
+	now := time.Now().Unix()
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, &sls.LogProject{
+			Name:               p.name,
+			Description:        p.description,
+			Status:             "Normal",
+			CreateTime:         strconv.FormatInt(p.createTime, 10),
+			LastModifyTime:     strconv.FormatInt(p.lastModifyTime, 10),
+			DataRedundancyType: p.dataRedundancyType,
+		})
+	case http.MethodPost:
+		if exist {
+			writeError(w, newError(http.StatusBadRequest, sls.PROJECT_ALREADY_EXIST, "project %s already exists", name))
+			return
+		}
+		var req struct {
+			Description        string `json:"description"`
+			DataRedundancyType string `json:"dataRedundancyType"`
+		}
+		if !readJSON(w, body, &req) {
+			return
+		}
+		s.projects[name] = &project{
+			name:               name,
+			description:        req.Description,
+			dataRedundancyType: req.DataRedundancyType,
+			createTime:         now,
+			lastModifyTime:     now,
+			logstores:          make(map[string]*logstore),
+		}
+	case http.MethodPut:
+		var req struct {
+			Description string `json:"description"`
+		}
+		if !readJSON(w, body, &req) {
+			return
+		}
+		p.description = req.Description
+		p.lastModifyTime = now
+	case http.MethodDelete:
+		delete(s.projects, name)
+	default:
+		writeNotSupported(w, r)
+	}
+}
+
+func (s *Server) handleLogstore(w http.ResponseWriter, r *http.Request, p *project, body []byte) {
+	// /logstores/{logstore}/{resource}/{name}
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "logstores" || len(parts) > 4 {
+		writeNotSupported(w, r)
+		return
+	}
+	if len(parts) == 1 {
+		switch r.Method {
+		case http.MethodGet:
+			s.listLogstore(w, r, p)
+		case http.MethodPost:
+			s.createLogstore(w, p, body)
+		default:
+			writeNotSupported(w, r)
+		}
+		return
+	}
+	ls, slsErr := p.getLogstore(parts[1])
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	if len(parts) == 2 {
+		switch r.Method {
+		case http.MethodGet:
+			if r.URL.Query().Get("type") == "log" {
+				s.getLogsByURL(w, r, ls)
+				return
+			}
+			writeJSON(w, &ls.meta)
+		case http.MethodPut:
+			s.updateLogstore(w, ls, body)
+		case http.MethodDelete:
+			delete(p.logstores, ls.meta.Name)
+		case http.MethodPost:
+			s.postLogs(w, r, ls, "", body)
+		default:
+			writeNotSupported(w, r)
+		}
+		return
+	}
+	switch {
+	case parts[2] == "logs" && len(parts) == 3 && r.Method == http.MethodPost:
+		s.getLogs(w, ls, body)
+	case parts[2] == "shards" && len(parts) == 3 && r.Method == http.MethodGet:
+		s.listShards(w, ls)
+	case parts[2] == "shards" && len(parts) == 4 && parts[3] == "route" && r.Method == http.MethodPost:
+		s.postLogs(w, r, ls, r.URL.Query().Get("key"), body)
+	case parts[2] == "shards" && len(parts) == 4 && r.Method == http.MethodGet:
+		s.handleShard(w, r, ls, parts[3])
+	case parts[2] == "consumergroups" && len(parts) == 3:
+		s.handleConsumerGroups(w, r, ls, body)
+	case parts[2] == "consumergroups" && len(parts) == 4:
+		s.handleConsumerGroup(w, r, ls, parts[3], body)
+	default:
+		writeNotSupported(w, r)
+	}
+}
+
+func (s *Server) listLogstore(w http.ResponseWriter, r *http.Request, p *project) {
+	names := []string{}
+	for name := range p.logstores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	offset, size := pageOf(r, len(names))
+	writeJSON(w, map[string]interface{}{
+		"count":     size,
+		"total":     len(names),
+		"logstores": names[offset : offset+size],
+	})
+}
+
+func (s *Server) createLogstore(w http.ResponseWriter, p *project, body []byte) {
+	var meta sls.LogStore
+	if !readJSON(w, body, &meta) {
+		return
+	}
+	if meta.Name == "" || meta.ShardCount <= 0 || meta.TTL <= 0 {
+		writeError(w, newError(http.StatusBadRequest, sls.PARAMETER_INVALID, "logstore name, ttl and shard count are required"))
+		return
+	}
+	if _, ok := p.logstores[meta.Name]; ok {
+		writeError(w, newError(http.StatusBadRequest, sls.LOGSTORE_ALREADY_EXIST, "logstore %s already exists", meta.Name))
+		return
+	}
+	p.logstores[meta.Name] = newLogstore(meta)
+}
+
+func (s *Server) updateLogstore(w http.ResponseWriter, ls *logstore, body []byte) {
+	var meta sls.LogStore
+	if !readJSON(w, body, &meta) {
+		return
+	}
+	// shards are not changed by updating logstore
+	meta.ShardCount = ls.meta.ShardCount
+	meta.Name = ls.meta.Name
+	meta.CreateTime = ls.meta.CreateTime
+	meta.LastModifyTime = uint32(time.Now().Unix())
+	ls.meta = meta
+}
+
+func (s *Server) listShards(w http.ResponseWriter, ls *logstore) {
+	shards := make([]*sls.Shard, 0, len(ls.shards))
+	for _, shard := range ls.shards {
+		shards = append(shards, &sls.Shard{
+			ShardID:           shard.id,
+			Status:            "readwrite",
+			InclusiveBeginKey: shard.beginKey,
+			ExclusiveBeginKey: shard.endKey,
+			CreateTime:        int(shard.createTime),
+		})
+	}
+	writeJSON(w, shards)
+}
+
+func (s *Server) postLogs(w http.ResponseWriter, r *http.Request, ls *logstore, key string, body []byte) {
+	rawSize, err := strconv.Atoi(r.Header.Get(sls.HTTPHeaderBodyRawSize))
+	if err != nil {
+		writeError(w, newError(http.StatusBadRequest, sls.INVALID_BODY_RAW_SIZE, "invalid x-log-bodyrawsize"))
+		return
+	}
+	raw := body
+	switch compressType := r.Header.Get("x-log-compresstype"); compressType {
+	case "":
+	case "lz4":
+		raw = make([]byte, rawSize)
+		n, err := lz4.UncompressBlock(body, raw)
+		if err != nil || n != rawSize {
+			writeError(w, newError(http.StatusBadRequest, sls.POST_BODY_UNCOMPRESS_ERROR, "fail to uncompress lz4 body"))
+			return
+		}
+	case "zstd":
+		raw, err = zstdCompressor.Decompress(body, make([]byte, 0, rawSize))
+		if err != nil || len(raw) != rawSize {
+			writeError(w, newError(http.StatusBadRequest, sls.POST_BODY_UNCOMPRESS_ERROR, "fail to uncompress zstd body"))
+			return
+		}
+	default:
+		writeError(w, newError(http.StatusBadRequest, sls.INVALID_COMPRESS_TYPE, "unsupported compress type %s", compressType))
+		return
+	}
+	logGroup := &sls.LogGroup{}
+	if err := proto.Unmarshal(raw, logGroup); err != nil {
+		writeError(w, newError(http.StatusBadRequest, sls.POST_BODY_INVALID, "invalid log group: %v", err))
+		return
+	}
+	shard, slsErr := ls.routeShard(key)
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	shard.groups = append(shard.groups, storedLogGroup{receiveTime: time.Now().Unix(), logGroup: logGroup})
+}
+
+func (s *Server) handleShard(w http.ResponseWriter, r *http.Request, ls *logstore, id string) {
+	shardID, err := strconv.Atoi(id)
+	if err != nil {
+		writeError(w, newError(http.StatusBadRequest, sls.SHARD_NOT_EXIST, "shard %s does not exist", id))
+		return
+	}
+	shard, slsErr := ls.getShard(shardID)
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	query := r.URL.Query()
+	switch query.Get("type") {
+	case "cursor":
+		cursor, slsErr := shard.cursorOf(query.Get("from"))
+		if slsErr != nil {
+			writeError(w, slsErr)
+			return
+		}
+		writeJSON(w, map[string]string{"cursor": cursor})
+	case "logs":
+		s.pullLogs(w, r, shard)
+	default:
+		writeNotSupported(w, r)
+	}
+}
+
+func (s *Server) pullLogs(w http.ResponseWriter, r *http.Request, shard *shard) {
+	query := r.URL.Query()
+	if query.Get("query") != "" {
+		writeError(w, newError(http.StatusBadRequest, sls.NOT_SUPPORTED, "pulling logs with query is not supported"))
+		return
+	}
+	begin, slsErr := shard.decodeCursor(query.Get("cursor"))
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	end := len(shard.groups)
+	if endCursor := query.Get("end_cursor"); endCursor != "" {
+		if end, slsErr = shard.decodeCursor(endCursor); slsErr != nil {
+			writeError(w, slsErr)
+			return
+		}
+	}
+	count, err := strconv.Atoi(query.Get("count"))
+	if err != nil || count <= 0 {
+		writeError(w, newError(http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid count %s", query.Get("count")))
+		return
+	}
+	if end > begin+count {
+		end = begin + count
+	}
+	if end < begin {
+		end = begin
+	}
+	logGroupList := &sls.LogGroupList{}
+	for i := begin; i < end; i++ {
+		logGroupList.LogGroups = append(logGroupList.LogGroups, shard.groups[i].logGroup)
+	}
+
+	raw, err := proto.Marshal(logGroupList)
+	if err != nil {
+		writeError(w, newError(http.StatusInternalServerError, sls.INTERNAL_SERVER_ERROR, "fail to marshal logs: %v", err))
+		return
+	}
+	compressType := "lz4"
+	if r.Header.Get("Accept-Encoding") == "zstd" {
+		compressType = "zstd"
+	}
+	out, err := compress(compressType, raw)
+	if err != nil {
+		writeError(w, newError(http.StatusInternalServerError, sls.INTERNAL_SERVER_ERROR, "fail to compress logs: %v", err))
+		return
+	}
+	h := w.Header()
+	h.Set("X-Log-Cursor", fakestore.EncodeCursor(end))
+	h.Set("X-Log-Count", strconv.Itoa(len(logGroupList.LogGroups)))
+	h.Set("X-Log-Bodyrawsize", strconv.Itoa(len(raw)))
+	h.Set("X-Log-Compresstype", compressType)
+	h.Set("Content-Type", "application/x-protobuf")
+	w.Write(out)
+}
+
+func (s *Server) getLogs(w http.ResponseWriter, ls *logstore, body []byte) {
+	var req sls.GetLogRequest
+	if !readJSON(w, body, &req) {
+		return
+	}
+	logs, slsErr := ls.queryLogs(&req)
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	writeJSON(w, &sls.GetLogsV3Response{
+		Meta: sls.GetLogsV3ResponseMeta{
+			Progress: "Complete",
+			Count:    int64(len(logs)),
+		},
+		Logs: logs,
+	})
+}
+
+// getLogsByURL serves GetLogs of the GET api, whose request is in the url, and the meta of the response
+// is in the headers.
+func (s *Server) getLogsByURL(w http.ResponseWriter, r *http.Request, ls *logstore) {
+	query := r.URL.Query()
+	req := sls.GetLogRequest{
+		Topic:   query.Get("topic"),
+		Query:   query.Get("query"),
+		Reverse: query.Get("reverse") == "true",
+	}
+	for _, param := range []struct {
+		name  string
+		value *int64
+	}{{"from", &req.From}, {"to", &req.To}, {"line", &req.Lines}, {"offset", &req.Offset}} {
+		v := query.Get(param.name)
+		if v == "" {
+			continue
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			writeError(w, newError(http.StatusBadRequest, sls.PARAMETER_INVALID, "invalid %s %s", param.name, v))
+			return
+		}
+		*param.value = n
+	}
+	logs, slsErr := ls.queryLogs(&req)
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	h := w.Header()
+	h.Set(sls.ProgressHeader, "Complete")
+	h.Set(sls.GetLogsCountHeader, strconv.Itoa(len(logs)))
+	h.Set(sls.HasSQLHeader, "false")
+	writeJSON(w, logs)
+}
+
+func (s *Server) handleConsumerGroups(w http.ResponseWriter, r *http.Request, ls *logstore, body []byte) {
+	switch r.Method {
+	case http.MethodGet:
+		type consumerGroupInfo struct {
+			Name    string `json:"name"`
+			Timeout int    `json:"timeout"`
+			InOrder bool   `json:"order"`
+		}
+		names := []string{}
+		for name := range ls.consumerGroups {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		groups := []consumerGroupInfo{}
+		for _, name := range names {
+			cg := ls.consumerGroups[name]
+			groups = append(groups, consumerGroupInfo{Name: name, Timeout: cg.meta.Timeout, InOrder: cg.meta.InOrder})
+		}
+		writeJSON(w, groups)
+	case http.MethodPost:
+		var meta sls.ConsumerGroup
+		if !readJSON(w, body, &meta) {
+			return
+		}
+		if meta.ConsumerGroupName == "" || meta.Timeout <= 0 {
+			writeError(w, newError(http.StatusBadRequest, sls.PARAMETER_INVALID, "consumer group name and timeout are required"))
+			return
+		}
+		if _, ok := ls.consumerGroups[meta.ConsumerGroupName]; ok {
+			writeError(w, newError(http.StatusBadRequest, sls.CONSUMER_GROUP_ALREADY_EXIST, "consumer group %s already exists", meta.ConsumerGroupName))
+			return
+		}
+		ls.consumerGroups[meta.ConsumerGroupName] = &consumerGroup{
+			meta:        meta,
+			checkpoints: make(map[int]*sls.ConsumerGroupCheckPoint),
+			assignment:  fakestore.NewAssignment(),
+		}
+	default:
+		writeNotSupported(w, r)
+	}
+}
+
+func (s *Server) handleConsumerGroup(w http.ResponseWriter, r *http.Request, ls *logstore, name string, body []byte) {
+	cg, slsErr := ls.getConsumerGroup(name)
+	if slsErr != nil {
+		writeError(w, slsErr)
+		return
+	}
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodGet:
+		checkpoints := []*sls.ConsumerGroupCheckPoint{}
+		for _, shard := range ls.shards {
+			if checkpoint, ok := cg.checkpoints[shard.id]; ok {
+				checkpoints = append(checkpoints, checkpoint)
+			}
+		}
+		writeJSON(w, checkpoints)
+	case r.Method == http.MethodPut:
+		var req struct {
+			InOrder *bool `json:"order"`
+			Timeout *int  `json:"timeout"`
+		}
+		if !readJSON(w, body, &req) {
+			return
+		}
+		if req.InOrder != nil {
+			cg.meta.InOrder = *req.InOrder
+		}
+		if req.Timeout != nil {
+			cg.meta.Timeout = *req.Timeout
+		}
+	case r.Method == http.MethodDelete:
+		delete(ls.consumerGroups, name)
+	case r.Method == http.MethodPost && query.Get("type") == "heartbeat":
+		var shards []int
+		if !readJSON(w, body, &shards) {
+			return
+		}
+		writeJSON(w, cg.heartbeat(query.Get("consumer"), len(ls.shards)))
+	case r.Method == http.MethodPost && query.Get("type") == "checkpoint":
+		var req struct {
+			Shard      int    `json:"shard"`
+			Checkpoint string `json:"checkpoint"`
+		}
+		if !readJSON(w, body, &req) {
+			return
+		}
+		shard, slsErr := ls.getShard(req.Shard)
+		if slsErr != nil {
+			writeError(w, slsErr)
+			return
+		}
+		if _, slsErr = shard.decodeCursor(req.Checkpoint); slsErr != nil {
+			writeError(w, slsErr)
+			return
+		}
+		consumer := query.Get("consumer")
+		if owner, ok := cg.assignment.Owner(req.Shard); ok && owner != consumer && query.Get("forceSuccess") != "true" {
+			writeError(w, newError(http.StatusBadRequest, sls.CONSUMER_NOT_MATCH, "shard %d is held by consumer %s", req.Shard, owner))
+			return
+		}
+		cg.checkpoints[req.Shard] = &sls.ConsumerGroupCheckPoint{
+			ShardID:    req.Shard,
+			CheckPoint: req.Checkpoint,
+			UpdateTime: time.Now().UnixNano() / int64(time.Microsecond),
+			Consumer:   consumer,
+		}
+	default:
+		writeNotSupported(w, r)
+	}
+}
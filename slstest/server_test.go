@@ -0,0 +1,238 @@
+package slstest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	consumerLibrary "github.com/aliyun/aliyun-log-go-sdk/consumer"
+	"github.com/aliyun/aliyun-log-go-sdk/producer"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAccessKeyID     = "testAccessKeyId"
+	testAccessKeySecret = "testAccessKeySecret"
+	testProject         = "test-project"
+	testLogstore        = "test-logstore"
+)
+
+func newTestServer(t *testing.T) (*Server, sls.ClientInterface) {
+	srv := NewServer(testAccessKeyID, testAccessKeySecret)
+	t.Cleanup(srv.Close)
+	client := srv.NewClient()
+	_, err := client.CreateProject(testProject, "test")
+	require.NoError(t, err)
+	require.NoError(t, client.CreateLogStore(testProject, testLogstore, 1, 2, false, 0))
+	return srv, client
+}
+
+func TestServerMeta(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	project, err := client.GetProject(testProject)
+	require.NoError(t, err)
+	require.Equal(t, "test", project.Description)
+	projects, err := client.ListProject()
+	require.NoError(t, err)
+	require.Equal(t, []string{testProject}, projects)
+	logstores, err := client.ListLogStore(testProject)
+	require.NoError(t, err)
+	require.Equal(t, []string{testLogstore}, logstores)
+	logstore, err := client.GetLogStore(testProject, testLogstore)
+	require.NoError(t, err)
+	require.Equal(t, 2, logstore.ShardCount)
+	shards, err := client.ListShards(testProject, testLogstore)
+	require.NoError(t, err)
+	require.Len(t, shards, 2)
+	require.Equal(t, "00000000000000000000000000000000", shards[0].InclusiveBeginKey)
+	require.Equal(t, "80000000000000000000000000000000", shards[1].InclusiveBeginKey)
+
+	err = client.CreateLogStore(testProject, testLogstore, 1, 2, false, 0)
+	require.ErrorIs(t, err, sls.ErrLogStoreAlreadyExist)
+	_, err = client.GetLogStore(testProject, "notExist")
+	require.ErrorIs(t, err, sls.ErrLogStoreNotExist)
+	_, err = client.ListShards("not-exist", testLogstore)
+	require.ErrorIs(t, err, sls.ErrProjectNotExist)
+	require.NoError(t, client.DeleteLogStore(testProject, testLogstore))
+	_, err = client.GetLogStore(testProject, testLogstore)
+	require.ErrorIs(t, err, sls.ErrLogStoreNotExist)
+
+	// signature v4
+	client = srv.NewClient()
+	client.SetAuthVersion(sls.AuthV4)
+	client.SetRegion("cn-hangzhou")
+	_, err = client.GetProject(testProject)
+	require.NoError(t, err)
+
+	// wrong access key
+	client = sls.CreateNormalInterfaceV2(srv.Endpoint(), sls.NewStaticCredentialsProvider(testAccessKeyID, "wrongSecret", ""))
+	client.SetHTTPClient(srv.HTTPClient())
+	_, err = client.GetProject(testProject)
+	require.ErrorIs(t, err, sls.ErrUnauthorized)
+}
+
+func TestServerLogs(t *testing.T) {
+	srv, client := newTestServer(t)
+	now := uint32(time.Now().Unix())
+	topic := "topic"
+	for i, compressType := range []int{sls.Compress_LZ4, sls.Compress_ZSTD, sls.Compress_None} {
+		hashKey := "00000000000000000000000000000000"
+		err := client.PostLogStoreLogsV2(testProject, testLogstore, &sls.PostLogStoreLogsRequest{
+			LogGroup: &sls.LogGroup{
+				Topic: &topic,
+				Logs: []*sls.Log{
+					producer.GenerateLog(now, map[string]string{"level": "info", "message": fmt.Sprintf("hello %d", i)}),
+					producer.GenerateLog(now, map[string]string{"level": "error", "message": fmt.Sprintf("world %d", i)}),
+				},
+			},
+			HashKey:      &hashKey,
+			CompressType: compressType,
+		})
+		require.NoError(t, err)
+	}
+
+	for _, compressType := range []int{sls.Compress_LZ4, sls.Compress_ZSTD} {
+		cursor, err := client.GetCursor(testProject, testLogstore, 0, "begin")
+		require.NoError(t, err)
+		gl, meta, err := client.PullLogsWithQuery(&sls.PullLogRequest{
+			Project:          testProject,
+			Logstore:         testLogstore,
+			ShardID:          0,
+			Cursor:           cursor,
+			LogGroupMaxCount: 2,
+			CompressType:     compressType,
+		})
+		require.NoError(t, err)
+		require.Len(t, gl.LogGroups, 2)
+		require.Equal(t, 2, meta.Count)
+		gl, _, err = client.PullLogs(testProject, testLogstore, 0, meta.NextCursor, "", 10)
+		require.NoError(t, err)
+		require.Len(t, gl.LogGroups, 1)
+		require.Contains(t, gl.LogGroups[0].Logs[0].String(), "hello 2")
+	}
+	cursor, err := client.GetCursor(testProject, testLogstore, 1, "end")
+	require.NoError(t, err)
+	gl, nextCursor, err := client.PullLogs(testProject, testLogstore, 1, cursor, "", 10)
+	require.NoError(t, err)
+	require.Len(t, gl.LogGroups, 0)
+	require.Equal(t, cursor, nextCursor)
+	_, _, err = client.PullLogs(testProject, testLogstore, 0, "invalid", "", 10)
+	require.ErrorIs(t, err, sls.ErrInvalidCursor)
+
+	resp, err := client.GetLogs(testProject, testLogstore, "", int64(now), int64(now)+1, "level: error", 100, 0, false)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), resp.Count)
+	resp, err = client.GetLogs(testProject, testLogstore, "", int64(now), int64(now)+1, "hello and 1", 100, 0, false)
+	require.NoError(t, err)
+	require.Len(t, resp.Logs, 1)
+	require.Equal(t, "hello 1", resp.Logs[0]["message"])
+
+	// GetLogs of the GET api
+	req := &sls.GetLogRequest{From: int64(now), To: int64(now) + 1, Query: "level: error", Lines: 2}
+	uri := fmt.Sprintf("/logstores/%s?%s", testLogstore, req.ToURLParams().Encode())
+	headers := map[string]string{
+		sls.HTTPHeaderDate:       time.Now().UTC().Format(http.TimeFormat),
+		sls.HTTPHeaderAPIVersion: "0.6.0",
+	}
+	require.NoError(t, sls.NewSignerV1(testAccessKeyID, testAccessKeySecret).Sign(http.MethodGet, uri, headers, nil))
+	httpReq, err := http.NewRequest(http.MethodGet, fmt.Sprintf("http://%s.%s%s", testProject, srv.Endpoint(), uri), nil)
+	require.NoError(t, err)
+	for k, v := range headers {
+		httpReq.Header.Set(k, v)
+	}
+	httpResp, err := srv.HTTPClient().Do(httpReq)
+	require.NoError(t, err)
+	defer httpResp.Body.Close()
+	require.Equal(t, http.StatusOK, httpResp.StatusCode)
+	require.Equal(t, "2", httpResp.Header.Get(sls.GetLogsCountHeader))
+	var logs []map[string]string
+	require.NoError(t, json.NewDecoder(httpResp.Body).Decode(&logs))
+	require.Len(t, logs, 2)
+	require.Equal(t, "error", logs[0]["level"])
+}
+
+func TestServerHeartbeatRebalance(t *testing.T) {
+	_, client := newTestServer(t)
+	require.NoError(t, client.CreateConsumerGroup(testProject, testLogstore, sls.ConsumerGroup{ConsumerGroupName: "cg", Timeout: 60}))
+
+	shards, err := client.HeartBeat(testProject, testLogstore, "cg", "consumer-a", nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1}, shards)
+	// consumer-b joins, consumer-a releases a shard to it
+	shards, err = client.HeartBeat(testProject, testLogstore, "cg", "consumer-b", nil)
+	require.NoError(t, err)
+	require.Empty(t, shards)
+	shards, err = client.HeartBeat(testProject, testLogstore, "cg", "consumer-a", []int{0, 1})
+	require.NoError(t, err)
+	require.Equal(t, []int{0}, shards)
+	shards, err = client.HeartBeat(testProject, testLogstore, "cg", "consumer-b", nil)
+	require.NoError(t, err)
+	require.Equal(t, []int{1}, shards)
+}
+
+func TestServerProducerConsumer(t *testing.T) {
+	srv, client := newTestServer(t)
+
+	config := producer.GetDefaultProducerConfig()
+	config.Endpoint = srv.Endpoint()
+	config.CredentialsProvider = sls.NewStaticCredentialsProvider(testAccessKeyID, testAccessKeySecret, "")
+	config.HTTPClient = srv.HTTPClient()
+	config.LingerMs = 100
+	config.CompressType = sls.Compress_ZSTD
+	p, err := producer.NewProducer(config)
+	require.NoError(t, err)
+	p.Start()
+	const count = 100
+	for i := 0; i < count; i++ {
+		log := producer.GenerateLog(uint32(time.Now().Unix()), map[string]string{"index": fmt.Sprint(i)})
+		hashKey := []string{"00000000000000000000000000000000", "80000000000000000000000000000000"}[i%2]
+		require.NoError(t, p.HashSendLog(testProject, testLogstore, hashKey, "topic", "127.0.0.1", log))
+	}
+	p.SafeClose()
+
+	var mu sync.Mutex
+	received := make(map[string]bool)
+	worker := consumerLibrary.InitConsumerWorkerWithCheckpointTracker(consumerLibrary.LogHubConfig{
+		Endpoint:                  srv.Endpoint(),
+		CredentialsProvider:       sls.NewStaticCredentialsProvider(testAccessKeyID, testAccessKeySecret, ""),
+		HTTPClient:                srv.HTTPClient(),
+		Project:                   testProject,
+		Logstore:                  testLogstore,
+		ConsumerGroupName:         "test-consumer-group",
+		ConsumerName:              "test-consumer",
+		CursorPosition:            consumerLibrary.BEGIN_CURSOR,
+		HeartbeatIntervalInSecond: 1,
+		DataFetchIntervalInMs:     100,
+		AutoCommitIntervalInMS:    100,
+	}, func(shardID int, gl *sls.LogGroupList, tracker consumerLibrary.CheckPointTracker) (string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, lg := range gl.LogGroups {
+			for _, log := range lg.Logs {
+				received[log.Contents[0].GetValue()] = true
+			}
+		}
+		return "", tracker.SaveCheckPoint(false)
+	})
+	worker.Start()
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == count
+	}, 10*time.Second, 100*time.Millisecond)
+	worker.StopAndWait()
+
+	checkpoints, err := client.GetCheckpoint(testProject, testLogstore, "test-consumer-group")
+	require.NoError(t, err)
+	require.Len(t, checkpoints, 2)
+	for _, checkpoint := range checkpoints {
+		cursor, err := client.GetCursor(testProject, testLogstore, checkpoint.ShardID, "end")
+		require.NoError(t, err)
+		require.Equal(t, cursor, checkpoint.CheckPoint)
+	}
+}
@@ -0,0 +1,184 @@
+package slstest
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/aliyun/aliyun-log-go-sdk/internal/fakestore"
+)
+
+func newError(httpCode int, code, format string, args ...interface{}) *sls.Error {
+	return &sls.Error{
+		HTTPCode: int32(httpCode),
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	}
+}
+
+type project struct {
+	name               string
+	description        string
+	dataRedundancyType string
+	createTime         int64
+	lastModifyTime     int64
+	logstores          map[string]*logstore
+}
+
+type logstore struct {
+	meta           sls.LogStore
+	shards         []*shard
+	consumerGroups map[string]*consumerGroup
+	nextShard      int // shard to write to next if no hash key is given
+}
+
+type shard struct {
+	id         int
+	beginKey   string
+	endKey     string
+	createTime int64
+	groups     []storedLogGroup
+}
+
+type storedLogGroup struct {
+	receiveTime int64
+	logGroup    *sls.LogGroup
+}
+
+type consumerGroup struct {
+	meta        sls.ConsumerGroup
+	checkpoints map[int]*sls.ConsumerGroupCheckPoint
+	assignment  *fakestore.Assignment
+}
+
+func (p *project) getLogstore(name string) (*logstore, *sls.Error) {
+	ls, ok := p.logstores[name]
+	if !ok {
+		return nil, newError(http.StatusNotFound, sls.LOGSTORE_NOT_EXIST, "logstore %s does not exist", name)
+	}
+	return ls, nil
+}
+
+func newLogstore(meta sls.LogStore) *logstore {
+	now := time.Now().Unix()
+	meta.CreateTime = uint32(now)
+	meta.LastModifyTime = uint32(now)
+	ls := &logstore{
+		meta:           meta,
+		consumerGroups: make(map[string]*consumerGroup),
+	}
+	bounds := fakestore.SplitHashKeyRange(strings.Repeat("0", 32), fakestore.MaxHashKey, meta.ShardCount)
+	for i := 0; i < meta.ShardCount; i++ {
+		ls.shards = append(ls.shards, &shard{
+			id:         i,
+			beginKey:   bounds[i],
+			endKey:     bounds[i+1],
+			createTime: now,
+		})
+	}
+	return ls
+}
+
+func (ls *logstore) getShard(id int) (*shard, *sls.Error) {
+	if id < 0 || id >= len(ls.shards) {
+		return nil, newError(http.StatusBadRequest, sls.SHARD_NOT_EXIST, "shard %d does not exist", id)
+	}
+	return ls.shards[id], nil
+}
+
+// routeShard returns the shard whose hash key range contains key, or the shards in turn if key is empty.
+func (ls *logstore) routeShard(key string) (*shard, *sls.Error) {
+	if key == "" {
+		s := ls.shards[ls.nextShard%len(ls.shards)]
+		ls.nextShard++
+		return s, nil
+	}
+	i := fakestore.RouteHashKey(key, len(ls.shards), func(i int) (string, string) {
+		return ls.shards[i].beginKey, ls.shards[i].endKey
+	})
+	if i >= 0 {
+		return ls.shards[i], nil
+	}
+	return nil, newError(http.StatusBadRequest, sls.INVALID_KEY, "invalid hash key %s", key)
+}
+
+// queryLogs returns the logs matching req, which must be a keyword query.
+func (ls *logstore) queryLogs(req *sls.GetLogRequest) ([]map[string]string, *sls.Error) {
+	if strings.Contains(req.Query, "|") {
+		return nil, newError(http.StatusBadRequest, sls.NOT_SUPPORTED, "only keyword queries are supported")
+	}
+	logs := []map[string]string{}
+	for _, shard := range ls.shards {
+		for _, stored := range shard.groups {
+			lg := stored.logGroup
+			if req.Topic != "" && lg.GetTopic() != req.Topic {
+				continue
+			}
+			for _, l := range lg.Logs {
+				t := int64(l.GetTime())
+				if t < req.From || t >= req.To {
+					continue
+				}
+				log := map[string]string{
+					"__time__":   strconv.FormatInt(t, 10),
+					"__topic__":  lg.GetTopic(),
+					"__source__": lg.GetSource(),
+				}
+				for _, c := range l.Contents {
+					log[c.GetKey()] = c.GetValue()
+				}
+				if fakestore.Matches(req.Query, log) {
+					logs = append(logs, log)
+				}
+			}
+		}
+	}
+	sortLogs(logs, req.Reverse)
+	lines := int(req.Lines)
+	if lines <= 0 {
+		lines = 100
+	}
+	offset := int(req.Offset)
+	if offset > len(logs) {
+		offset = len(logs)
+	}
+	logs = logs[offset:]
+	if len(logs) > lines {
+		logs = logs[:lines]
+	}
+	return logs, nil
+}
+
+func (ls *logstore) getConsumerGroup(name string) (*consumerGroup, *sls.Error) {
+	cg, ok := ls.consumerGroups[name]
+	if !ok {
+		return nil, newError(http.StatusNotFound, sls.CONSUMER_GROUP_NOT_EXIST, "consumer group %s does not exist", name)
+	}
+	return cg, nil
+}
+
+func (s *shard) decodeCursor(cursor string) (int, *sls.Error) {
+	if offset, ok := fakestore.DecodeCursor(cursor, len(s.groups)); ok {
+		return offset, nil
+	}
+	return 0, newError(http.StatusBadRequest, sls.INVALID_CURSOR, "cursor %s is invalid", cursor)
+}
+
+// cursorOf returns the cursor of from, which is begin, end or a unix timestamp of receive time.
+func (s *shard) cursorOf(from string) (string, *sls.Error) {
+	cursor, ok := fakestore.CursorOf(from, len(s.groups), func(i int) int64 {
+		return s.groups[i].receiveTime
+	})
+	if !ok {
+		return "", newError(http.StatusBadRequest, sls.PARAMETER_INVALID, "from %s is invalid", from)
+	}
+	return cursor, nil
+}
+
+// heartbeat records the heartbeat of consumer and returns the shards assigned to it.
+func (cg *consumerGroup) heartbeat(consumer string, shardCount int) []int {
+	return cg.assignment.Heartbeat(consumer, shardCount, time.Duration(cg.meta.Timeout)*time.Second)
+}
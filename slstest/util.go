@@ -0,0 +1,100 @@
+package slstest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	sls "github.com/aliyun/aliyun-log-go-sdk"
+	"github.com/pierrec/lz4/v4"
+)
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, newError(http.StatusInternalServerError, sls.INTERNAL_SERVER_ERROR, "fail to marshal response: %v", err))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+func writeError(w http.ResponseWriter, err *sls.Error) {
+	body, _ := json.Marshal(map[string]string{
+		"errorCode":    err.Code,
+		"errorMessage": err.Message,
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(int(err.HTTPCode))
+	w.Write(body)
+}
+
+func writeNotSupported(w http.ResponseWriter, r *http.Request) {
+	writeError(w, newError(http.StatusBadRequest, sls.NOT_SUPPORTED, "%s %s is not supported", r.Method, r.URL.Path))
+}
+
+// readJSON unmarshals body into v, and writes an error if body is invalid.
+func readJSON(w http.ResponseWriter, body []byte, v interface{}) bool {
+	if err := json.Unmarshal(body, v); err != nil {
+		writeError(w, newError(http.StatusBadRequest, sls.POST_BODY_INVALID, "invalid json body: %v", err))
+		return false
+	}
+	return true
+}
+
+// pageOf returns the offset and size of the page of r among total items.
+func pageOf(r *http.Request, total int) (offset, size int) {
+	offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	size, _ = strconv.Atoi(r.URL.Query().Get("size"))
+	if offset < 0 || offset > total {
+		offset = total
+	}
+	if size <= 0 || offset+size > total {
+		size = total - offset
+	}
+	return offset, size
+}
+
+// sortLogs sorts logs by __time__, in descending order if reverse.
+func sortLogs(logs []map[string]string, reverse bool) {
+	sort.SliceStable(logs, func(i, j int) bool {
+		ti, _ := strconv.ParseInt(logs[i]["__time__"], 10, 64)
+		tj, _ := strconv.ParseInt(logs[j]["__time__"], 10, 64)
+		if reverse {
+			return ti > tj
+		}
+		return ti < tj
+	})
+}
+
+func compress(compressType string, raw []byte) ([]byte, error) {
+	if compressType == "zstd" {
+		return zstdCompressor.Compress(raw, nil)
+	}
+	out := make([]byte, lz4.CompressBlockBound(len(raw)))
+	n, err := lz4.CompressBlock(raw, out, nil)
+	if err != nil {
+		return nil, err
+	}
+	if n == 0 && len(raw) > 0 {
+		return lz4Literals(raw), nil
+	}
+	return out[:n], nil
+}
+
+// lz4Literals encodes incompressible src as an lz4 block of a single literal sequence.
+func lz4Literals(src []byte) []byte {
+	out := make([]byte, 0, len(src)+len(src)/255+2)
+	n := len(src)
+	if n < 0xF {
+		out = append(out, byte(n<<4))
+	} else {
+		out = append(out, 0xF0)
+		for n -= 0xF; n >= 0xFF; n -= 0xFF {
+			out = append(out, 0xFF)
+		}
+		out = append(out, byte(n))
+	}
+	return append(out, src...)
+}
@@ -151,6 +151,36 @@ func (c *TokenAutoUpdateClient) SetRetryTimeout(timeout time.Duration) {
 	c.logClient.SetRetryTimeout(timeout)
 }
 
+// SetEndpoints set an ordered list of endpoints to fail over between
+func (c *TokenAutoUpdateClient) SetEndpoints(endpoints ...string) {
+	c.logClient.(*Client).SetEndpoints(endpoints...)
+}
+
+// SetRetryPolicy set the retry policy of all requests sent by the client
+func (c *TokenAutoUpdateClient) SetRetryPolicy(policy *RetryPolicy) {
+	c.logClient.(*Client).SetRetryPolicy(policy)
+}
+
+// SetRateLimiter set the rate limiter of all requests sent by the client
+func (c *TokenAutoUpdateClient) SetRateLimiter(limiter *RateLimiter) {
+	c.logClient.(*Client).SetRateLimiter(limiter)
+}
+
+// SetCircuitBreaker set the circuit breaker of all requests sent by the client
+func (c *TokenAutoUpdateClient) SetCircuitBreaker(breaker *CircuitBreaker) {
+	c.logClient.(*Client).SetCircuitBreaker(breaker)
+}
+
+// SetMetricsCollector set the collector of metrics of all requests sent by the client
+func (c *TokenAutoUpdateClient) SetMetricsCollector(collector *MetricsCollector) {
+	c.logClient.(*Client).SetMetricsCollector(collector)
+}
+
+// SetSigner set the function creating the signer of each request from the credentials
+func (c *TokenAutoUpdateClient) SetSigner(newSigner func(creds Credentials) Signer) {
+	c.logClient.(*Client).SetSigner(newSigner)
+}
+
 // SetAuthVersion set auth version that the client used
 func (c *TokenAutoUpdateClient) SetAuthVersion(version AuthVersionType) {
 	c.logClient.SetAuthVersion(version)
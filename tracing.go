@@ -0,0 +1,107 @@
+package sls
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/aliyun/aliyun-log-go-sdk"
+
+// span attribute keys
+const (
+	attrProject    = attribute.Key("sls.project")
+	attrLogstore   = attribute.Key("sls.logstore")
+	attrShard      = attribute.Key("sls.shard")
+	attrRequestID  = attribute.Key("sls.request_id")
+	attrErrorCode  = attribute.Key("sls.error_code")
+	attrAttempt    = attribute.Key("sls.attempt")
+	attrHTTPMethod = attribute.Key("http.method")
+	attrHTTPStatus = attribute.Key("http.status_code")
+)
+
+// a span that records nothing, used when tracing is disabled
+var noopSpan = trace.SpanFromContext(context.Background())
+
+// SetTracerProvider enables OpenTelemetry tracing of the client with tp.
+// A span named sls.<Operation> is started for each api call, with a child span
+// named sls.<Operation>.attempt for each http request, including retries.
+// Spans are children of the span in the context passed to the Ctx methods.
+// Set tp to nil to disable tracing, which is the default.
+func (c *Client) SetTracerProvider(tp trace.TracerProvider) {
+	c.accessKeyLock.Lock()
+	defer c.accessKeyLock.Unlock()
+	if tp == nil {
+		c.tracer = nil
+		return
+	}
+	c.tracer = tp.Tracer(tracerName)
+}
+
+// startOperationSpan starts the span of an api call, it returns a noop span if tracer is nil.
+func startOperationSpan(ctx context.Context, tracer trace.Tracer, project string, op apiOperation) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, noopSpan
+	}
+	attrs := []attribute.KeyValue{attrProject.String(project)}
+	if op.Logstore != "" {
+		attrs = append(attrs, attrLogstore.String(op.Logstore))
+	}
+	if op.Shard != "" {
+		attrs = append(attrs, attrShard.String(op.Shard))
+	}
+	return tracer.Start(ctx, "sls."+op.Name, trace.WithAttributes(attrs...))
+}
+
+// startAttemptSpan starts the span of an http request of an api call,
+// it returns a noop span if tracer is nil.
+func startAttemptSpan(ctx context.Context, tracer trace.Tracer, method string, op apiOperation, attempt int) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, noopSpan
+	}
+	return tracer.Start(ctx, "sls."+op.Name+".attempt",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrHTTPMethod.String(method), attrAttempt.Int(attempt)))
+}
+
+// endSpan records the result of a request on span and ends it.
+func endSpan(span trace.Span, resp *http.Response, err error) {
+	if !span.IsRecording() {
+		span.End()
+		return
+	}
+	var slsErr *Error
+	var badRespErr *BadResponseError
+	switch {
+	case err == nil:
+		if resp != nil {
+			span.SetAttributes(attrHTTPStatus.Int(resp.StatusCode))
+			if resp.Header != nil {
+				span.SetAttributes(attrRequestID.String(resp.Header.Get(RequestIDHeader)))
+			}
+		}
+	case errors.As(err, &slsErr):
+		if slsErr.HTTPCode > 0 {
+			span.SetAttributes(attrHTTPStatus.Int(int(slsErr.HTTPCode)))
+		}
+		span.SetAttributes(attrErrorCode.String(slsErr.Code), attrRequestID.String(slsErr.RequestID))
+		span.SetStatus(codes.Error, slsErr.Message)
+	case errors.As(err, &badRespErr):
+		span.SetAttributes(attrHTTPStatus.Int(badRespErr.HTTPCode), attrErrorCode.String(strconv.Itoa(badRespErr.HTTPCode)))
+		if badRespErr.RequestID != "" {
+			span.SetAttributes(attrRequestID.String(badRespErr.RequestID))
+		}
+		span.SetStatus(codes.Error, badRespErr.ErrorMessage)
+	default:
+		span.SetStatus(codes.Error, err.Error())
+	}
+	if err != nil {
+		span.RecordError(err)
+	}
+	span.End()
+}
@@ -0,0 +1,111 @@
+package sls
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttr(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes() {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestTracing(t *testing.T) {
+	endpoint, project, logstore := "mock-test-endpoint.aliyuncs.com", "testProject", "testLogstore"
+	transport := httpmock.NewMockTransport()
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/consumergroups/cg", project, endpoint, logstore),
+		httpmock.NewStringResponder(404, `{"errorCode":"ConsumerGroupNotExist","errorMessage":"consumer group not exist"}`).
+			HeaderSet(http.Header{RequestIDHeader: []string{"request-1"}}))
+	transport.RegisterResponder("GET", fmt.Sprintf("http://%s.%s/logstores/%s/shards", project, endpoint, logstore),
+		httpmock.NewStringResponder(500, `{"errorCode":"InternalServerError","errorMessage":"internal error"}`).Once().
+			Then(httpmock.NewStringResponder(200, `[{"shardID":0,"status":"readwrite"}]`)))
+
+	recorder := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	client := CreateNormalInterface(endpoint, "testAccessKeyId", "testAccessKeySecret", "").(*Client)
+	client.SetHTTPClient(&http.Client{Transport: transport})
+
+	// tracing is disabled by default
+	_, err := client.GetCheckpoint(project, logstore, "cg")
+	require.Error(t, err)
+	require.Empty(t, recorder.Ended())
+
+	client.SetTracerProvider(tp)
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "parent")
+
+	// Client path
+	_, err = client.GetCheckpointCtx(ctx, project, logstore, "cg")
+	require.Error(t, err)
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	attempt, op := spans[0], spans[1]
+	require.Equal(t, "sls.GetCheckpoint.attempt", attempt.Name())
+	require.Equal(t, "sls.GetCheckpoint", op.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), op.Parent().SpanID())
+	require.Equal(t, op.SpanContext().SpanID(), attempt.Parent().SpanID())
+	require.Equal(t, project, spanAttr(op, attrProject).AsString())
+	require.Equal(t, logstore, spanAttr(op, attrLogstore).AsString())
+	require.Equal(t, codes.Error, op.Status().Code)
+	require.Equal(t, int64(404), spanAttr(attempt, attrHTTPStatus).AsInt64())
+	require.Equal(t, "ConsumerGroupNotExist", spanAttr(attempt, attrErrorCode).AsString())
+	require.Equal(t, "request-1", spanAttr(attempt, attrRequestID).AsString())
+
+	// LogProject path, the first attempt fails and is retried
+	recorder = tracetest.NewSpanRecorder()
+	tp.RegisterSpanProcessor(recorder)
+	shards, err := client.ListShardsCtx(ctx, project, logstore)
+	require.NoError(t, err)
+	require.Len(t, shards, 1)
+	spans = recorder.Ended()
+	require.Len(t, spans, 3)
+	op = spans[2]
+	require.Equal(t, "sls.ListShards", op.Name())
+	require.Equal(t, parent.SpanContext().SpanID(), op.Parent().SpanID())
+	require.Equal(t, codes.Unset, op.Status().Code)
+	for i, attempt := range spans[:2] {
+		require.Equal(t, "sls.ListShards.attempt", attempt.Name())
+		require.Equal(t, op.SpanContext().SpanID(), attempt.Parent().SpanID())
+		require.Equal(t, int64(i+1), spanAttr(attempt, attrAttempt).AsInt64())
+	}
+	require.Equal(t, int64(500), spanAttr(spans[0], attrHTTPStatus).AsInt64())
+	require.Equal(t, codes.Error, spans[0].Status().Code)
+	require.Equal(t, int64(200), spanAttr(spans[1], attrHTTPStatus).AsInt64())
+}
+
+func TestResolveOperation(t *testing.T) {
+	cases := []struct {
+		method, uri string
+		expected    apiOperation
+	}{
+		{"POST", "/logstores/ls/shards/route?key=abc", apiOperation{Name: "PostLogStoreLogs", Logstore: "ls"}},
+		{"POST", "/logstores/ls", apiOperation{Name: "PutLogs", Logstore: "ls"}},
+		{"GET", "/logstores/ls/shards/1?type=logs&cursor=abc", apiOperation{Name: "PullLogs", Logstore: "ls", Shard: "1"}},
+		{"GET", "/logstores/ls/shards/1?type=cursor&from=end", apiOperation{Name: "GetCursor", Logstore: "ls", Shard: "1"}},
+		{"POST", "/logstores/ls/shards/1?action=split&key=abc", apiOperation{Name: "SplitShard", Logstore: "ls", Shard: "1"}},
+		{"GET", "/logstores/ls?type=histogram&topic=", apiOperation{Name: "GetHistograms", Logstore: "ls"}},
+		{"POST", "/logstores/ls/logs", apiOperation{Name: "GetLogsV3", Logstore: "ls"}},
+		{"POST", "/logstores/ls/consumergroups/cg?type=heartbeat", apiOperation{Name: "HeartBeat", Logstore: "ls"}},
+		{"PUT", "/logstores/ls/consumergroups/cg?type=checkpoint", apiOperation{Name: "UpdateCheckpoint", Logstore: "ls"}},
+		{"GET", "/logstores?offset=0&size=100", apiOperation{Name: "ListLogStore"}},
+		{"GET", "/logstores/ls", apiOperation{Name: "GetLogStore", Logstore: "ls"}},
+		{"DELETE", "/machinegroups/mg", apiOperation{Name: "DeleteMachineGroup"}},
+		{"GET", "/", apiOperation{Name: "ListProject"}},
+		{"PUT", "/jobs/job1?action=START", apiOperation{Name: "StartJob"}},
+	}
+	for _, c := range cases {
+		require.Equal(t, c.expected, resolveOperation(c.method, c.uri), c.method+" "+c.uri)
+	}
+}
@@ -8,7 +8,7 @@ import (
 
 const ENDPOINT_REGEX_PATTERN = `^(?:http[s]?:\/\/)?([a-z-0-9]+)\.(?:sls|log)\.aliyuncs\.com$`
 
-var regionSuffixs = []string{"-intranet", "-share", "-vpc"}
+var regionSuffixs = []string{"-intranet", "-share", "-vpc", "-dualstack"}
 
 func ParseRegion(endpoint string) (string, error) {
 	var re = regexp.MustCompile(ENDPOINT_REGEX_PATTERN)
@@ -27,6 +27,10 @@ func TestParseRegion(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "cn-shanghai-corp", region)
 
+	region, err = ParseRegion("cn-hangzhou-dualstack.log.aliyuncs.com")
+	assert.NoError(t, err)
+	assert.Equal(t, "cn-hangzhou", region)
+
 	_, err = ParseRegion("sls.aliyuncs.com")
 	assert.Error(t, err)
 }